@@ -0,0 +1,1206 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.33.0
+// 	protoc        v25.3.0
+// source: bustiming/v1/bus_timing.proto
+
+package bustimingv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	durationpb "google.golang.org/protobuf/types/known/durationpb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type GetBusLinesRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// include_paths adds the path of every bus line, they are large
+	IncludePaths bool `protobuf:"varint,1,opt,name=include_paths,json=includePaths,proto3" json:"include_paths,omitempty"`
+}
+
+func (x *GetBusLinesRequest) Reset() {
+	*x = GetBusLinesRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_bustiming_v1_bus_timing_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetBusLinesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetBusLinesRequest) ProtoMessage() {}
+
+func (x *GetBusLinesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_bustiming_v1_bus_timing_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetBusLinesRequest.ProtoReflect.Descriptor instead.
+func (*GetBusLinesRequest) Descriptor() ([]byte, []int) {
+	return file_bustiming_v1_bus_timing_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *GetBusLinesRequest) GetIncludePaths() bool {
+	if x != nil {
+		return x.IncludePaths
+	}
+	return false
+}
+
+type GetBusLinesResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	BusLines []*BusLine `protobuf:"bytes,1,rep,name=bus_lines,json=busLines,proto3" json:"bus_lines,omitempty"`
+}
+
+func (x *GetBusLinesResponse) Reset() {
+	*x = GetBusLinesResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_bustiming_v1_bus_timing_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetBusLinesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetBusLinesResponse) ProtoMessage() {}
+
+func (x *GetBusLinesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_bustiming_v1_bus_timing_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetBusLinesResponse.ProtoReflect.Descriptor instead.
+func (*GetBusLinesResponse) Descriptor() ([]byte, []int) {
+	return file_bustiming_v1_bus_timing_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *GetBusLinesResponse) GetBusLines() []*BusLine {
+	if x != nil {
+		return x.BusLines
+	}
+	return nil
+}
+
+type BusLine struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id        string     `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	FullName  string     `protobuf:"bytes,2,opt,name=full_name,json=fullName,proto3" json:"full_name,omitempty"`
+	ShortName string     `protobuf:"bytes,3,opt,name=short_name,json=shortName,proto3" json:"short_name,omitempty"`
+	Origin    string     `protobuf:"bytes,4,opt,name=origin,proto3" json:"origin,omitempty"`
+	BusStops  []*BusStop `protobuf:"bytes,5,rep,name=bus_stops,json=busStops,proto3" json:"bus_stops,omitempty"`
+	Path      []*LatLng  `protobuf:"bytes,6,rep,name=path,proto3" json:"path,omitempty"`
+}
+
+func (x *BusLine) Reset() {
+	*x = BusLine{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_bustiming_v1_bus_timing_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *BusLine) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BusLine) ProtoMessage() {}
+
+func (x *BusLine) ProtoReflect() protoreflect.Message {
+	mi := &file_bustiming_v1_bus_timing_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BusLine.ProtoReflect.Descriptor instead.
+func (*BusLine) Descriptor() ([]byte, []int) {
+	return file_bustiming_v1_bus_timing_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *BusLine) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *BusLine) GetFullName() string {
+	if x != nil {
+		return x.FullName
+	}
+	return ""
+}
+
+func (x *BusLine) GetShortName() string {
+	if x != nil {
+		return x.ShortName
+	}
+	return ""
+}
+
+func (x *BusLine) GetOrigin() string {
+	if x != nil {
+		return x.Origin
+	}
+	return ""
+}
+
+func (x *BusLine) GetBusStops() []*BusStop {
+	if x != nil {
+		return x.BusStops
+	}
+	return nil
+}
+
+func (x *BusLine) GetPath() []*LatLng {
+	if x != nil {
+		return x.Path
+	}
+	return nil
+}
+
+type BusStop struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id   string  `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name string  `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Lat  float64 `protobuf:"fixed64,3,opt,name=lat,proto3" json:"lat,omitempty"`
+	Lng  float64 `protobuf:"fixed64,4,opt,name=lng,proto3" json:"lng,omitempty"`
+}
+
+func (x *BusStop) Reset() {
+	*x = BusStop{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_bustiming_v1_bus_timing_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *BusStop) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BusStop) ProtoMessage() {}
+
+func (x *BusStop) ProtoReflect() protoreflect.Message {
+	mi := &file_bustiming_v1_bus_timing_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BusStop.ProtoReflect.Descriptor instead.
+func (*BusStop) Descriptor() ([]byte, []int) {
+	return file_bustiming_v1_bus_timing_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *BusStop) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *BusStop) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *BusStop) GetLat() float64 {
+	if x != nil {
+		return x.Lat
+	}
+	return 0
+}
+
+func (x *BusStop) GetLng() float64 {
+	if x != nil {
+		return x.Lng
+	}
+	return 0
+}
+
+type LatLng struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Lat float64 `protobuf:"fixed64,1,opt,name=lat,proto3" json:"lat,omitempty"`
+	Lng float64 `protobuf:"fixed64,2,opt,name=lng,proto3" json:"lng,omitempty"`
+}
+
+func (x *LatLng) Reset() {
+	*x = LatLng{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_bustiming_v1_bus_timing_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *LatLng) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LatLng) ProtoMessage() {}
+
+func (x *LatLng) ProtoReflect() protoreflect.Message {
+	mi := &file_bustiming_v1_bus_timing_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LatLng.ProtoReflect.Descriptor instead.
+func (*LatLng) Descriptor() ([]byte, []int) {
+	return file_bustiming_v1_bus_timing_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *LatLng) GetLat() float64 {
+	if x != nil {
+		return x.Lat
+	}
+	return 0
+}
+
+func (x *LatLng) GetLng() float64 {
+	if x != nil {
+		return x.Lng
+	}
+	return 0
+}
+
+type GetBusPositionsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	BusLineId string `protobuf:"bytes,1,opt,name=bus_line_id,json=busLineId,proto3" json:"bus_line_id,omitempty"`
+}
+
+func (x *GetBusPositionsRequest) Reset() {
+	*x = GetBusPositionsRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_bustiming_v1_bus_timing_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetBusPositionsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetBusPositionsRequest) ProtoMessage() {}
+
+func (x *GetBusPositionsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_bustiming_v1_bus_timing_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetBusPositionsRequest.ProtoReflect.Descriptor instead.
+func (*GetBusPositionsRequest) Descriptor() ([]byte, []int) {
+	return file_bustiming_v1_bus_timing_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *GetBusPositionsRequest) GetBusLineId() string {
+	if x != nil {
+		return x.BusLineId
+	}
+	return ""
+}
+
+type GetBusPositionsResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	BusPositions []*BusPosition `protobuf:"bytes,1,rep,name=bus_positions,json=busPositions,proto3" json:"bus_positions,omitempty"`
+}
+
+func (x *GetBusPositionsResponse) Reset() {
+	*x = GetBusPositionsResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_bustiming_v1_bus_timing_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetBusPositionsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetBusPositionsResponse) ProtoMessage() {}
+
+func (x *GetBusPositionsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_bustiming_v1_bus_timing_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetBusPositionsResponse.ProtoReflect.Descriptor instead.
+func (*GetBusPositionsResponse) Descriptor() ([]byte, []int) {
+	return file_bustiming_v1_bus_timing_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *GetBusPositionsResponse) GetBusPositions() []*BusPosition {
+	if x != nil {
+		return x.BusPositions
+	}
+	return nil
+}
+
+type BusPosition struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	VehiclePlate string  `protobuf:"bytes,1,opt,name=vehicle_plate,json=vehiclePlate,proto3" json:"vehicle_plate,omitempty"`
+	Bearing      float64 `protobuf:"fixed64,2,opt,name=bearing,proto3" json:"bearing,omitempty"`
+	CrowdLevel   string  `protobuf:"bytes,3,opt,name=crowd_level,json=crowdLevel,proto3" json:"crowd_level,omitempty"`
+	Lat          float64 `protobuf:"fixed64,4,opt,name=lat,proto3" json:"lat,omitempty"`
+	Lng          float64 `protobuf:"fixed64,5,opt,name=lng,proto3" json:"lng,omitempty"`
+}
+
+func (x *BusPosition) Reset() {
+	*x = BusPosition{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_bustiming_v1_bus_timing_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *BusPosition) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BusPosition) ProtoMessage() {}
+
+func (x *BusPosition) ProtoReflect() protoreflect.Message {
+	mi := &file_bustiming_v1_bus_timing_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BusPosition.ProtoReflect.Descriptor instead.
+func (*BusPosition) Descriptor() ([]byte, []int) {
+	return file_bustiming_v1_bus_timing_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *BusPosition) GetVehiclePlate() string {
+	if x != nil {
+		return x.VehiclePlate
+	}
+	return ""
+}
+
+func (x *BusPosition) GetBearing() float64 {
+	if x != nil {
+		return x.Bearing
+	}
+	return 0
+}
+
+func (x *BusPosition) GetCrowdLevel() string {
+	if x != nil {
+		return x.CrowdLevel
+	}
+	return ""
+}
+
+func (x *BusPosition) GetLat() float64 {
+	if x != nil {
+		return x.Lat
+	}
+	return 0
+}
+
+func (x *BusPosition) GetLng() float64 {
+	if x != nil {
+		return x.Lng
+	}
+	return 0
+}
+
+type GetStopArrivalsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	BusStopId string `protobuf:"bytes,1,opt,name=bus_stop_id,json=busStopId,proto3" json:"bus_stop_id,omitempty"`
+}
+
+func (x *GetStopArrivalsRequest) Reset() {
+	*x = GetStopArrivalsRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_bustiming_v1_bus_timing_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetStopArrivalsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetStopArrivalsRequest) ProtoMessage() {}
+
+func (x *GetStopArrivalsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_bustiming_v1_bus_timing_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetStopArrivalsRequest.ProtoReflect.Descriptor instead.
+func (*GetStopArrivalsRequest) Descriptor() ([]byte, []int) {
+	return file_bustiming_v1_bus_timing_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *GetStopArrivalsRequest) GetBusStopId() string {
+	if x != nil {
+		return x.BusStopId
+	}
+	return ""
+}
+
+type GetStopArrivalsResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Arrivals []*Arrival `protobuf:"bytes,1,rep,name=arrivals,proto3" json:"arrivals,omitempty"`
+}
+
+func (x *GetStopArrivalsResponse) Reset() {
+	*x = GetStopArrivalsResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_bustiming_v1_bus_timing_proto_msgTypes[9]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetStopArrivalsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetStopArrivalsResponse) ProtoMessage() {}
+
+func (x *GetStopArrivalsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_bustiming_v1_bus_timing_proto_msgTypes[9]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetStopArrivalsResponse.ProtoReflect.Descriptor instead.
+func (*GetStopArrivalsResponse) Descriptor() ([]byte, []int) {
+	return file_bustiming_v1_bus_timing_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *GetStopArrivalsResponse) GetArrivals() []*Arrival {
+	if x != nil {
+		return x.Arrivals
+	}
+	return nil
+}
+
+type Arrival struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	BusLineId    string               `protobuf:"bytes,1,opt,name=bus_line_id,json=busLineId,proto3" json:"bus_line_id,omitempty"`
+	FullName     string               `protobuf:"bytes,2,opt,name=full_name,json=fullName,proto3" json:"full_name,omitempty"`
+	ShortName    string               `protobuf:"bytes,3,opt,name=short_name,json=shortName,proto3" json:"short_name,omitempty"`
+	Origin       string               `protobuf:"bytes,4,opt,name=origin,proto3" json:"origin,omitempty"`
+	VehiclePlate string               `protobuf:"bytes,5,opt,name=vehicle_plate,json=vehiclePlate,proto3" json:"vehicle_plate,omitempty"`
+	Lat          float64              `protobuf:"fixed64,6,opt,name=lat,proto3" json:"lat,omitempty"`
+	Lng          float64              `protobuf:"fixed64,7,opt,name=lng,proto3" json:"lng,omitempty"`
+	Eta          *durationpb.Duration `protobuf:"bytes,8,opt,name=eta,proto3" json:"eta,omitempty"`
+	// distance in meters from the bus to the bus stop along the bus line
+	Distance float64 `protobuf:"fixed64,9,opt,name=distance,proto3" json:"distance,omitempty"`
+}
+
+func (x *Arrival) Reset() {
+	*x = Arrival{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_bustiming_v1_bus_timing_proto_msgTypes[10]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Arrival) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Arrival) ProtoMessage() {}
+
+func (x *Arrival) ProtoReflect() protoreflect.Message {
+	mi := &file_bustiming_v1_bus_timing_proto_msgTypes[10]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Arrival.ProtoReflect.Descriptor instead.
+func (*Arrival) Descriptor() ([]byte, []int) {
+	return file_bustiming_v1_bus_timing_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *Arrival) GetBusLineId() string {
+	if x != nil {
+		return x.BusLineId
+	}
+	return ""
+}
+
+func (x *Arrival) GetFullName() string {
+	if x != nil {
+		return x.FullName
+	}
+	return ""
+}
+
+func (x *Arrival) GetShortName() string {
+	if x != nil {
+		return x.ShortName
+	}
+	return ""
+}
+
+func (x *Arrival) GetOrigin() string {
+	if x != nil {
+		return x.Origin
+	}
+	return ""
+}
+
+func (x *Arrival) GetVehiclePlate() string {
+	if x != nil {
+		return x.VehiclePlate
+	}
+	return ""
+}
+
+func (x *Arrival) GetLat() float64 {
+	if x != nil {
+		return x.Lat
+	}
+	return 0
+}
+
+func (x *Arrival) GetLng() float64 {
+	if x != nil {
+		return x.Lng
+	}
+	return 0
+}
+
+func (x *Arrival) GetEta() *durationpb.Duration {
+	if x != nil {
+		return x.Eta
+	}
+	return nil
+}
+
+func (x *Arrival) GetDistance() float64 {
+	if x != nil {
+		return x.Distance
+	}
+	return 0
+}
+
+type WatchBusPositionsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	BusLineId string `protobuf:"bytes,1,opt,name=bus_line_id,json=busLineId,proto3" json:"bus_line_id,omitempty"`
+	// last_event_id resumes a watch, updates after it are sent again when still kept
+	LastEventId uint64 `protobuf:"varint,2,opt,name=last_event_id,json=lastEventId,proto3" json:"last_event_id,omitempty"`
+}
+
+func (x *WatchBusPositionsRequest) Reset() {
+	*x = WatchBusPositionsRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_bustiming_v1_bus_timing_proto_msgTypes[11]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *WatchBusPositionsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchBusPositionsRequest) ProtoMessage() {}
+
+func (x *WatchBusPositionsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_bustiming_v1_bus_timing_proto_msgTypes[11]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchBusPositionsRequest.ProtoReflect.Descriptor instead.
+func (*WatchBusPositionsRequest) Descriptor() ([]byte, []int) {
+	return file_bustiming_v1_bus_timing_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *WatchBusPositionsRequest) GetBusLineId() string {
+	if x != nil {
+		return x.BusLineId
+	}
+	return ""
+}
+
+func (x *WatchBusPositionsRequest) GetLastEventId() uint64 {
+	if x != nil {
+		return x.LastEventId
+	}
+	return 0
+}
+
+type BusPositionsUpdate struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	EventId      uint64                 `protobuf:"varint,1,opt,name=event_id,json=eventId,proto3" json:"event_id,omitempty"`
+	BusLineId    string                 `protobuf:"bytes,2,opt,name=bus_line_id,json=busLineId,proto3" json:"bus_line_id,omitempty"`
+	BusPositions []*BusPosition         `protobuf:"bytes,3,rep,name=bus_positions,json=busPositions,proto3" json:"bus_positions,omitempty"`
+	PublishedAt  *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=published_at,json=publishedAt,proto3" json:"published_at,omitempty"`
+}
+
+func (x *BusPositionsUpdate) Reset() {
+	*x = BusPositionsUpdate{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_bustiming_v1_bus_timing_proto_msgTypes[12]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *BusPositionsUpdate) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BusPositionsUpdate) ProtoMessage() {}
+
+func (x *BusPositionsUpdate) ProtoReflect() protoreflect.Message {
+	mi := &file_bustiming_v1_bus_timing_proto_msgTypes[12]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BusPositionsUpdate.ProtoReflect.Descriptor instead.
+func (*BusPositionsUpdate) Descriptor() ([]byte, []int) {
+	return file_bustiming_v1_bus_timing_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *BusPositionsUpdate) GetEventId() uint64 {
+	if x != nil {
+		return x.EventId
+	}
+	return 0
+}
+
+func (x *BusPositionsUpdate) GetBusLineId() string {
+	if x != nil {
+		return x.BusLineId
+	}
+	return ""
+}
+
+func (x *BusPositionsUpdate) GetBusPositions() []*BusPosition {
+	if x != nil {
+		return x.BusPositions
+	}
+	return nil
+}
+
+func (x *BusPositionsUpdate) GetPublishedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.PublishedAt
+	}
+	return nil
+}
+
+var File_bustiming_v1_bus_timing_proto protoreflect.FileDescriptor
+
+var file_bustiming_v1_bus_timing_proto_rawDesc = []byte{
+	0x0a, 0x1d, 0x62, 0x75, 0x73, 0x74, 0x69, 0x6d, 0x69, 0x6e, 0x67, 0x2f, 0x76, 0x31, 0x2f, 0x62,
+	0x75, 0x73, 0x5f, 0x74, 0x69, 0x6d, 0x69, 0x6e, 0x67, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12,
+	0x0c, 0x62, 0x75, 0x73, 0x74, 0x69, 0x6d, 0x69, 0x6e, 0x67, 0x2e, 0x76, 0x31, 0x1a, 0x1e, 0x67,
+	0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x64,
+	0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x1f, 0x67,
+	0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x74,
+	0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x39,
+	0x0a, 0x12, 0x47, 0x65, 0x74, 0x42, 0x75, 0x73, 0x4c, 0x69, 0x6e, 0x65, 0x73, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x12, 0x23, 0x0a, 0x0d, 0x69, 0x6e, 0x63, 0x6c, 0x75, 0x64, 0x65, 0x5f,
+	0x70, 0x61, 0x74, 0x68, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0c, 0x69, 0x6e, 0x63,
+	0x6c, 0x75, 0x64, 0x65, 0x50, 0x61, 0x74, 0x68, 0x73, 0x22, 0x49, 0x0a, 0x13, 0x47, 0x65, 0x74,
+	0x42, 0x75, 0x73, 0x4c, 0x69, 0x6e, 0x65, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x12, 0x32, 0x0a, 0x09, 0x62, 0x75, 0x73, 0x5f, 0x6c, 0x69, 0x6e, 0x65, 0x73, 0x18, 0x01, 0x20,
+	0x03, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x62, 0x75, 0x73, 0x74, 0x69, 0x6d, 0x69, 0x6e, 0x67, 0x2e,
+	0x76, 0x31, 0x2e, 0x42, 0x75, 0x73, 0x4c, 0x69, 0x6e, 0x65, 0x52, 0x08, 0x62, 0x75, 0x73, 0x4c,
+	0x69, 0x6e, 0x65, 0x73, 0x22, 0xcb, 0x01, 0x0a, 0x07, 0x42, 0x75, 0x73, 0x4c, 0x69, 0x6e, 0x65,
+	0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64,
+	0x12, 0x1b, 0x0a, 0x09, 0x66, 0x75, 0x6c, 0x6c, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x08, 0x66, 0x75, 0x6c, 0x6c, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x1d, 0x0a,
+	0x0a, 0x73, 0x68, 0x6f, 0x72, 0x74, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x09, 0x73, 0x68, 0x6f, 0x72, 0x74, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x16, 0x0a, 0x06,
+	0x6f, 0x72, 0x69, 0x67, 0x69, 0x6e, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x6f, 0x72,
+	0x69, 0x67, 0x69, 0x6e, 0x12, 0x32, 0x0a, 0x09, 0x62, 0x75, 0x73, 0x5f, 0x73, 0x74, 0x6f, 0x70,
+	0x73, 0x18, 0x05, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x62, 0x75, 0x73, 0x74, 0x69, 0x6d,
+	0x69, 0x6e, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x42, 0x75, 0x73, 0x53, 0x74, 0x6f, 0x70, 0x52, 0x08,
+	0x62, 0x75, 0x73, 0x53, 0x74, 0x6f, 0x70, 0x73, 0x12, 0x28, 0x0a, 0x04, 0x70, 0x61, 0x74, 0x68,
+	0x18, 0x06, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x62, 0x75, 0x73, 0x74, 0x69, 0x6d, 0x69,
+	0x6e, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x61, 0x74, 0x4c, 0x6e, 0x67, 0x52, 0x04, 0x70, 0x61,
+	0x74, 0x68, 0x22, 0x51, 0x0a, 0x07, 0x42, 0x75, 0x73, 0x53, 0x74, 0x6f, 0x70, 0x12, 0x0e, 0x0a,
+	0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x12, 0x12, 0x0a,
+	0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d,
+	0x65, 0x12, 0x10, 0x0a, 0x03, 0x6c, 0x61, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x01, 0x52, 0x03,
+	0x6c, 0x61, 0x74, 0x12, 0x10, 0x0a, 0x03, 0x6c, 0x6e, 0x67, 0x18, 0x04, 0x20, 0x01, 0x28, 0x01,
+	0x52, 0x03, 0x6c, 0x6e, 0x67, 0x22, 0x2c, 0x0a, 0x06, 0x4c, 0x61, 0x74, 0x4c, 0x6e, 0x67, 0x12,
+	0x10, 0x0a, 0x03, 0x6c, 0x61, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x01, 0x52, 0x03, 0x6c, 0x61,
+	0x74, 0x12, 0x10, 0x0a, 0x03, 0x6c, 0x6e, 0x67, 0x18, 0x02, 0x20, 0x01, 0x28, 0x01, 0x52, 0x03,
+	0x6c, 0x6e, 0x67, 0x22, 0x38, 0x0a, 0x16, 0x47, 0x65, 0x74, 0x42, 0x75, 0x73, 0x50, 0x6f, 0x73,
+	0x69, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1e, 0x0a,
+	0x0b, 0x62, 0x75, 0x73, 0x5f, 0x6c, 0x69, 0x6e, 0x65, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x09, 0x62, 0x75, 0x73, 0x4c, 0x69, 0x6e, 0x65, 0x49, 0x64, 0x22, 0x59, 0x0a,
+	0x17, 0x47, 0x65, 0x74, 0x42, 0x75, 0x73, 0x50, 0x6f, 0x73, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x73,
+	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x3e, 0x0a, 0x0d, 0x62, 0x75, 0x73, 0x5f,
+	0x70, 0x6f, 0x73, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32,
+	0x19, 0x2e, 0x62, 0x75, 0x73, 0x74, 0x69, 0x6d, 0x69, 0x6e, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x42,
+	0x75, 0x73, 0x50, 0x6f, 0x73, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x0c, 0x62, 0x75, 0x73, 0x50,
+	0x6f, 0x73, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x22, 0x91, 0x01, 0x0a, 0x0b, 0x42, 0x75, 0x73,
+	0x50, 0x6f, 0x73, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x23, 0x0a, 0x0d, 0x76, 0x65, 0x68, 0x69,
+	0x63, 0x6c, 0x65, 0x5f, 0x70, 0x6c, 0x61, 0x74, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x0c, 0x76, 0x65, 0x68, 0x69, 0x63, 0x6c, 0x65, 0x50, 0x6c, 0x61, 0x74, 0x65, 0x12, 0x18, 0x0a,
+	0x07, 0x62, 0x65, 0x61, 0x72, 0x69, 0x6e, 0x67, 0x18, 0x02, 0x20, 0x01, 0x28, 0x01, 0x52, 0x07,
+	0x62, 0x65, 0x61, 0x72, 0x69, 0x6e, 0x67, 0x12, 0x1f, 0x0a, 0x0b, 0x63, 0x72, 0x6f, 0x77, 0x64,
+	0x5f, 0x6c, 0x65, 0x76, 0x65, 0x6c, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x63, 0x72,
+	0x6f, 0x77, 0x64, 0x4c, 0x65, 0x76, 0x65, 0x6c, 0x12, 0x10, 0x0a, 0x03, 0x6c, 0x61, 0x74, 0x18,
+	0x04, 0x20, 0x01, 0x28, 0x01, 0x52, 0x03, 0x6c, 0x61, 0x74, 0x12, 0x10, 0x0a, 0x03, 0x6c, 0x6e,
+	0x67, 0x18, 0x05, 0x20, 0x01, 0x28, 0x01, 0x52, 0x03, 0x6c, 0x6e, 0x67, 0x22, 0x38, 0x0a, 0x16,
+	0x47, 0x65, 0x74, 0x53, 0x74, 0x6f, 0x70, 0x41, 0x72, 0x72, 0x69, 0x76, 0x61, 0x6c, 0x73, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1e, 0x0a, 0x0b, 0x62, 0x75, 0x73, 0x5f, 0x73, 0x74,
+	0x6f, 0x70, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x62, 0x75, 0x73,
+	0x53, 0x74, 0x6f, 0x70, 0x49, 0x64, 0x22, 0x4c, 0x0a, 0x17, 0x47, 0x65, 0x74, 0x53, 0x74, 0x6f,
+	0x70, 0x41, 0x72, 0x72, 0x69, 0x76, 0x61, 0x6c, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x12, 0x31, 0x0a, 0x08, 0x61, 0x72, 0x72, 0x69, 0x76, 0x61, 0x6c, 0x73, 0x18, 0x01, 0x20,
+	0x03, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x62, 0x75, 0x73, 0x74, 0x69, 0x6d, 0x69, 0x6e, 0x67, 0x2e,
+	0x76, 0x31, 0x2e, 0x41, 0x72, 0x72, 0x69, 0x76, 0x61, 0x6c, 0x52, 0x08, 0x61, 0x72, 0x72, 0x69,
+	0x76, 0x61, 0x6c, 0x73, 0x22, 0x8f, 0x02, 0x0a, 0x07, 0x41, 0x72, 0x72, 0x69, 0x76, 0x61, 0x6c,
+	0x12, 0x1e, 0x0a, 0x0b, 0x62, 0x75, 0x73, 0x5f, 0x6c, 0x69, 0x6e, 0x65, 0x5f, 0x69, 0x64, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x62, 0x75, 0x73, 0x4c, 0x69, 0x6e, 0x65, 0x49, 0x64,
+	0x12, 0x1b, 0x0a, 0x09, 0x66, 0x75, 0x6c, 0x6c, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x08, 0x66, 0x75, 0x6c, 0x6c, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x1d, 0x0a,
+	0x0a, 0x73, 0x68, 0x6f, 0x72, 0x74, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x09, 0x73, 0x68, 0x6f, 0x72, 0x74, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x16, 0x0a, 0x06,
+	0x6f, 0x72, 0x69, 0x67, 0x69, 0x6e, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x6f, 0x72,
+	0x69, 0x67, 0x69, 0x6e, 0x12, 0x23, 0x0a, 0x0d, 0x76, 0x65, 0x68, 0x69, 0x63, 0x6c, 0x65, 0x5f,
+	0x70, 0x6c, 0x61, 0x74, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x76, 0x65, 0x68,
+	0x69, 0x63, 0x6c, 0x65, 0x50, 0x6c, 0x61, 0x74, 0x65, 0x12, 0x10, 0x0a, 0x03, 0x6c, 0x61, 0x74,
+	0x18, 0x06, 0x20, 0x01, 0x28, 0x01, 0x52, 0x03, 0x6c, 0x61, 0x74, 0x12, 0x10, 0x0a, 0x03, 0x6c,
+	0x6e, 0x67, 0x18, 0x07, 0x20, 0x01, 0x28, 0x01, 0x52, 0x03, 0x6c, 0x6e, 0x67, 0x12, 0x2b, 0x0a,
+	0x03, 0x65, 0x74, 0x61, 0x18, 0x08, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x67, 0x6f, 0x6f,
+	0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x44, 0x75, 0x72,
+	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x03, 0x65, 0x74, 0x61, 0x12, 0x1a, 0x0a, 0x08, 0x64, 0x69,
+	0x73, 0x74, 0x61, 0x6e, 0x63, 0x65, 0x18, 0x09, 0x20, 0x01, 0x28, 0x01, 0x52, 0x08, 0x64, 0x69,
+	0x73, 0x74, 0x61, 0x6e, 0x63, 0x65, 0x22, 0x5e, 0x0a, 0x18, 0x57, 0x61, 0x74, 0x63, 0x68, 0x42,
+	0x75, 0x73, 0x50, 0x6f, 0x73, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x12, 0x1e, 0x0a, 0x0b, 0x62, 0x75, 0x73, 0x5f, 0x6c, 0x69, 0x6e, 0x65, 0x5f, 0x69,
+	0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x62, 0x75, 0x73, 0x4c, 0x69, 0x6e, 0x65,
+	0x49, 0x64, 0x12, 0x22, 0x0a, 0x0d, 0x6c, 0x61, 0x73, 0x74, 0x5f, 0x65, 0x76, 0x65, 0x6e, 0x74,
+	0x5f, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0b, 0x6c, 0x61, 0x73, 0x74, 0x45,
+	0x76, 0x65, 0x6e, 0x74, 0x49, 0x64, 0x22, 0xce, 0x01, 0x0a, 0x12, 0x42, 0x75, 0x73, 0x50, 0x6f,
+	0x73, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x12, 0x19, 0x0a,
+	0x08, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52,
+	0x07, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x49, 0x64, 0x12, 0x1e, 0x0a, 0x0b, 0x62, 0x75, 0x73, 0x5f,
+	0x6c, 0x69, 0x6e, 0x65, 0x5f, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x62,
+	0x75, 0x73, 0x4c, 0x69, 0x6e, 0x65, 0x49, 0x64, 0x12, 0x3e, 0x0a, 0x0d, 0x62, 0x75, 0x73, 0x5f,
+	0x70, 0x6f, 0x73, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32,
+	0x19, 0x2e, 0x62, 0x75, 0x73, 0x74, 0x69, 0x6d, 0x69, 0x6e, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x42,
+	0x75, 0x73, 0x50, 0x6f, 0x73, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x0c, 0x62, 0x75, 0x73, 0x50,
+	0x6f, 0x73, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x3d, 0x0a, 0x0c, 0x70, 0x75, 0x62, 0x6c,
+	0x69, 0x73, 0x68, 0x65, 0x64, 0x5f, 0x61, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a,
+	0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66,
+	0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x0b, 0x70, 0x75, 0x62, 0x6c,
+	0x69, 0x73, 0x68, 0x65, 0x64, 0x41, 0x74, 0x32, 0x80, 0x03, 0x0a, 0x09, 0x42, 0x75, 0x73, 0x54,
+	0x69, 0x6d, 0x69, 0x6e, 0x67, 0x12, 0x52, 0x0a, 0x0b, 0x47, 0x65, 0x74, 0x42, 0x75, 0x73, 0x4c,
+	0x69, 0x6e, 0x65, 0x73, 0x12, 0x20, 0x2e, 0x62, 0x75, 0x73, 0x74, 0x69, 0x6d, 0x69, 0x6e, 0x67,
+	0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65, 0x74, 0x42, 0x75, 0x73, 0x4c, 0x69, 0x6e, 0x65, 0x73, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x21, 0x2e, 0x62, 0x75, 0x73, 0x74, 0x69, 0x6d, 0x69,
+	0x6e, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65, 0x74, 0x42, 0x75, 0x73, 0x4c, 0x69, 0x6e, 0x65,
+	0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x5e, 0x0a, 0x0f, 0x47, 0x65, 0x74,
+	0x42, 0x75, 0x73, 0x50, 0x6f, 0x73, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x24, 0x2e, 0x62,
+	0x75, 0x73, 0x74, 0x69, 0x6d, 0x69, 0x6e, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65, 0x74, 0x42,
+	0x75, 0x73, 0x50, 0x6f, 0x73, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x1a, 0x25, 0x2e, 0x62, 0x75, 0x73, 0x74, 0x69, 0x6d, 0x69, 0x6e, 0x67, 0x2e, 0x76,
+	0x31, 0x2e, 0x47, 0x65, 0x74, 0x42, 0x75, 0x73, 0x50, 0x6f, 0x73, 0x69, 0x74, 0x69, 0x6f, 0x6e,
+	0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x5e, 0x0a, 0x0f, 0x47, 0x65, 0x74,
+	0x53, 0x74, 0x6f, 0x70, 0x41, 0x72, 0x72, 0x69, 0x76, 0x61, 0x6c, 0x73, 0x12, 0x24, 0x2e, 0x62,
+	0x75, 0x73, 0x74, 0x69, 0x6d, 0x69, 0x6e, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65, 0x74, 0x53,
+	0x74, 0x6f, 0x70, 0x41, 0x72, 0x72, 0x69, 0x76, 0x61, 0x6c, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x1a, 0x25, 0x2e, 0x62, 0x75, 0x73, 0x74, 0x69, 0x6d, 0x69, 0x6e, 0x67, 0x2e, 0x76,
+	0x31, 0x2e, 0x47, 0x65, 0x74, 0x53, 0x74, 0x6f, 0x70, 0x41, 0x72, 0x72, 0x69, 0x76, 0x61, 0x6c,
+	0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x5f, 0x0a, 0x11, 0x57, 0x61, 0x74,
+	0x63, 0x68, 0x42, 0x75, 0x73, 0x50, 0x6f, 0x73, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x26,
+	0x2e, 0x62, 0x75, 0x73, 0x74, 0x69, 0x6d, 0x69, 0x6e, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x57, 0x61,
+	0x74, 0x63, 0x68, 0x42, 0x75, 0x73, 0x50, 0x6f, 0x73, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x20, 0x2e, 0x62, 0x75, 0x73, 0x74, 0x69, 0x6d, 0x69,
+	0x6e, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x42, 0x75, 0x73, 0x50, 0x6f, 0x73, 0x69, 0x74, 0x69, 0x6f,
+	0x6e, 0x73, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x30, 0x01, 0x42, 0x29, 0x5a, 0x27, 0x62, 0x75,
+	0x73, 0x2d, 0x74, 0x69, 0x6d, 0x69, 0x6e, 0x67, 0x2f, 0x61, 0x70, 0x69, 0x2f, 0x62, 0x75, 0x73,
+	0x74, 0x69, 0x6d, 0x69, 0x6e, 0x67, 0x2f, 0x76, 0x31, 0x3b, 0x62, 0x75, 0x73, 0x74, 0x69, 0x6d,
+	0x69, 0x6e, 0x67, 0x76, 0x31, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_bustiming_v1_bus_timing_proto_rawDescOnce sync.Once
+	file_bustiming_v1_bus_timing_proto_rawDescData = file_bustiming_v1_bus_timing_proto_rawDesc
+)
+
+func file_bustiming_v1_bus_timing_proto_rawDescGZIP() []byte {
+	file_bustiming_v1_bus_timing_proto_rawDescOnce.Do(func() {
+		file_bustiming_v1_bus_timing_proto_rawDescData = protoimpl.X.CompressGZIP(file_bustiming_v1_bus_timing_proto_rawDescData)
+	})
+	return file_bustiming_v1_bus_timing_proto_rawDescData
+}
+
+var file_bustiming_v1_bus_timing_proto_msgTypes = make([]protoimpl.MessageInfo, 13)
+var file_bustiming_v1_bus_timing_proto_goTypes = []interface{}{
+	(*GetBusLinesRequest)(nil),       // 0: bustiming.v1.GetBusLinesRequest
+	(*GetBusLinesResponse)(nil),      // 1: bustiming.v1.GetBusLinesResponse
+	(*BusLine)(nil),                  // 2: bustiming.v1.BusLine
+	(*BusStop)(nil),                  // 3: bustiming.v1.BusStop
+	(*LatLng)(nil),                   // 4: bustiming.v1.LatLng
+	(*GetBusPositionsRequest)(nil),   // 5: bustiming.v1.GetBusPositionsRequest
+	(*GetBusPositionsResponse)(nil),  // 6: bustiming.v1.GetBusPositionsResponse
+	(*BusPosition)(nil),              // 7: bustiming.v1.BusPosition
+	(*GetStopArrivalsRequest)(nil),   // 8: bustiming.v1.GetStopArrivalsRequest
+	(*GetStopArrivalsResponse)(nil),  // 9: bustiming.v1.GetStopArrivalsResponse
+	(*Arrival)(nil),                  // 10: bustiming.v1.Arrival
+	(*WatchBusPositionsRequest)(nil), // 11: bustiming.v1.WatchBusPositionsRequest
+	(*BusPositionsUpdate)(nil),       // 12: bustiming.v1.BusPositionsUpdate
+	(*durationpb.Duration)(nil),      // 13: google.protobuf.Duration
+	(*timestamppb.Timestamp)(nil),    // 14: google.protobuf.Timestamp
+}
+var file_bustiming_v1_bus_timing_proto_depIdxs = []int32{
+	2,  // 0: bustiming.v1.GetBusLinesResponse.bus_lines:type_name -> bustiming.v1.BusLine
+	3,  // 1: bustiming.v1.BusLine.bus_stops:type_name -> bustiming.v1.BusStop
+	4,  // 2: bustiming.v1.BusLine.path:type_name -> bustiming.v1.LatLng
+	7,  // 3: bustiming.v1.GetBusPositionsResponse.bus_positions:type_name -> bustiming.v1.BusPosition
+	10, // 4: bustiming.v1.GetStopArrivalsResponse.arrivals:type_name -> bustiming.v1.Arrival
+	13, // 5: bustiming.v1.Arrival.eta:type_name -> google.protobuf.Duration
+	7,  // 6: bustiming.v1.BusPositionsUpdate.bus_positions:type_name -> bustiming.v1.BusPosition
+	14, // 7: bustiming.v1.BusPositionsUpdate.published_at:type_name -> google.protobuf.Timestamp
+	0,  // 8: bustiming.v1.BusTiming.GetBusLines:input_type -> bustiming.v1.GetBusLinesRequest
+	5,  // 9: bustiming.v1.BusTiming.GetBusPositions:input_type -> bustiming.v1.GetBusPositionsRequest
+	8,  // 10: bustiming.v1.BusTiming.GetStopArrivals:input_type -> bustiming.v1.GetStopArrivalsRequest
+	11, // 11: bustiming.v1.BusTiming.WatchBusPositions:input_type -> bustiming.v1.WatchBusPositionsRequest
+	1,  // 12: bustiming.v1.BusTiming.GetBusLines:output_type -> bustiming.v1.GetBusLinesResponse
+	6,  // 13: bustiming.v1.BusTiming.GetBusPositions:output_type -> bustiming.v1.GetBusPositionsResponse
+	9,  // 14: bustiming.v1.BusTiming.GetStopArrivals:output_type -> bustiming.v1.GetStopArrivalsResponse
+	12, // 15: bustiming.v1.BusTiming.WatchBusPositions:output_type -> bustiming.v1.BusPositionsUpdate
+	12, // [12:16] is the sub-list for method output_type
+	8,  // [8:12] is the sub-list for method input_type
+	8,  // [8:8] is the sub-list for extension type_name
+	8,  // [8:8] is the sub-list for extension extendee
+	0,  // [0:8] is the sub-list for field type_name
+}
+
+func init() { file_bustiming_v1_bus_timing_proto_init() }
+func file_bustiming_v1_bus_timing_proto_init() {
+	if File_bustiming_v1_bus_timing_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_bustiming_v1_bus_timing_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetBusLinesRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_bustiming_v1_bus_timing_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetBusLinesResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_bustiming_v1_bus_timing_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*BusLine); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_bustiming_v1_bus_timing_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*BusStop); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_bustiming_v1_bus_timing_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*LatLng); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_bustiming_v1_bus_timing_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetBusPositionsRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_bustiming_v1_bus_timing_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetBusPositionsResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_bustiming_v1_bus_timing_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*BusPosition); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_bustiming_v1_bus_timing_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetStopArrivalsRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_bustiming_v1_bus_timing_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetStopArrivalsResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_bustiming_v1_bus_timing_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Arrival); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_bustiming_v1_bus_timing_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*WatchBusPositionsRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_bustiming_v1_bus_timing_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*BusPositionsUpdate); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_bustiming_v1_bus_timing_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   13,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_bustiming_v1_bus_timing_proto_goTypes,
+		DependencyIndexes: file_bustiming_v1_bus_timing_proto_depIdxs,
+		MessageInfos:      file_bustiming_v1_bus_timing_proto_msgTypes,
+	}.Build()
+	File_bustiming_v1_bus_timing_proto = out.File
+	file_bustiming_v1_bus_timing_proto_rawDesc = nil
+	file_bustiming_v1_bus_timing_proto_goTypes = nil
+	file_bustiming_v1_bus_timing_proto_depIdxs = nil
+}
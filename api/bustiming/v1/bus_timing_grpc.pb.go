@@ -0,0 +1,250 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             v25.3.0
+// source: bustiming/v1/bus_timing.proto
+
+package bustimingv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	BusTiming_GetBusLines_FullMethodName       = "/bustiming.v1.BusTiming/GetBusLines"
+	BusTiming_GetBusPositions_FullMethodName   = "/bustiming.v1.BusTiming/GetBusPositions"
+	BusTiming_GetStopArrivals_FullMethodName   = "/bustiming.v1.BusTiming/GetStopArrivals"
+	BusTiming_WatchBusPositions_FullMethodName = "/bustiming.v1.BusTiming/WatchBusPositions"
+)
+
+// BusTimingClient is the client API for BusTiming service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type BusTimingClient interface {
+	GetBusLines(ctx context.Context, in *GetBusLinesRequest, opts ...grpc.CallOption) (*GetBusLinesResponse, error)
+	GetBusPositions(ctx context.Context, in *GetBusPositionsRequest, opts ...grpc.CallOption) (*GetBusPositionsResponse, error)
+	GetStopArrivals(ctx context.Context, in *GetStopArrivalsRequest, opts ...grpc.CallOption) (*GetStopArrivalsResponse, error)
+	// WatchBusPositions sends the running buses of a bus line every time they move.
+	WatchBusPositions(ctx context.Context, in *WatchBusPositionsRequest, opts ...grpc.CallOption) (BusTiming_WatchBusPositionsClient, error)
+}
+
+type busTimingClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewBusTimingClient(cc grpc.ClientConnInterface) BusTimingClient {
+	return &busTimingClient{cc}
+}
+
+func (c *busTimingClient) GetBusLines(ctx context.Context, in *GetBusLinesRequest, opts ...grpc.CallOption) (*GetBusLinesResponse, error) {
+	out := new(GetBusLinesResponse)
+	err := c.cc.Invoke(ctx, BusTiming_GetBusLines_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *busTimingClient) GetBusPositions(ctx context.Context, in *GetBusPositionsRequest, opts ...grpc.CallOption) (*GetBusPositionsResponse, error) {
+	out := new(GetBusPositionsResponse)
+	err := c.cc.Invoke(ctx, BusTiming_GetBusPositions_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *busTimingClient) GetStopArrivals(ctx context.Context, in *GetStopArrivalsRequest, opts ...grpc.CallOption) (*GetStopArrivalsResponse, error) {
+	out := new(GetStopArrivalsResponse)
+	err := c.cc.Invoke(ctx, BusTiming_GetStopArrivals_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *busTimingClient) WatchBusPositions(ctx context.Context, in *WatchBusPositionsRequest, opts ...grpc.CallOption) (BusTiming_WatchBusPositionsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &BusTiming_ServiceDesc.Streams[0], BusTiming_WatchBusPositions_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &busTimingWatchBusPositionsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type BusTiming_WatchBusPositionsClient interface {
+	Recv() (*BusPositionsUpdate, error)
+	grpc.ClientStream
+}
+
+type busTimingWatchBusPositionsClient struct {
+	grpc.ClientStream
+}
+
+func (x *busTimingWatchBusPositionsClient) Recv() (*BusPositionsUpdate, error) {
+	m := new(BusPositionsUpdate)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// BusTimingServer is the server API for BusTiming service.
+// All implementations must embed UnimplementedBusTimingServer
+// for forward compatibility
+type BusTimingServer interface {
+	GetBusLines(context.Context, *GetBusLinesRequest) (*GetBusLinesResponse, error)
+	GetBusPositions(context.Context, *GetBusPositionsRequest) (*GetBusPositionsResponse, error)
+	GetStopArrivals(context.Context, *GetStopArrivalsRequest) (*GetStopArrivalsResponse, error)
+	// WatchBusPositions sends the running buses of a bus line every time they move.
+	WatchBusPositions(*WatchBusPositionsRequest, BusTiming_WatchBusPositionsServer) error
+	mustEmbedUnimplementedBusTimingServer()
+}
+
+// UnimplementedBusTimingServer must be embedded to have forward compatible implementations.
+type UnimplementedBusTimingServer struct {
+}
+
+func (UnimplementedBusTimingServer) GetBusLines(context.Context, *GetBusLinesRequest) (*GetBusLinesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetBusLines not implemented")
+}
+func (UnimplementedBusTimingServer) GetBusPositions(context.Context, *GetBusPositionsRequest) (*GetBusPositionsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetBusPositions not implemented")
+}
+func (UnimplementedBusTimingServer) GetStopArrivals(context.Context, *GetStopArrivalsRequest) (*GetStopArrivalsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetStopArrivals not implemented")
+}
+func (UnimplementedBusTimingServer) WatchBusPositions(*WatchBusPositionsRequest, BusTiming_WatchBusPositionsServer) error {
+	return status.Errorf(codes.Unimplemented, "method WatchBusPositions not implemented")
+}
+func (UnimplementedBusTimingServer) mustEmbedUnimplementedBusTimingServer() {}
+
+// UnsafeBusTimingServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to BusTimingServer will
+// result in compilation errors.
+type UnsafeBusTimingServer interface {
+	mustEmbedUnimplementedBusTimingServer()
+}
+
+func RegisterBusTimingServer(s grpc.ServiceRegistrar, srv BusTimingServer) {
+	s.RegisterService(&BusTiming_ServiceDesc, srv)
+}
+
+func _BusTiming_GetBusLines_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetBusLinesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BusTimingServer).GetBusLines(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BusTiming_GetBusLines_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BusTimingServer).GetBusLines(ctx, req.(*GetBusLinesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BusTiming_GetBusPositions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetBusPositionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BusTimingServer).GetBusPositions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BusTiming_GetBusPositions_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BusTimingServer).GetBusPositions(ctx, req.(*GetBusPositionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BusTiming_GetStopArrivals_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetStopArrivalsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BusTimingServer).GetStopArrivals(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BusTiming_GetStopArrivals_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BusTimingServer).GetStopArrivals(ctx, req.(*GetStopArrivalsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BusTiming_WatchBusPositions_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchBusPositionsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(BusTimingServer).WatchBusPositions(m, &busTimingWatchBusPositionsServer{stream})
+}
+
+type BusTiming_WatchBusPositionsServer interface {
+	Send(*BusPositionsUpdate) error
+	grpc.ServerStream
+}
+
+type busTimingWatchBusPositionsServer struct {
+	grpc.ServerStream
+}
+
+func (x *busTimingWatchBusPositionsServer) Send(m *BusPositionsUpdate) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// BusTiming_ServiceDesc is the grpc.ServiceDesc for BusTiming service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var BusTiming_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "bustiming.v1.BusTiming",
+	HandlerType: (*BusTimingServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetBusLines",
+			Handler:    _BusTiming_GetBusLines_Handler,
+		},
+		{
+			MethodName: "GetBusPositions",
+			Handler:    _BusTiming_GetBusPositions_Handler,
+		},
+		{
+			MethodName: "GetStopArrivals",
+			Handler:    _BusTiming_GetStopArrivals_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchBusPositions",
+			Handler:       _BusTiming_WatchBusPositions_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "bustiming/v1/bus_timing.proto",
+}
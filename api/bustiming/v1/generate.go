@@ -0,0 +1,4 @@
+// Package bustimingv1 holds the gRPC API, regenerate it from the api directory after changing bus_timing.proto.
+package bustimingv1
+
+//go:generate protoc -I ../.. --go_out=../.. --go_opt=paths=source_relative --go-grpc_out=../.. --go-grpc_opt=paths=source_relative bustiming/v1/bus_timing.proto
@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"context"
+	"slices"
+
+	bustimingv1 "bus-timing/api/bustiming/v1"
+	config "bus-timing/configuration"
+	"bus-timing/internal/core/port"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/reflection"
+)
+
+// SetupGRPC wires the gRPC server on the services shared with the HTTP routes.
+func SetupGRPC(services *Services) *grpc.Server {
+	auth := grpcAuth(services)
+	server := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(auth.UnaryInterceptor()),
+		grpc.ChainStreamInterceptor(auth.StreamInterceptor()),
+	)
+	bustimingv1.RegisterBusTimingServer(server, &port.BusTimingGRPCServer{
+		BusLineService:         services.BusLineService,
+		BusLineCatalogue:       services.BusLineCatalogue,
+		BusPositionService:     services.BusPositionService,
+		RunningBusService:      services.RunningBusService,
+		BusPositionBroadcaster: services.BusPositionBroadcaster,
+	})
+	// lets grpcurl and other tools discover the service
+	if config.Config.GRPC.Reflection {
+		reflection.Register(server)
+	}
+	return server
+}
+
+// grpcGroups are the route groups serving what the gRPC service serves
+var grpcGroups = []string{"api", "v2"}
+
+// grpcAuth makes gRPC calls need an access token when auth.groups lists a group of grpcGroups,
+// and an API key when api_keys.groups lists one, as their routes do
+func grpcAuth(services *Services) port.GRPCAuth {
+	var auth port.GRPCAuth
+	if slices.ContainsFunc(grpcGroups, func(group string) bool { return slices.Contains(config.Config.Auth.Groups, group) }) {
+		auth.Authenticator = services.Authenticator
+	}
+	if slices.ContainsFunc(grpcGroups, func(group string) bool { return slices.Contains(config.Config.APIKeys.Groups, group) }) {
+		auth.APIKeyAuthorizer = services.APIKeyService
+	}
+	return auth
+}
+
+// stopGRPC waits for calls in progress until ctx is done, then closes the remaining ones (watches never end).
+func stopGRPC(ctx context.Context, server *grpc.Server) {
+	stopped := make(chan struct{})
+	go func() {
+		server.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		server.Stop()
+	}
+}
@@ -4,23 +4,66 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"log/slog"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"slices"
+	"strings"
 	"syscall"
 	"time"
 
 	config "bus-timing/configuration"
 	"bus-timing/internal/core/port"
 	"bus-timing/internal/core/service"
+	"bus-timing/internal/repository"
+	"bus-timing/pkg/cache"
+	"bus-timing/pkg/logging"
+	"bus-timing/pkg/metrics"
+	"bus-timing/pkg/middlewares/apikey"
 	"bus-timing/pkg/middlewares/cors"
+	"bus-timing/pkg/middlewares/deprecation"
+	"bus-timing/pkg/middlewares/errorhandler"
+	"bus-timing/pkg/middlewares/jwt"
+	"bus-timing/pkg/middlewares/requestid"
+	"bus-timing/pkg/ratelimit"
+	"bus-timing/pkg/tracing"
 	"bus-timing/pkg/uwave"
+	"bus-timing/pkg/webhook"
 
 	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 )
 
 func RunServer() {
-	router := SetupHTTP()
+	ctx, stop := context.WithCancel(context.Background())
+	defer stop()
+
+	logger, err := logging.New(logging.Options{
+		Level:  config.Config.Log.Level,
+		Format: config.Config.Log.Format,
+	})
+	if err != nil {
+		log.Fatalln("logging: ", err)
+	}
+	// services, middlewares and the standard log package log with it
+	slog.SetDefault(logger)
+
+	shutdownTracing, err := tracing.Setup(ctx, tracing.Options{
+		Exporter:    config.Config.Tracing.Exporter,
+		Endpoint:    config.Config.Tracing.Endpoint,
+		Insecure:    config.Config.Tracing.Insecure,
+		ServiceName: tracingServiceName(),
+		SampleRatio: config.Config.Tracing.SampleRatio,
+	})
+	if err != nil {
+		fatal("tracing", err)
+	}
+
+	services := SetupServices(ctx)
+	router := SetupHTTP(services)
 	srv := &http.Server{
 		Addr:         fmt.Sprintf("%s:%d", config.Config.Server.Host, config.Config.Server.Port),
 		WriteTimeout: time.Second * time.Duration(config.Config.Server.WriteTimeout),
@@ -31,65 +74,536 @@ func RunServer() {
 
 	go func() {
 		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatalln("Server listen: ", err)
+			fatal("server listen", err)
 		}
 	}()
 
+	// metrics are scraped from a listener of their own, not exposed with the API
+	var metricsSrv *http.Server
+	if config.Config.Metrics.Enabled {
+		metricsRouter := http.NewServeMux()
+		metricsRouter.Handle("/metrics", metrics.Handler())
+		metricsSrv = &http.Server{
+			Addr:              fmt.Sprintf("%s:%d", config.Config.Metrics.Host, config.Config.Metrics.Port),
+			ReadHeaderTimeout: time.Second * time.Duration(config.Config.Server.ReadTimeout),
+			Handler:           metricsRouter,
+		}
+		go func() {
+			if err := metricsSrv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				fatal("metrics listen", err)
+			}
+		}()
+	}
+
+	grpcServer := SetupGRPC(services)
+	if config.Config.GRPC.Enabled {
+		listener, err := net.Listen("tcp", fmt.Sprintf("%s:%d", config.Config.Server.Host, config.Config.GRPC.Port))
+		if err != nil {
+			fatal("gRPC listen", err)
+		}
+		go func() {
+			if err := grpcServer.Serve(listener); err != nil {
+				fatal("gRPC serve", err)
+			}
+		}()
+	}
+
 	quit := make(chan os.Signal, 2)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
-	log.Println("shutdown server ...")
+	logger.Info("shutdown server ...")
+	stop()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
 	defer cancel()
 	if err := srv.Shutdown(ctx); err != nil {
-		log.Fatalln("server shutdown:", err)
+		fatal("server shutdown", err)
+	}
+	if metricsSrv != nil {
+		if err := metricsSrv.Shutdown(ctx); err != nil {
+			logger.Warn("metrics server shutdown", "error", err)
+		}
+	}
+	if config.Config.GRPC.Enabled {
+		stopGRPC(ctx, grpcServer)
+	}
+
+	if err := shutdownTracing(ctx); err != nil {
+		logger.Warn("tracing shutdown", "error", err)
 	}
 
 	if _, ok := <-ctx.Done(); ok {
-		log.Println("timeout of 1 second.")
+		logger.Info("timeout of 1 second.")
 	}
 
-	log.Println("server exiting")
+	logger.Info("server exiting")
+}
+
+// fatal logs a failure the service cannot run with and exits.
+func fatal(msg string, err error) {
+	slog.Error(msg, "error", err)
+	os.Exit(1)
+}
+
+// Services are shared by the HTTP and gRPC servers.
+type Services struct {
+	BusLineService         *service.BusLiveService
+	BusLineCatalogue       *service.BusLineCatalogue
+	SearchService          *service.SearchService
+	BusPositionService     *service.BusPositionService
+	RunningBusService      *service.RunningBusService
+	JourneyPlanner         *service.JourneyPlanner
+	TileService            *service.TileService
+	BusPositionBroadcaster *service.BusPositionBroadcaster
+	ArrivalWatcher         *service.ArrivalWatcher
+	ArrivalAlertService    *service.ArrivalAlertService
+	APIKeyService          *service.APIKeyService
+	// Authenticator checks the tokens of the HTTP routes and the gRPC calls
+	Authenticator *jwt.Authenticator
 }
 
-func SetupHTTP() *gin.Engine {
-	router := gin.Default()
+// SetupServices wires services, background jobs run until ctx is done.
+func SetupServices(ctx context.Context) *Services {
+	logger := slog.Default()
+	positionHistoryRepository, err := repository.NewPositionHistoryRepository(
+		config.Config.History.FilePath,
+		time.Hour*time.Duration(config.Config.History.Retention),
+	)
+	if err != nil {
+		fatal("position history", err)
+	}
+	go func() {
+		<-ctx.Done()
+		positionHistoryRepository.Close()
+	}()
 
-	uWaveClient := uwave.UWaveClient{
-		Endpoint: config.Config.UWaveConfig.Endpoint,
+	uWaveClient := uwave.CachedClient{
+		Client: &uwave.UWaveClient{
+			Endpoint: config.Config.UWaveConfig.Endpoint,
+			Logger:   logger,
+		},
+		Cache: setupCache(ctx),
+		BusLine: uwave.CacheOption{
+			KeyPrefix: config.Config.Cache.BusLine.KeyPrefix,
+			TTL:       time.Second * time.Duration(config.Config.Cache.BusLine.TTL),
+		},
+		BusPosition: uwave.CacheOption{
+			KeyPrefix: config.Config.Cache.BusPosition.KeyPrefix,
+			TTL:       time.Second * time.Duration(config.Config.Cache.BusPosition.TTL),
+		},
+		Logger: logger,
 	}
 	busLineService := service.BusLiveService{
 		UWaveClient: &uWaveClient,
 	}
+	busLineCatalogue := service.BusLineCatalogue{
+		BusLineService:  &busLineService,
+		RefreshInterval: time.Second * time.Duration(config.Config.Catalogue.RefreshInterval),
+		Logger:          logger,
+	}
+	searchService := service.SearchService{
+		BusLineCatalogue: &busLineCatalogue,
+	}
+	busLineCatalogue.OnRefresh(searchService.Rebuild)
 	busPositionService := service.BusPositionService{
-		UWaveClient: &uWaveClient,
+		UWaveClient:               &uWaveClient,
+		PositionHistoryRepository: positionHistoryRepository,
+		Logger:                    logger,
 	}
 	runningBusService := service.RunningBusService{
-		UWaveClient: &uWaveClient,
+		UWaveClient:               &uWaveClient,
+		BusLineCatalogue:          &busLineCatalogue,
+		PositionHistoryRepository: positionHistoryRepository,
+		Logger:                    logger,
+	}
+	journeyPlanner := service.JourneyPlanner{
+		BusLineCatalogue:   &busLineCatalogue,
+		RunningBusService:  &runningBusService,
+		MaxWalkingDistance: config.Config.Journey.MaxWalkingDistance,
+		MaxAccessDistance:  config.Config.Journey.MaxAccessDistance,
+		DefaultWaitTime:    time.Second * time.Duration(config.Config.Journey.DefaultWaitTime),
+		Logger:             logger,
+	}
+	busLineCatalogue.OnRefresh(journeyPlanner.Rebuild)
+	tileService := service.TileService{
+		BusLineCatalogue: &busLineCatalogue,
+		MinBusStopZoom:   config.Config.Tile.MinBusStopZoom,
+		CacheSize:        config.Config.Tile.CacheSize,
+	}
+	busLineCatalogue.OnRefresh(tileService.Rebuild)
+	busPositionBroadcaster := service.NewBusPositionBroadcaster(config.Config.Stream.BufferSize)
+	if config.Config.Poller.Enabled {
+		busPositionPoller := service.BusPositionPoller{
+			Interval:               time.Second * time.Duration(config.Config.Poller.Interval),
+			BusLineService:         &busLineCatalogue,
+			BusPositionService:     &busPositionService,
+			BusPositionBroadcaster: busPositionBroadcaster,
+			Logger:                 logger,
+		}
+		go busPositionPoller.Run(ctx)
+	}
+	arrivalWatcher := service.ArrivalWatcher{
+		Interval:          time.Second * time.Duration(config.Config.ArrivalWatch.Interval),
+		RunningBusService: &runningBusService,
+		ChangeMinimum:     time.Second * time.Duration(config.Config.ArrivalWatch.ChangeMinimum),
+		Logger:            logger,
+	}
+	go arrivalWatcher.Run(ctx)
+	arrivalAlertRepository, err := repository.NewArrivalAlertRepository(config.Config.Alert.FilePath)
+	if err != nil {
+		fatal("arrival alerts", err)
+	}
+	if err := checkSecret("alert.secret_key", "ALERT_SECRET_KEY", config.Config.Alert.SecretKey); err != nil {
+		fatal("arrival alerts", err)
 	}
+	arrivalAlertService := service.ArrivalAlertService{
+		Interval:               time.Second * time.Duration(config.Config.Alert.Interval),
+		ArrivalAlertRepository: arrivalAlertRepository,
+		BusLineCatalogue:       &busLineCatalogue,
+		RunningBusService:      &runningBusService,
+		Webhook:                setupWebhook(),
+		DedupWindow:            time.Second * time.Duration(config.Config.Alert.DedupWindow),
+		Logger:                 logger,
+	}
+	go arrivalAlertService.Run(ctx)
+	apiKeyRepository, err := repository.NewAPIKeyRepository(config.Config.APIKeys.FilePath)
+	if err != nil {
+		fatal("api keys", err)
+	}
+	apiKeyService := service.APIKeyService{
+		APIKeyRepository:  apiKeyRepository,
+		Limiter:           setupLimiter(ctx),
+		KeyPrefix:         config.Config.APIKeys.KeyPrefix,
+		DefaultRateLimit:  config.Config.APIKeys.RateLimit,
+		DefaultBurst:      config.Config.APIKeys.Burst,
+		DefaultDailyQuota: config.Config.APIKeys.DailyQuota,
+	}
+
+	return &Services{
+		BusLineService:         &busLineService,
+		BusLineCatalogue:       &busLineCatalogue,
+		SearchService:          &searchService,
+		BusPositionService:     &busPositionService,
+		RunningBusService:      &runningBusService,
+		JourneyPlanner:         &journeyPlanner,
+		TileService:            &tileService,
+		BusPositionBroadcaster: busPositionBroadcaster,
+		ArrivalWatcher:         &arrivalWatcher,
+		ArrivalAlertService:    &arrivalAlertService,
+		APIKeyService:          &apiKeyService,
+		Authenticator:          setupAuthenticator(),
+	}
+}
+
+// tracingServiceName names the service in the traces, bus-timing unless configured.
+func tracingServiceName() string {
+	if config.Config.Tracing.ServiceName == "" {
+		return "bus-timing"
+	}
+	return config.Config.Tracing.ServiceName
+}
+
+// SetupHTTP wires the HTTP ports and routes.
+func SetupHTTP(services *Services) *gin.Engine {
+	router := gin.New()
+	// handlers pass their gin.Context to services, it reads the trace span from the request context
+	router.ContextWithFallback = true
+
 	busLinePort := port.BusLinePort{
-		BusLineService: &busLineService,
+		BusLineService: services.BusLineService,
 	}
+	staleAfter := time.Second * time.Duration(config.Config.API.StaleAfter)
 	busPositionPort := port.BusPositionPort{
-		BusPositionService: &busPositionService,
+		BusPositionService: services.BusPositionService,
+		StaleAfter:         staleAfter,
+	}
+	busPositionStreamPort := port.BusPositionStreamPort{
+		BusLineCatalogue:       services.BusLineCatalogue,
+		BusPositionService:     services.BusPositionService,
+		BusPositionBroadcaster: services.BusPositionBroadcaster,
+		HeartbeatInterval:      time.Second * time.Duration(config.Config.Stream.HeartbeatInterval),
+	}
+	arrivalWebSocketPort := port.ArrivalWebSocketPort{
+		NewArrivalSubscription: func() port.ArrivalSubscription {
+			return services.ArrivalWatcher.NewSubscription()
+		},
+		WriteTimeout:  time.Second * time.Duration(config.Config.ArrivalWatch.WriteTimeout),
+		AllowedOrigin: setupOriginMatcher(),
+	}
+	arrivalAlertPort := port.ArrivalAlertPort{
+		ArrivalAlertService: services.ArrivalAlertService,
 	}
 	runningBusPort := port.RunningBusPort{
-		BusTimingService: &runningBusService,
+		BusTimingService: services.RunningBusService,
+		StaleAfter:       staleAfter,
+	}
+	busStopPort := port.BusStopPort{
+		BusStopService: services.BusLineCatalogue,
+	}
+	searchPort := port.SearchPort{
+		SearchService: services.SearchService,
+	}
+	journeyPort := port.JourneyPort{
+		JourneyPlanner: services.JourneyPlanner,
+		MaxTransfers:   config.Config.Journey.MaxTransfers,
+	}
+	tilePort := port.TilePort{
+		TileService: services.TileService,
+		MaxAge:      time.Second * time.Duration(config.Config.Tile.MaxAge),
+	}
+	authenticator := services.Authenticator
+	authPort := port.AuthPort{
+		Authenticator: authenticator,
+	}
+	adminPort := port.AdminPort{
+		BusLineCatalogue: services.BusLineCatalogue,
+	}
+	apiKeyPort := port.APIKeyPort{
+		APIKeyService: services.APIKeyService,
+	}
+	graphQLPort := port.GraphQLPort{
+		BusLineService:     services.BusLineCatalogue,
+		BusStopService:     services.BusLineCatalogue,
+		BusPositionService: services.BusPositionService,
+		RunningBusService:  services.RunningBusService,
 	}
 
 	router.Use(gin.Recovery())
-	router.Use(cors.CorsMiddleware())
+	router.Use(otelgin.Middleware(tracingServiceName()))
+	router.Use(requestid.RequestIDMiddleware())
+	router.Use(logging.Middleware(slog.Default()))
+	router.Use(errorhandler.ErrorHandlerMiddleware())
+	router.Use(setupCors())
+	if config.Config.Metrics.Enabled {
+		router.Use(metrics.HTTPMiddleware())
+	}
 
 	// health check
 	router.GET("/health", port.HealthCheck)
+	// API documentation
+	router.GET("/openapi.json", port.OpenAPI)
+	router.GET("/docs/*filepath", port.SwaggerUI)
+	// tokens
+	router.POST("/api/auth/token", authPort.IssueToken)
+	router.POST("/api/auth/refresh", authPort.RefreshToken)
 
-	routerGroup := router.Group("api")
-	// routerGroup.Use(jwt.Authorized())
+	// requireToken makes the routes of a group need an access token when auth.groups lists it,
+	// and an API key when api_keys.groups lists it
+	requireToken := func(name string, group *gin.RouterGroup) *gin.RouterGroup {
+		if slices.Contains(config.Config.Auth.Groups, name) {
+			group.Use(authenticator.Authorized())
+		}
+		if slices.Contains(config.Config.APIKeys.Groups, name) {
+			group.Use(apikey.APIKeyMiddleware(services.APIKeyService))
+		}
+		return group
+	}
 
-	routerGroup.GET("/busPosition/:busLineID", busPositionPort.GetBusPosition)
-	routerGroup.GET("/busLines", busLinePort.GetBusLines)
-	routerGroup.GET("/busStop/:busStopID", runningBusPort.EstimatedArrival)
+	// vector tiles of the bus network
+	tileGroup := requireToken("tiles", router.Group("tiles"))
+	tileGroup.GET("/:z/:x/:y", tilePort.GetTile)
+
+	routerGroup := requireToken("api", router.Group("api"))
+
+	// v1 routes replaced by a v2 route
+	v1Sunset := parseSunset(config.Config.API.V1Sunset)
+	routerGroup.GET("/busPosition/:busLineID", deprecation.DeprecationMiddleware("/api/v2/busLines/:busLineID/positions", v1Sunset), busPositionPort.GetBusPosition)
+	routerGroup.GET("/busPosition/:busLineID/history", deprecation.DeprecationMiddleware("/api/v2/busLines/:busLineID/history", v1Sunset), busPositionPort.GetBusPositionHistory)
+	routerGroup.GET("/busLines", deprecation.DeprecationMiddleware("/api/v2/busLines", v1Sunset), busLinePort.GetBusLines)
+	routerGroup.GET("/busStop/:busStopID", deprecation.DeprecationMiddleware("/api/v2/busStops/:busStopID/arrivals", v1Sunset), runningBusPort.EstimatedArrival)
+
+	routerGroup.GET("/busPosition/:busLineID/stream", busPositionStreamPort.StreamBusPosition)
+	routerGroup.GET("/busStops/arrivals/ws", arrivalWebSocketPort.WatchArrivals)
+	routerGroup.GET("/busStops", busStopPort.GetBusStops)
+	routerGroup.GET("/busStops/nearby", busStopPort.GetNearbyBusStops)
+	routerGroup.GET("/busStops/:busStopID", busStopPort.GetBusStop)
+	// alerts call back partners, they are managed with a partner token whatever auth.groups
+	alertGroup := routerGroup.Group("/alerts", authenticator.Authorized(), jwt.RequireRole(jwt.RolePartner))
+	alertGroup.POST("", arrivalAlertPort.CreateAlert)
+	alertGroup.GET("", arrivalAlertPort.GetAlerts)
+	alertGroup.GET("/:alertID", arrivalAlertPort.GetAlert)
+	alertGroup.PUT("/:alertID", arrivalAlertPort.UpdateAlert)
+	alertGroup.DELETE("/:alertID", arrivalAlertPort.DeleteAlert)
+	routerGroup.GET("/search", searchPort.Search)
+	routerGroup.GET("/journeys", journeyPort.GetJourneys)
+	routerGroup.POST("/graphql", graphQLPort.Query)
+
+	v2Group := requireToken("v2", router.Group("api/v2"))
+	v2Group.GET("/busLines", busLinePort.GetBusLinesV2)
+	v2Group.GET("/busLines/:busLineID/positions", busPositionPort.GetBusPositionV2)
+	v2Group.GET("/busLines/:busLineID/history", busPositionPort.GetBusPositionHistoryV2)
+	v2Group.GET("/busStops/:busStopID/arrivals", runningBusPort.EstimatedArrivalV2)
+
+	// administration, for operators and admins
+	adminGroup := router.Group("api/admin")
+	adminGroup.Use(authenticator.Authorized(), jwt.RequireRole(jwt.RoleOperator))
+	adminGroup.POST("/apiKeys", jwt.RequireRole(jwt.RoleAdmin), apiKeyPort.CreateAPIKey)
+	adminGroup.GET("/apiKeys", apiKeyPort.GetAPIKeys)
+	adminGroup.DELETE("/apiKeys/:apiKeyID", jwt.RequireRole(jwt.RoleAdmin), apiKeyPort.DeleteAPIKey)
+	adminGroup.GET("/usage", apiKeyPort.GetUsages)
+	adminGroup.POST("/catalogue/refresh", adminPort.RefreshCatalogue)
 
 	return router
 }
+
+// setupWebhook sends the alerts to public addresses only, unless alert.allow_private_networks is set for local receivers.
+func setupWebhook() *webhook.Sender {
+	timeout := time.Second * time.Duration(config.Config.Alert.Timeout)
+	client := webhook.NewClient(timeout)
+	if config.Config.Alert.AllowPrivateNetworks {
+		client = &http.Client{Timeout: timeout}
+	}
+	return &webhook.Sender{
+		Secret:               config.Config.Alert.SecretKey,
+		Client:               client,
+		MaxAttempts:          config.Config.Alert.MaxAttempts,
+		Backoff:              time.Second * time.Duration(config.Config.Alert.RetryBackoff),
+		AllowPrivateNetworks: config.Config.Alert.AllowPrivateNetworks,
+	}
+}
+
+func corsOptions() cors.Options {
+	return cors.Options{
+		AllowedOrigins:        config.Config.CORS.AllowedOrigins,
+		AllowedOriginPatterns: config.Config.CORS.AllowedOriginPatterns,
+		AllowedMethods:        config.Config.CORS.AllowedMethods,
+		AllowedHeaders:        config.Config.CORS.AllowedHeaders,
+		ExposedHeaders:        config.Config.CORS.ExposedHeaders,
+		AllowCredentials:      config.Config.CORS.AllowCredentials,
+		MaxAge:                config.Config.CORS.MaxAge,
+	}
+}
+
+func setupCors() gin.HandlerFunc {
+	middleware, err := cors.CorsMiddleware(corsOptions())
+	if err != nil {
+		fatal("cors", err)
+	}
+	return middleware
+}
+
+// setupOriginMatcher lets the CORS origins open WebSockets
+func setupOriginMatcher() func(origin string) bool {
+	allowed, err := cors.OriginMatcher(corsOptions())
+	if err != nil {
+		fatal("cors", err)
+	}
+	return allowed
+}
+
+// authGroups are the route groups auth.groups and api_keys.groups can list
+var authGroups = []string{"api", "v2", "tiles"}
+
+// minSecretKeyLength is the shortest signing secret accepted, in bytes
+const minSecretKeyLength = 32
+
+// placeholderSecretKeys are sample secrets that must not sign anything
+var placeholderSecretKeys = []string{"change-me", "changeme", "secret", "your-secret-key", "jwt-secret"}
+
+// checkSecret tells why secret, the value of the config key name set from the environment variable env, cannot sign.
+func checkSecret(name, env, secret string) error {
+	if secret == "" {
+		return fmt.Errorf("no %s, set %s", name, env)
+	}
+	if slices.Contains(placeholderSecretKeys, strings.ToLower(secret)) {
+		return fmt.Errorf("%s is a placeholder, set %s to a random secret", name, env)
+	}
+	if len(secret) < minSecretKeyLength {
+		return fmt.Errorf("%s is shorter than %d bytes", name, minSecretKeyLength)
+	}
+	return nil
+}
+
+// checkSecretKey tells why secret_key_jwt cannot guard the admin routes, they always need a token.
+// Without a secret key HS256 tokens are refused, tokens of auth.jwks.url are then the only way in.
+func checkSecretKey(secretKey string, jwksConfigured bool) error {
+	if secretKey == "" && jwksConfigured {
+		return nil
+	}
+	return checkSecret("secret_key_jwt", "SECRET_KEY_JWT", secretKey)
+}
+
+func setupAuthenticator() *jwt.Authenticator {
+	if err := checkSecretKey(config.Config.SecretKeyJWT, config.Config.Auth.JWKS.URL != ""); err != nil {
+		fatal("auth", err)
+	}
+	authenticator := &jwt.Authenticator{
+		SecretKey:       []byte(config.Config.SecretKeyJWT),
+		Issuer:          config.Config.Auth.Issuer,
+		AccessTokenTTL:  time.Second * time.Duration(config.Config.Auth.AccessTokenTTL),
+		RefreshTokenTTL: time.Second * time.Duration(config.Config.Auth.RefreshTokenTTL),
+		Clients:         make(map[string]jwt.Client),
+	}
+	for _, client := range config.Config.Auth.Clients {
+		authenticator.Clients[client.ID] = jwt.Client{Secret: client.Secret, Roles: client.Roles}
+	}
+	if config.Config.Auth.JWKS.URL != "" {
+		authenticator.JWKS = &jwt.JWKS{
+			URL:             config.Config.Auth.JWKS.URL,
+			Issuer:          config.Config.Auth.JWKS.Issuer,
+			Audience:        config.Config.Auth.JWKS.Audience,
+			RefreshInterval: time.Second * time.Duration(config.Config.Auth.JWKS.RefreshInterval),
+		}
+	}
+
+	for _, group := range append(slices.Clone(config.Config.Auth.Groups), config.Config.APIKeys.Groups...) {
+		if !slices.Contains(authGroups, group) {
+			fatal("auth groups", fmt.Errorf("unknown group %q, expected one of %v", group, authGroups))
+		}
+	}
+	return authenticator
+}
+
+// parseSunset reads a YYYY-MM-DD date, the zero time is returned when it is empty
+func parseSunset(date string) time.Time {
+	if date == "" {
+		return time.Time{}
+	}
+	sunset, err := time.Parse(time.DateOnly, date)
+	if err != nil {
+		fatal("api v1 sunset", err)
+	}
+	return sunset
+}
+
+// cacheSweepInterval is how often the memory cache drops the entries no one reads again
+const cacheSweepInterval = time.Minute
+
+func setupCache(ctx context.Context) cache.Cache {
+	if config.Config.Cache.Driver != "redis" {
+		memoryCache := cache.NewMemoryCache()
+		go memoryCache.Run(ctx, cacheSweepInterval)
+		return memoryCache
+	}
+
+	return &cache.RedisCache{
+		Client: setupRedis(ctx),
+	}
+}
+
+func setupLimiter(ctx context.Context) ratelimit.Limiter {
+	if config.Config.APIKeys.Driver != "redis" {
+		return ratelimit.NewMemoryLimiter()
+	}
+
+	return &ratelimit.RedisLimiter{
+		Client: setupRedis(ctx),
+	}
+}
+
+// setupRedis connects to Redis until ctx is done
+func setupRedis(ctx context.Context) *redis.Client {
+	client := redis.NewClient(&redis.Options{
+		Addr:     fmt.Sprintf("%s:%s", config.Config.Redis.Host, config.Config.Redis.Port),
+		Password: config.Config.Redis.Password,
+		DB:       config.Config.Redis.DB,
+	})
+	if err := client.Ping(ctx).Err(); err != nil {
+		slog.Warn("redis ping", "error", err)
+	}
+	go func() {
+		<-ctx.Done()
+		client.Close()
+	}()
+	return client
+}
@@ -0,0 +1,196 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"bus-timing/internal/aggregate"
+	"bus-timing/internal/core/port"
+	"bus-timing/internal/core/service"
+	"bus-timing/internal/repository"
+	"bus-timing/pkg/middlewares/jwt"
+	"bus-timing/pkg/tracing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// undocumentedRoutes are served but not part of the API
+var undocumentedRoutes = map[string]bool{
+	"GET /docs/*filepath": true,
+}
+
+var ginParam = regexp.MustCompile(`:([^/]+)`)
+
+// testSecretKey signs the tokens of the tests
+const testSecretKey = "test-secret-key-of-at-least-32-bytes"
+
+func TestCheckSecretKey(t *testing.T) {
+	t.Parallel()
+
+	t.Run("happy case", func(tt *testing.T) {
+		assert.NoError(tt, checkSecretKey(testSecretKey, false))
+		// HS256 tokens are refused, the admin routes take JWKS tokens only
+		assert.NoError(tt, checkSecretKey("", true))
+	})
+
+	t.Run("bad case: weak secret keys", func(tt *testing.T) {
+		for _, secretKey := range []string{"", "change-me", "CHANGE-ME", "too-short-secret"} {
+			assert.Error(tt, checkSecretKey(secretKey, false), secretKey)
+		}
+		assert.Error(tt, checkSecretKey("change-me", true))
+	})
+}
+
+func TestSetupHTTP_OpenAPIDocument(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := SetupHTTP(&Services{})
+
+	routes := make([]string, 0)
+	for _, route := range router.Routes() {
+		key := route.Method + " " + route.Path
+		if undocumentedRoutes[key] {
+			continue
+		}
+		routes = append(routes, route.Method+" "+ginParam.ReplaceAllString(route.Path, "{$1}"))
+	}
+
+	documented := make([]string, 0)
+	for path, item := range port.OpenAPIDocument().Paths {
+		for method := range item {
+			documented = append(documented, strings.ToUpper(method)+" "+path)
+		}
+	}
+
+	sort.Strings(routes)
+	sort.Strings(documented)
+	assert.Equal(t, routes, documented, "routes of SetupHTTP and the OpenAPI document differ")
+}
+
+func TestSetupHTTP_OpenAPIParameters(t *testing.T) {
+	pathParam := regexp.MustCompile(`{([^}]+)}`)
+	for path, item := range port.OpenAPIDocument().Paths {
+		for method, operation := range item {
+			documented := make(map[string]bool)
+			for _, parameter := range operation.Parameters {
+				if parameter.In == "path" {
+					documented[parameter.Name] = true
+				}
+			}
+			for _, match := range pathParam.FindAllStringSubmatch(path, -1) {
+				assert.True(t, documented[match[1]], "%s %s does not document path parameter %s", strings.ToUpper(method), path, match[1])
+			}
+			assert.NotEmpty(t, operation.Responses, "%s %s has no response", strings.ToUpper(method), path)
+		}
+	}
+}
+
+func TestSetupHTTP_TraceContext(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	_, err := tracing.Setup(context.Background(), tracing.Options{})
+	assert.NoError(t, err)
+	router := SetupHTTP(&Services{})
+
+	// services get the gin.Context of the handler, the span of the request must be reachable from it
+	var traceID string
+	router.GET("/trace", func(c *gin.Context) {
+		traceID = trace.SpanContextFromContext(c).TraceID().String()
+	})
+	req := httptest.NewRequest(http.MethodGet, "/trace", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", traceID)
+}
+
+func TestSetupHTTP_AlertRoutes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	arrivalAlertRepository, err := repository.NewArrivalAlertRepository("")
+	assert.NoError(t, err)
+	authenticator := &jwt.Authenticator{SecretKey: []byte(testSecretKey)}
+	router := SetupHTTP(&Services{
+		ArrivalAlertService: &service.ArrivalAlertService{ArrivalAlertRepository: arrivalAlertRepository},
+		Authenticator:       authenticator,
+	})
+	tokenOf := func(tt *testing.T, subject string, roles ...string) string {
+		claims := jwt.JWTClaims{ClientID: subject, Roles: roles}
+		claims.Subject = subject
+		tokens, err := authenticator.GenerateJWT(claims)
+		assert.NoError(tt, err)
+		return tokens.AccessToken
+	}
+	token := func(tt *testing.T, roles ...string) string {
+		return tokenOf(tt, "client", roles...)
+	}
+	serveBody := func(method, path, token, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+		return recorder
+	}
+	serve := func(method, path, token string) int {
+		return serveBody(method, path, token, "").Code
+	}
+
+	t.Run("happy case: partners and admins manage alerts", func(tt *testing.T) {
+		assert.Equal(tt, http.StatusOK, serve(http.MethodGet, "/api/alerts", token(tt, jwt.RolePartner)))
+		assert.Equal(tt, http.StatusOK, serve(http.MethodGet, "/api/alerts", token(tt, jwt.RoleAdmin)))
+		assert.Equal(tt, http.StatusNotFound, serve(http.MethodDelete, "/api/alerts/unknown", token(tt, jwt.RolePartner)))
+	})
+
+	t.Run("bad case: alerts of another partner", func(tt *testing.T) {
+		alert, err := arrivalAlertRepository.Create(context.Background(), aggregate.ArrivalAlert{
+			Owner:       "partner-a",
+			BusLineID:   "44480",
+			BusStopID:   "377906",
+			Threshold:   5 * time.Minute,
+			CallbackURL: "https://example.com/hook",
+		})
+		assert.NoError(tt, err)
+		path := "/api/alerts/" + alert.ID
+		owner, other := tokenOf(tt, "partner-a", jwt.RolePartner), tokenOf(tt, "partner-b", jwt.RolePartner)
+
+		resp := port.GetArrivalAlertsResponse{}
+		assert.NoError(tt, json.Unmarshal(serveBody(http.MethodGet, "/api/alerts", owner, "").Body.Bytes(), &resp))
+		assert.Len(tt, resp.Payload, 1)
+		assert.NoError(tt, json.Unmarshal(serveBody(http.MethodGet, "/api/alerts", other, "").Body.Bytes(), &resp))
+		assert.Empty(tt, resp.Payload)
+
+		body := `{"busLineID": "44480", "busStopID": "377906", "threshold": 60, "callbackURL": "https://example.com/other"}`
+		assert.Equal(tt, http.StatusNotFound, serve(http.MethodGet, path, other))
+		assert.Equal(tt, http.StatusNotFound, serveBody(http.MethodPut, path, other, body).Code)
+		assert.Equal(tt, http.StatusNotFound, serve(http.MethodDelete, path, other))
+
+		assert.Equal(tt, http.StatusOK, serve(http.MethodGet, path, owner))
+		assert.Equal(tt, http.StatusNoContent, serve(http.MethodDelete, path, owner))
+	})
+
+	t.Run("bad case: alerts are not public", func(tt *testing.T) {
+		for _, route := range [][2]string{
+			{http.MethodPost, "/api/alerts"},
+			{http.MethodGet, "/api/alerts"},
+			{http.MethodGet, "/api/alerts/1"},
+			{http.MethodPut, "/api/alerts/1"},
+			{http.MethodDelete, "/api/alerts/1"},
+		} {
+			assert.Equal(tt, http.StatusUnauthorized, serve(route[0], route[1], ""), route)
+			assert.Equal(tt, http.StatusForbidden, serve(route[0], route[1], token(tt, jwt.RoleRider)), route)
+		}
+	})
+
+	t.Run("bad case: tokens signed with another key", func(tt *testing.T) {
+		forged, err := (&jwt.Authenticator{SecretKey: []byte("change-me")}).GenerateJWT(jwt.JWTClaims{Roles: []string{jwt.RoleAdmin}})
+		assert.NoError(tt, err)
+		assert.Equal(tt, http.StatusUnauthorized, serve(http.MethodGet, "/api/alerts", forged.AccessToken))
+	})
+}
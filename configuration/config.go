@@ -1,15 +1,35 @@
 package configuration
 
 import (
+	"strings"
+
 	"github.com/spf13/viper"
 )
 
 var Config Configs
 
 type Configs struct {
-	Server       Server      `mapstructure:"server"`
-	UWaveConfig  UWaveConfig `mapstructure:"uwave"`
-	SecretKeyJWT string      `mapstructure:"secret_key_jwt"`
+	Server       Server       `mapstructure:"server"`
+	API          API          `mapstructure:"api"`
+	CORS         CORS         `mapstructure:"cors"`
+	GRPC         GRPC         `mapstructure:"grpc"`
+	UWaveConfig  UWaveConfig  `mapstructure:"uwave"`
+	SecretKeyJWT string       `mapstructure:"secret_key_jwt"`
+	Auth         Auth         `mapstructure:"auth"`
+	APIKeys      APIKeys      `mapstructure:"api_keys"`
+	Catalogue    Catalogue    `mapstructure:"catalogue"`
+	Journey      Journey      `mapstructure:"journey"`
+	Tile         Tile         `mapstructure:"tile"`
+	Poller       Poller       `mapstructure:"poller"`
+	Stream       Stream       `mapstructure:"stream"`
+	ArrivalWatch ArrivalWatch `mapstructure:"arrival_watch"`
+	Alert        Alert        `mapstructure:"alert"`
+	History      History      `mapstructure:"history"`
+	Cache        Cache        `mapstructure:"cache"`
+	Redis        Redis        `mapstructure:"redis"`
+	Metrics      Metrics      `mapstructure:"metrics"`
+	Tracing      Tracing      `mapstructure:"tracing"`
+	Log          Log          `mapstructure:"log"`
 }
 
 type Server struct {
@@ -20,19 +40,162 @@ type Server struct {
 	ReadTimeout  int    `mapstructure:"read_timeout"`
 }
 
+type API struct {
+	// StaleAfter is how many seconds old a bus position can be before v2 flags it as stale
+	StaleAfter int `mapstructure:"stale_after"`
+	// V1Sunset is the YYYY-MM-DD date deprecated v1 routes are removed, no Sunset header is sent when empty
+	V1Sunset string `mapstructure:"v1_sunset"`
+}
+
+type CORS struct {
+	// AllowedOrigins are exact origins, "*" allows every origin but not with credentials
+	AllowedOrigins []string `mapstructure:"allowed_origins"`
+	// AllowedOriginPatterns are regular expressions matching whole origins
+	AllowedOriginPatterns []string `mapstructure:"allowed_origin_patterns"`
+	AllowedMethods        []string `mapstructure:"allowed_methods"`
+	AllowedHeaders        []string `mapstructure:"allowed_headers"`
+	ExposedHeaders        []string `mapstructure:"exposed_headers"`
+	AllowCredentials      bool     `mapstructure:"allow_credentials"`
+	// MaxAge is how many seconds browsers may cache a preflight response
+	MaxAge int `mapstructure:"max_age"`
+}
+
+type Auth struct {
+	// Groups are the route groups requiring an access token, among api (the /api routes but v2), v2 and tiles
+	Groups []string `mapstructure:"groups"`
+	// Issuer is the iss claim of the tokens signed with SecretKeyJWT
+	Issuer          string       `mapstructure:"issuer"`
+	AccessTokenTTL  int          `mapstructure:"access_token_ttl"`
+	RefreshTokenTTL int          `mapstructure:"refresh_token_ttl"`
+	Clients         []AuthClient `mapstructure:"clients"`
+	JWKS            JWKS         `mapstructure:"jwks"`
+}
+
+// AuthClient can exchange its secret for tokens carrying its roles, among rider, partner, operator and admin
+type AuthClient struct {
+	ID     string   `mapstructure:"id"`
+	Secret string   `mapstructure:"secret"`
+	Roles  []string `mapstructure:"roles"`
+}
+
+// JWKS verifies the RS256 tokens of an external issuer, they are refused when URL is empty
+type JWKS struct {
+	URL             string `mapstructure:"url"`
+	Issuer          string `mapstructure:"issuer"`
+	Audience        string `mapstructure:"audience"`
+	RefreshInterval int    `mapstructure:"refresh_interval"`
+}
+
+type APIKeys struct {
+	// Groups are the route groups requiring an API key, among the ones of Auth.Groups
+	Groups []string `mapstructure:"groups"`
+	// FilePath is where keys are persisted, keys are kept in memory only when empty
+	FilePath string `mapstructure:"file_path"`
+	// Driver keeps rate limits and usage either in "memory" (default) or in "redis", shared between instances
+	Driver    string `mapstructure:"driver"`
+	KeyPrefix string `mapstructure:"key_prefix"`
+	// RateLimit (requests per second), Burst and DailyQuota apply to keys issued without limits
+	RateLimit  float64 `mapstructure:"rate_limit"`
+	Burst      int     `mapstructure:"burst"`
+	DailyQuota int64   `mapstructure:"daily_quota"`
+}
+
+type GRPC struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Port is served on Server.Host
+	Port int `mapstructure:"port"`
+	// Reflection lets clients list the services, for grpcurl and other tools in development
+	Reflection bool `mapstructure:"reflection"`
+}
+
 type UWaveConfig struct {
 	Endpoint string `mapstructure:"endpoint"`
 }
 
+type Catalogue struct {
+	RefreshInterval int `mapstructure:"refresh_interval"`
+}
+
+type Journey struct {
+	MaxTransfers       int     `mapstructure:"max_transfers"`
+	MaxWalkingDistance float64 `mapstructure:"max_walking_distance"`
+	MaxAccessDistance  float64 `mapstructure:"max_access_distance"`
+	DefaultWaitTime    int     `mapstructure:"default_wait_time"`
+}
+
+type Tile struct {
+	// MinBusStopZoom is the lowest zoom tiles have bus stops
+	MinBusStopZoom int `mapstructure:"min_bus_stop_zoom"`
+	// CacheSize is the number of rendered tiles kept in memory
+	CacheSize int `mapstructure:"cache_size"`
+	// MaxAge is how many seconds clients may cache a tile
+	MaxAge int `mapstructure:"max_age"`
+}
+
+type Poller struct {
+	Enabled  bool `mapstructure:"enabled"`
+	Interval int  `mapstructure:"interval"`
+}
+
+type Stream struct {
+	HeartbeatInterval int `mapstructure:"heartbeat_interval"`
+	// BufferSize is the number of events kept per bus line to resume a stream
+	BufferSize int `mapstructure:"buffer_size"`
+}
+
+type ArrivalWatch struct {
+	Interval int `mapstructure:"interval"`
+	// ChangeMinimum is how many seconds an ETA must move before subscribers are told about it
+	ChangeMinimum int `mapstructure:"change_minimum"`
+	WriteTimeout  int `mapstructure:"write_timeout"`
+}
+
+type Alert struct {
+	Interval int `mapstructure:"interval"`
+	// FilePath is where alerts are persisted, alerts are kept in memory only when empty
+	FilePath string `mapstructure:"file_path"`
+	// SecretKey signs webhook bodies with HMAC-SHA256, it is required
+	SecretKey    string `mapstructure:"secret_key"`
+	Timeout      int    `mapstructure:"timeout"`
+	MaxAttempts  int    `mapstructure:"max_attempts"`
+	RetryBackoff int    `mapstructure:"retry_backoff"`
+	// AllowPrivateNetworks lets callbacks reach loopback and private addresses, for local receivers only
+	AllowPrivateNetworks bool `mapstructure:"allow_private_networks"`
+	// DedupWindow is how many seconds a bus must be gone before it can trigger the same alert again
+	DedupWindow int `mapstructure:"dedup_window"`
+}
+
+type History struct {
+	// FilePath is where positions are persisted, history is kept in memory only when empty
+	FilePath  string `mapstructure:"file_path"`
+	Retention int    `mapstructure:"retention"`
+}
+
+type Cache struct {
+	// Driver is either "memory" (default) or "redis"
+	Driver      string     `mapstructure:"driver"`
+	BusLine     CacheEntry `mapstructure:"bus_line"`
+	BusPosition CacheEntry `mapstructure:"bus_position"`
+}
+
+type CacheEntry struct {
+	KeyPrefix string `mapstructure:"key_prefix"`
+	TTL       int    `mapstructure:"ttl"`
+}
+
 type Redis struct {
-	Host string `mapstructure:"host"`
-	Port string `mapstructure:"port"`
+	Host     string `mapstructure:"host"`
+	Port     string `mapstructure:"port"`
+	Password string `mapstructure:"password"`
+	DB       int    `mapstructure:"db"`
 }
 
 func LoadConfig(path string) (err error) {
 	viper.AddConfigPath(path)
 	viper.SetConfigName("config")
 	viper.SetConfigType("yaml")
+	// environment variables override keys of the file, nested ones with _ for ., e.g. ALERT_SECRET_KEY for alert.secret_key
+	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
 	viper.AutomaticEnv()
 
 	err = viper.ReadInConfig()
@@ -43,3 +206,27 @@ func LoadConfig(path string) (err error) {
 	err = viper.Unmarshal(&Config)
 	return
 }
+
+type Metrics struct {
+	// Enabled serves the Prometheus metrics on /metrics of a listener of their own, apart from the API
+	Enabled bool   `mapstructure:"enabled"`
+	Host    string `mapstructure:"host"`
+	Port    int    `mapstructure:"port"`
+}
+
+type Tracing struct {
+	// Exporter is otlp, stdout (for local use) or empty to only propagate trace contexts
+	Exporter string `mapstructure:"exporter"`
+	// Endpoint is the host:port of the OTLP/HTTP collector
+	Endpoint    string  `mapstructure:"endpoint"`
+	Insecure    bool    `mapstructure:"insecure"`
+	ServiceName string  `mapstructure:"service_name"`
+	SampleRatio float64 `mapstructure:"sample_ratio"`
+}
+
+type Log struct {
+	// Level is debug, info, warn or error
+	Level string `mapstructure:"level"`
+	// Format is json or text
+	Format string `mapstructure:"format"`
+}
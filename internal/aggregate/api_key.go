@@ -0,0 +1,27 @@
+package aggregate
+
+import "time"
+
+// APIKey lets a partner app call the API, at most RateLimit requests per second with bursts of Burst requests,
+// and DailyQuota requests per UTC day
+type APIKey struct {
+	ID   string
+	Name string
+	// KeyHash is the hex SHA-256 of the key, the key itself is only known when it is issued
+	KeyHash    string
+	RateLimit  float64
+	Burst      int
+	DailyQuota int64
+	CreatedAt  time.Time
+}
+
+// APIKeyUsage counts the requests made with an API key during a UTC day
+type APIKeyUsage struct {
+	APIKeyID string
+	Name     string
+	Day      time.Time
+	// Requests counts the requests that got past the rate limit, QuotaExceeded of them were refused
+	Requests      int64
+	RateLimited   int64
+	QuotaExceeded int64
+}
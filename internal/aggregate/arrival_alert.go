@@ -0,0 +1,27 @@
+package aggregate
+
+import "time"
+
+// ArrivalAlert asks for CallbackURL to be called when a bus of the bus line is Threshold away from the bus stop
+type ArrivalAlert struct {
+	ID string
+	// Owner is the subject of the token that created the alert, only its owner sees and changes it
+	Owner       string
+	BusLineID   string
+	BusStopID   string
+	Threshold   time.Duration
+	CallbackURL string
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// ArrivalAlertNotification is delivered to the callback of an alert, once per bus
+type ArrivalAlertNotification struct {
+	AlertID      string
+	BusLineID    string
+	BusStopID    string
+	VehiclePlate string
+	ETA          time.Duration
+	Distance     float64
+	TriggeredAt  time.Time
+}
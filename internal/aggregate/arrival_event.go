@@ -0,0 +1,10 @@
+package aggregate
+
+import "time"
+
+// ArrivalEvent is a snapshot of the incoming buses of a bus stop published when their ETAs change
+type ArrivalEvent struct {
+	BusStopID     string
+	IncomingBuses []IncomingBus
+	PublishedAt   time.Time
+}
@@ -1,9 +1,15 @@
 package aggregate
 
-import "bus-timing/internal/entity"
+import (
+	"time"
+
+	"bus-timing/internal/entity"
+)
 
 type BusPosition struct {
 	Bus                entity.Bus
 	RunningBus         entity.RunningBus
 	RunningBusPosition entity.RunningBusPosition
+	// UpdatedAt is when the position was fetched from uWave
+	UpdatedAt time.Time
 }
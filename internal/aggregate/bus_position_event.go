@@ -0,0 +1,11 @@
+package aggregate
+
+import "time"
+
+// BusPositionEvent is a snapshot of the running buses of a bus line published when positions change
+type BusPositionEvent struct {
+	ID           uint64
+	BusLineID    string
+	BusPositions []BusPosition
+	PublishedAt  time.Time
+}
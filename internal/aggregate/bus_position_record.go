@@ -0,0 +1,19 @@
+package aggregate
+
+import (
+	"time"
+
+	"bus-timing/internal/entity"
+)
+
+type BusPositionRecord struct {
+	BusLineID          string
+	Bus                entity.Bus
+	RunningBusPosition entity.RunningBusPosition
+	RecordedAt         time.Time
+}
+
+type BusTrack struct {
+	VehiclePlate string
+	Positions    []BusPositionRecord
+}
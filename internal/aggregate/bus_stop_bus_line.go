@@ -0,0 +1,14 @@
+package aggregate
+
+import "bus-timing/internal/entity"
+
+type BusStopBusLines struct {
+	BusStop  entity.BusStop
+	BusLines []ServedBusLine
+}
+
+// ServedBusLine is a bus line passing a bus stop, StopOrder is the 1-based position of the stop on the line
+type ServedBusLine struct {
+	BusLine   entity.BusLine
+	StopOrder int
+}
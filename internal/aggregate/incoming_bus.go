@@ -11,5 +11,8 @@ type IncomingBus struct {
 	BusLine     entity.BusLine
 	BusPosition entity.RunningBusPosition
 	Distance    float64
+	// ArrivalTime is how long the bus needs to run Distance at the speed of its crowd level
 	ArrivalTime time.Duration
+	// UpdatedAt is when the bus position was fetched from uWave
+	UpdatedAt time.Time
 }
@@ -0,0 +1,34 @@
+package aggregate
+
+import (
+	"time"
+
+	"bus-timing/internal/entity"
+	"bus-timing/pkg/common"
+	"bus-timing/pkg/location"
+)
+
+// JourneyPlace is where a journey starts or ends, a bus stop when BusStopID is set, a coordinate otherwise
+type JourneyPlace struct {
+	BusStopID string
+	Location  location.Location
+}
+
+type Journey struct {
+	Legs      []JourneyLeg
+	Duration  time.Duration
+	Transfers int
+}
+
+// JourneyLeg is a walk, a wait for BusLine at From, or a ride on BusLine from From to To.
+// From and To of a walk to or from a coordinate have an empty ID.
+type JourneyLeg struct {
+	Mode     common.JourneyLegMode
+	From     entity.BusStop
+	To       entity.BusStop
+	BusLine  entity.BusLine
+	Distance float64
+	Duration time.Duration
+	// Live is set on a wait estimated from the position of a running bus
+	Live bool
+}
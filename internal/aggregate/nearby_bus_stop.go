@@ -0,0 +1,7 @@
+package aggregate
+
+// NearbyBusStop is a bus stop with its straight line distance in meters from the searched location
+type NearbyBusStop struct {
+	BusStop  BusStopBusLines
+	Distance float64
+}
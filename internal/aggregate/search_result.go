@@ -0,0 +1,14 @@
+package aggregate
+
+import (
+	"bus-timing/internal/entity"
+	"bus-timing/pkg/common"
+)
+
+// SearchResult holds either a bus stop or a bus line depending on Type
+type SearchResult struct {
+	Type    common.SearchResultType
+	BusStop entity.BusStop
+	BusLine entity.BusLine
+	Score   float64
+}
@@ -0,0 +1,24 @@
+package port
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AdminPort struct {
+	BusLineCatalogue interface {
+		Refresh(ctx context.Context) error
+	}
+}
+
+// RefreshCatalogue reloads the bus lines and bus stops, and rebuilds the indexes and tiles built from them.
+func (port *AdminPort) RefreshCatalogue(ctx *gin.Context) {
+	if err := port.BusLineCatalogue.Refresh(ctx); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
@@ -0,0 +1,169 @@
+package port
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"bus-timing/internal/aggregate"
+	"bus-timing/pkg/apperror"
+
+	"github.com/gin-gonic/gin"
+)
+
+type APIKeyPort struct {
+	APIKeyService interface {
+		CreateAPIKey(ctx context.Context, apiKey aggregate.APIKey) (aggregate.APIKey, string, error)
+		GetAPIKeys(ctx context.Context) ([]aggregate.APIKey, error)
+		DeleteAPIKey(ctx context.Context, apiKeyID string) error
+		GetUsages(ctx context.Context, day time.Time) ([]aggregate.APIKeyUsage, error)
+	}
+}
+
+// APIKeyRequest limits left out or 0 take the configured defaults, rateLimit is in requests per second
+type APIKeyRequest struct {
+	Name       string  `json:"name" binding:"required"`
+	RateLimit  float64 `json:"rateLimit"`
+	Burst      int     `json:"burst"`
+	DailyQuota int64   `json:"dailyQuota"`
+}
+
+type GetAPIKeyResponse struct {
+	Payload APIKeyPayload `json:"payload"`
+	Status  int           `json:"status"`
+}
+
+type GetAPIKeysResponse struct {
+	Payload []APIKeyPayload `json:"payload"`
+	Status  int             `json:"status"`
+}
+
+// APIKeyPayload key is only answered when the key is issued
+type APIKeyPayload struct {
+	ID         string    `json:"id"`
+	Name       string    `json:"name"`
+	Key        string    `json:"key,omitempty"`
+	RateLimit  float64   `json:"rateLimit"`
+	Burst      int       `json:"burst"`
+	DailyQuota int64     `json:"dailyQuota"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+type GetAPIKeyUsagesResponse struct {
+	Payload []APIKeyUsagePayload `json:"payload"`
+	Status  int                  `json:"status"`
+}
+
+// APIKeyUsagePayload day is a YYYY-MM-DD UTC date
+type APIKeyUsagePayload struct {
+	APIKeyID      string `json:"apiKeyID"`
+	Name          string `json:"name"`
+	Day           string `json:"day"`
+	Requests      int64  `json:"requests"`
+	RateLimited   int64  `json:"rateLimited"`
+	QuotaExceeded int64  `json:"quotaExceeded"`
+}
+
+func (port *APIKeyPort) CreateAPIKey(ctx *gin.Context) {
+	req := APIKeyRequest{}
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Error(apperror.InvalidInput("invalid body: %s", err))
+		return
+	}
+
+	apiKey, key, err := port.APIKeyService.CreateAPIKey(ctx, aggregate.APIKey{
+		Name:       req.Name,
+		RateLimit:  req.RateLimit,
+		Burst:      req.Burst,
+		DailyQuota: req.DailyQuota,
+	})
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	payload := toAPIKeyPayload(apiKey)
+	payload.Key = key
+	ctx.JSON(http.StatusCreated, GetAPIKeyResponse{
+		Payload: payload,
+		Status:  statusSuccess,
+	})
+}
+
+func (port *APIKeyPort) GetAPIKeys(ctx *gin.Context) {
+	apiKeys, err := port.APIKeyService.GetAPIKeys(ctx)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	payload := make([]APIKeyPayload, 0, len(apiKeys))
+	for _, val := range apiKeys {
+		payload = append(payload, toAPIKeyPayload(val))
+	}
+	ctx.JSON(http.StatusOK, GetAPIKeysResponse{
+		Payload: payload,
+		Status:  statusSuccess,
+	})
+}
+
+func (port *APIKeyPort) DeleteAPIKey(ctx *gin.Context) {
+	apiKeyID := ctx.Param("apiKeyID")
+	if apiKeyID == "" {
+		ctx.Error(apperror.InvalidInput("invalid api key: %s", apiKeyID))
+		return
+	}
+
+	if err := port.APIKeyService.DeleteAPIKey(ctx, apiKeyID); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// GetUsages answers the usage of every API key during the day query parameter, today by default.
+func (port *APIKeyPort) GetUsages(ctx *gin.Context) {
+	day := time.Now()
+	if val := ctx.Query("day"); val != "" {
+		parsed, err := time.Parse(time.DateOnly, val)
+		if err != nil {
+			ctx.Error(apperror.InvalidInput("invalid day: %s", val))
+			return
+		}
+		day = parsed
+	}
+
+	usages, err := port.APIKeyService.GetUsages(ctx, day)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	payload := make([]APIKeyUsagePayload, 0, len(usages))
+	for _, val := range usages {
+		payload = append(payload, APIKeyUsagePayload{
+			APIKeyID:      val.APIKeyID,
+			Name:          val.Name,
+			Day:           val.Day.Format(time.DateOnly),
+			Requests:      val.Requests,
+			RateLimited:   val.RateLimited,
+			QuotaExceeded: val.QuotaExceeded,
+		})
+	}
+	ctx.JSON(http.StatusOK, GetAPIKeyUsagesResponse{
+		Payload: payload,
+		Status:  statusSuccess,
+	})
+}
+
+func toAPIKeyPayload(apiKey aggregate.APIKey) APIKeyPayload {
+	return APIKeyPayload{
+		ID:         apiKey.ID,
+		Name:       apiKey.Name,
+		RateLimit:  apiKey.RateLimit,
+		Burst:      apiKey.Burst,
+		DailyQuota: apiKey.DailyQuota,
+		CreatedAt:  apiKey.CreatedAt,
+	}
+}
@@ -0,0 +1,204 @@
+package port
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"bus-timing/internal/aggregate"
+	"bus-timing/pkg/apperror"
+	"bus-timing/pkg/middlewares/jwt"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ArrivalAlertPort struct {
+	ArrivalAlertService interface {
+		CreateAlert(ctx context.Context, alert aggregate.ArrivalAlert) (aggregate.ArrivalAlert, error)
+		GetAlert(ctx context.Context, owner, alertID string) (aggregate.ArrivalAlert, error)
+		GetAlerts(ctx context.Context, owner string) ([]aggregate.ArrivalAlert, error)
+		UpdateAlert(ctx context.Context, alert aggregate.ArrivalAlert) (aggregate.ArrivalAlert, error)
+		DeleteAlert(ctx context.Context, owner, alertID string) error
+	}
+}
+
+// ArrivalAlertRequest threshold is in seconds
+type ArrivalAlertRequest struct {
+	BusLineID   string `json:"busLineID" binding:"required"`
+	BusStopID   string `json:"busStopID" binding:"required"`
+	Threshold   int64  `json:"threshold" binding:"required"`
+	CallbackURL string `json:"callbackURL" binding:"required"`
+}
+
+type GetArrivalAlertResponse struct {
+	Payload ArrivalAlertPayload `json:"payload"`
+	Status  int                 `json:"status"`
+}
+
+type GetArrivalAlertsResponse struct {
+	Payload []ArrivalAlertPayload `json:"payload"`
+	Status  int                   `json:"status"`
+}
+
+// ArrivalAlertPayload threshold is in seconds
+type ArrivalAlertPayload struct {
+	ID          string    `json:"id"`
+	BusLineID   string    `json:"busLineID"`
+	BusStopID   string    `json:"busStopID"`
+	Threshold   int64     `json:"threshold"`
+	CallbackURL string    `json:"callbackURL"`
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+func (port *ArrivalAlertPort) CreateAlert(ctx *gin.Context) {
+	owner, err := alertOwner(ctx)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	req := ArrivalAlertRequest{}
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Error(apperror.InvalidInput("invalid body: %s", err))
+		return
+	}
+
+	alert, err := port.ArrivalAlertService.CreateAlert(ctx, toArrivalAlert(owner, "", req))
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, GetArrivalAlertResponse{
+		Payload: toArrivalAlertPayload(alert),
+		Status:  statusSuccess,
+	})
+}
+
+func (port *ArrivalAlertPort) GetAlerts(ctx *gin.Context) {
+	owner, err := alertOwner(ctx)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	alerts, err := port.ArrivalAlertService.GetAlerts(ctx, owner)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	payload := make([]ArrivalAlertPayload, 0, len(alerts))
+	for _, val := range alerts {
+		payload = append(payload, toArrivalAlertPayload(val))
+	}
+	ctx.JSON(http.StatusOK, GetArrivalAlertsResponse{
+		Payload: payload,
+		Status:  statusSuccess,
+	})
+}
+
+func (port *ArrivalAlertPort) GetAlert(ctx *gin.Context) {
+	owner, err := alertOwner(ctx)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	alertID := ctx.Param("alertID")
+	if alertID == "" {
+		ctx.Error(apperror.InvalidInput("invalid alert: %s", alertID))
+		return
+	}
+
+	alert, err := port.ArrivalAlertService.GetAlert(ctx, owner, alertID)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, GetArrivalAlertResponse{
+		Payload: toArrivalAlertPayload(alert),
+		Status:  statusSuccess,
+	})
+}
+
+func (port *ArrivalAlertPort) UpdateAlert(ctx *gin.Context) {
+	owner, err := alertOwner(ctx)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	alertID := ctx.Param("alertID")
+	if alertID == "" {
+		ctx.Error(apperror.InvalidInput("invalid alert: %s", alertID))
+		return
+	}
+	req := ArrivalAlertRequest{}
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Error(apperror.InvalidInput("invalid body: %s", err))
+		return
+	}
+
+	alert, err := port.ArrivalAlertService.UpdateAlert(ctx, toArrivalAlert(owner, alertID, req))
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, GetArrivalAlertResponse{
+		Payload: toArrivalAlertPayload(alert),
+		Status:  statusSuccess,
+	})
+}
+
+func (port *ArrivalAlertPort) DeleteAlert(ctx *gin.Context) {
+	owner, err := alertOwner(ctx)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	alertID := ctx.Param("alertID")
+	if alertID == "" {
+		ctx.Error(apperror.InvalidInput("invalid alert: %s", alertID))
+		return
+	}
+
+	if err := port.ArrivalAlertService.DeleteAlert(ctx, owner, alertID); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// alertOwner is the subject of the token of the request, alerts belong to it
+func alertOwner(ctx *gin.Context) (string, error) {
+	claims, ok := jwt.GetClaims(ctx)
+	if !ok || claims.Subject == "" {
+		return "", apperror.Unauthorized("invalid token: missing subject")
+	}
+	return claims.Subject, nil
+}
+
+func toArrivalAlert(owner, alertID string, req ArrivalAlertRequest) aggregate.ArrivalAlert {
+	return aggregate.ArrivalAlert{
+		ID:          alertID,
+		Owner:       owner,
+		BusLineID:   req.BusLineID,
+		BusStopID:   req.BusStopID,
+		Threshold:   time.Duration(req.Threshold) * time.Second,
+		CallbackURL: req.CallbackURL,
+	}
+}
+
+func toArrivalAlertPayload(alert aggregate.ArrivalAlert) ArrivalAlertPayload {
+	return ArrivalAlertPayload{
+		ID:          alert.ID,
+		BusLineID:   alert.BusLineID,
+		BusStopID:   alert.BusStopID,
+		Threshold:   int64(alert.Threshold / time.Second),
+		CallbackURL: alert.CallbackURL,
+		CreatedAt:   alert.CreatedAt,
+		UpdatedAt:   alert.UpdatedAt,
+	}
+}
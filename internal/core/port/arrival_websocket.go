@@ -0,0 +1,186 @@
+package port
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"bus-timing/internal/aggregate"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/net/websocket"
+)
+
+const defaultArrivalWriteTimeout = 10 * time.Second
+
+// maxArrivalSubscriptions bounds the bus stops a single connection watches
+const maxArrivalSubscriptions = 50
+
+// ArrivalSubscription is the set of bus stops watched by one client.
+type ArrivalSubscription interface {
+	Subscribe(ctx context.Context, busStopID string) error
+	Unsubscribe(busStopID string)
+	BusStops() []string
+	Updates() <-chan struct{}
+	Next() []aggregate.ArrivalEvent
+	Close()
+}
+
+type ArrivalWebSocketPort struct {
+	NewArrivalSubscription func() ArrivalSubscription
+	// WriteTimeout drops a client that does not read its messages in time
+	WriteTimeout time.Duration
+	// AllowedOrigin reports whether pages of another origin may open the WebSocket, only the same origin may when nil
+	AllowedOrigin func(origin string) bool
+}
+
+// ArrivalMessage is sent by clients to change the bus stops they watch, Type is "subscribe" or "unsubscribe".
+type ArrivalMessage struct {
+	Type       string   `json:"type"`
+	BusStopIDs []string `json:"busStopIDs"`
+}
+
+// ArrivalUpdate is sent to clients, Type is "arrival", "subscribed", "unsubscribed" or "error".
+type ArrivalUpdate struct {
+	Type       string               `json:"type"`
+	BusStopID  string               `json:"busStopID,omitempty"`
+	BusStopIDs []string             `json:"busStopIDs,omitempty"`
+	Data       *IncomingBusResponse `json:"data,omitempty"`
+	Error      string               `json:"error,omitempty"`
+}
+
+// WatchArrivals upgrades to a WebSocket on which the client subscribes to bus stops
+// and receives their arrivals every time the ETAs change materially.
+func (port *ArrivalWebSocketPort) WatchArrivals(ctx *gin.Context) {
+	server := websocket.Server{
+		Handshake: func(config *websocket.Config, req *http.Request) error {
+			return port.checkOrigin(req)
+		},
+		Handler: func(conn *websocket.Conn) {
+			port.serve(ctx.Request.Context(), conn)
+		},
+	}
+	server.ServeHTTP(ctx.Writer, ctx.Request)
+}
+
+// checkOrigin refuses the pages of other sites, browsers open WebSockets to any site without CORS.
+// Clients without an Origin are not browsers and are let through.
+func (port *ArrivalWebSocketPort) checkOrigin(req *http.Request) error {
+	origin := req.Header.Get("Origin")
+	if origin == "" {
+		return nil
+	}
+	if originURL, err := url.Parse(origin); err == nil && originURL.Host == req.Host {
+		return nil
+	}
+	if port.AllowedOrigin != nil && port.AllowedOrigin(origin) {
+		return nil
+	}
+	return fmt.Errorf("origin not allowed: %s", origin)
+}
+
+func (port *ArrivalWebSocketPort) serve(ctx context.Context, conn *websocket.Conn) {
+	defer conn.Close()
+
+	// the connection outlives the server read and write timeouts
+	if err := conn.SetDeadline(time.Time{}); err != nil {
+		return
+	}
+
+	writeTimeout := port.WriteTimeout
+	if writeTimeout <= 0 {
+		writeTimeout = defaultArrivalWriteTimeout
+	}
+	var writeMu sync.Mutex
+	send := func(update ArrivalUpdate) error {
+		writeMu.Lock()
+		defer writeMu.Unlock()
+		if err := conn.SetWriteDeadline(time.Now().Add(writeTimeout)); err != nil {
+			return err
+		}
+		return websocket.JSON.Send(conn, update)
+	}
+
+	subscription := port.NewArrivalSubscription()
+	defer subscription.Close()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		defer cancel()
+		port.receive(ctx, conn, subscription, send)
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-subscription.Updates():
+			for _, event := range subscription.Next() {
+				if err := send(transformArrivalEvent(event)); err != nil {
+					// a client too slow to read is dropped, it reconnects and subscribes again
+					return
+				}
+			}
+		}
+	}
+}
+
+// receive handles the messages of the client until the connection is closed.
+func (port *ArrivalWebSocketPort) receive(ctx context.Context, conn *websocket.Conn, subscription ArrivalSubscription, send func(update ArrivalUpdate) error) {
+	for {
+		msg := ArrivalMessage{}
+		if err := websocket.JSON.Receive(conn, &msg); err != nil {
+			return
+		}
+
+		var update ArrivalUpdate
+		switch msg.Type {
+		case "subscribe":
+			update = port.subscribe(ctx, subscription, msg.BusStopIDs)
+		case "unsubscribe":
+			for _, busStopID := range msg.BusStopIDs {
+				subscription.Unsubscribe(busStopID)
+			}
+			update = ArrivalUpdate{Type: "unsubscribed", BusStopIDs: msg.BusStopIDs}
+		default:
+			update = ArrivalUpdate{Type: "error", Error: fmt.Sprintf("invalid message type: %s", msg.Type)}
+		}
+		if err := send(update); err != nil {
+			return
+		}
+	}
+}
+
+func (port *ArrivalWebSocketPort) subscribe(ctx context.Context, subscription ArrivalSubscription, busStopIDs []string) ArrivalUpdate {
+	if len(busStopIDs) == 0 {
+		return ArrivalUpdate{Type: "error", Error: "busStopIDs is required"}
+	}
+	if len(subscription.BusStops())+len(busStopIDs) > maxArrivalSubscriptions {
+		return ArrivalUpdate{Type: "error", Error: fmt.Sprintf("at most %d bus stops can be watched", maxArrivalSubscriptions)}
+	}
+
+	subscribed := make([]string, 0, len(busStopIDs))
+	for _, busStopID := range busStopIDs {
+		if busStopID == "" {
+			return ArrivalUpdate{Type: "error", BusStopIDs: subscribed, Error: fmt.Sprintf("invalid bus stop: %s", busStopID)}
+		}
+		if err := subscription.Subscribe(ctx, busStopID); err != nil {
+			return ArrivalUpdate{Type: "error", BusStopID: busStopID, BusStopIDs: subscribed, Error: err.Error()}
+		}
+		subscribed = append(subscribed, busStopID)
+	}
+	return ArrivalUpdate{Type: "subscribed", BusStopIDs: subscribed}
+}
+
+func transformArrivalEvent(event aggregate.ArrivalEvent) ArrivalUpdate {
+	data := transformIncomingBusToEstimatedArrival(event.IncomingBuses)
+	return ArrivalUpdate{
+		Type:      "arrival",
+		BusStopID: event.BusStopID,
+		Data:      &data,
+	}
+}
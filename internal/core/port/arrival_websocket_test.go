@@ -0,0 +1,58 @@
+package port
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"bus-timing/internal/aggregate"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/websocket"
+)
+
+type mockArrivalSubscription struct{}
+
+func (mockArrivalSubscription) Subscribe(ctx context.Context, busStopID string) error { return nil }
+func (mockArrivalSubscription) Unsubscribe(busStopID string)                          {}
+func (mockArrivalSubscription) BusStops() []string                                    { return nil }
+func (mockArrivalSubscription) Updates() <-chan struct{}                              { return nil }
+func (mockArrivalSubscription) Next() []aggregate.ArrivalEvent                        { return nil }
+func (mockArrivalSubscription) Close()                                                {}
+
+func TestArrivalWebSocketPort_WatchArrivals(t *testing.T) {
+	t.Parallel()
+
+	gin.SetMode(gin.TestMode)
+	port := &ArrivalWebSocketPort{
+		NewArrivalSubscription: func() ArrivalSubscription {
+			return mockArrivalSubscription{}
+		},
+		AllowedOrigin: func(origin string) bool {
+			return origin == "https://app.example.com"
+		},
+	}
+	router := gin.New()
+	router.GET("/ws/arrivals", port.WatchArrivals)
+	server := httptest.NewServer(router)
+	defer server.Close()
+	endpoint := "ws" + strings.TrimPrefix(server.URL, "http") + "/ws/arrivals"
+
+	t.Run("happy case: same and allowed origins", func(tt *testing.T) {
+		for _, origin := range []string{server.URL, "https://app.example.com"} {
+			conn, err := websocket.Dial(endpoint, "", origin)
+			if assert.NoError(tt, err, origin) {
+				conn.Close()
+			}
+		}
+	})
+
+	t.Run("bad case: other sites", func(tt *testing.T) {
+		for _, origin := range []string{"https://evil.example.com", "http://app.example.com"} {
+			_, err := websocket.Dial(endpoint, "", origin)
+			assert.Error(tt, err, origin)
+		}
+	})
+}
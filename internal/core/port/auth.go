@@ -0,0 +1,71 @@
+package port
+
+import (
+	"net/http"
+
+	"bus-timing/pkg/apperror"
+	"bus-timing/pkg/middlewares/jwt"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AuthPort struct {
+	Authenticator interface {
+		IssueToken(clientID, clientSecret string) (jwt.TokenPair, error)
+		RefreshToken(refreshToken string) (jwt.TokenPair, error)
+	}
+}
+
+type TokenRequest struct {
+	ClientID     string `json:"clientID" binding:"required"`
+	ClientSecret string `json:"clientSecret" binding:"required"`
+}
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refreshToken" binding:"required"`
+}
+
+type GetTokenResponse struct {
+	Payload jwt.TokenPair `json:"payload"`
+	Status  int           `json:"status"`
+}
+
+// IssueToken exchanges client credentials for an access token and a refresh token.
+func (port *AuthPort) IssueToken(ctx *gin.Context) {
+	req := TokenRequest{}
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Error(apperror.InvalidInput("invalid body: %s", err))
+		return
+	}
+
+	tokens, err := port.Authenticator.IssueToken(req.ClientID, req.ClientSecret)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, GetTokenResponse{
+		Payload: tokens,
+		Status:  statusSuccess,
+	})
+}
+
+// RefreshToken exchanges a refresh token for new tokens.
+func (port *AuthPort) RefreshToken(ctx *gin.Context) {
+	req := RefreshTokenRequest{}
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Error(apperror.InvalidInput("invalid body: %s", err))
+		return
+	}
+
+	tokens, err := port.Authenticator.RefreshToken(req.RefreshToken)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, GetTokenResponse{
+		Payload: tokens,
+		Status:  statusSuccess,
+	})
+}
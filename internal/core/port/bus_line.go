@@ -3,8 +3,13 @@ package port
 import (
 	"context"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"bus-timing/internal/aggregate"
+	"bus-timing/internal/entity"
+	"bus-timing/pkg/apperror"
+	"bus-timing/pkg/location"
 
 	"github.com/gin-gonic/gin"
 )
@@ -13,6 +18,9 @@ var (
 	statusSuccess = 100000
 )
 
+// maxPathTolerance bounds the simplification tolerance in meters
+const maxPathTolerance = 5000.0
+
 type BusLinePort struct {
 	BusLineService interface {
 		GetBusLines(ctx context.Context) ([]aggregate.BusLineBusStop, error)
@@ -27,13 +35,15 @@ type GetBusLineResponse struct {
 	Status  int              `json:"status"`
 }
 
+// BusLinePayload bus stops and path are nil only when include leaves them out,
+// bus lines without any are answered with empty lists.
 type BusLinePayload struct {
-	BusStops  []BusStop   `json:"busStops"`
-	FullName  string      `json:"fullName"`
-	ID        string      `json:"id"`
-	Origin    string      `json:"origin"`
-	Path      [][]float64 `json:"path"`
-	ShortName string      `json:"shortName"`
+	BusStops  *[]BusStop   `json:"busStops,omitempty"`
+	FullName  string       `json:"fullName"`
+	ID        string       `json:"id"`
+	Origin    string       `json:"origin"`
+	Path      *[][]float64 `json:"path,omitempty"`
+	ShortName string       `json:"shortName"`
 }
 
 type BusStop struct {
@@ -43,42 +53,147 @@ type BusStop struct {
 	Name string  `json:"name"`
 }
 
+// busLineOptions select the bus lines and the fields GetBusLines answers
+type busLineOptions struct {
+	// ids is nil to answer every bus line
+	ids          map[string]bool
+	withBusStops bool
+	withPath     bool
+	// tolerance in meters simplifies paths when positive
+	tolerance float64
+}
+
+// queryBusLineOptions reads the comma separated ids and include (busStops, path) parameters, and the path tolerance.
+// Bus stops and paths are included when include is absent.
+func queryBusLineOptions(ctx *gin.Context) (busLineOptions, error) {
+	options := busLineOptions{withBusStops: true, withPath: true}
+	if val := ctx.Query("ids"); val != "" {
+		options.ids = make(map[string]bool)
+		for _, id := range strings.Split(val, ",") {
+			if id = strings.TrimSpace(id); id != "" {
+				options.ids[id] = true
+			}
+		}
+	}
+	if val, ok := ctx.GetQuery("include"); ok {
+		options.withBusStops, options.withPath = false, false
+		for _, field := range strings.Split(val, ",") {
+			switch strings.TrimSpace(field) {
+			case "":
+			case "busStops":
+				options.withBusStops = true
+			case "path":
+				options.withPath = true
+			default:
+				return busLineOptions{}, apperror.InvalidInput("invalid include: %s", field)
+			}
+		}
+	}
+	if val := ctx.Query("tolerance"); val != "" {
+		tolerance, err := strconv.ParseFloat(val, 64)
+		if err != nil || tolerance < 0 || tolerance > maxPathTolerance {
+			return busLineOptions{}, apperror.InvalidInput("invalid tolerance: %s", val)
+		}
+		options.tolerance = tolerance
+	}
+	return options, nil
+}
+
+// apply keeps the selected bus lines, without the fields not included
+func (options busLineOptions) apply(busLineBusStops []aggregate.BusLineBusStop) []aggregate.BusLineBusStop {
+	selected := make([]aggregate.BusLineBusStop, 0, len(busLineBusStops))
+	for _, val := range busLineBusStops {
+		if options.ids != nil && !options.ids[val.BusLine.ID] {
+			continue
+		}
+		if !options.withBusStops {
+			val.BusStops = nil
+		}
+		switch {
+		case !options.withPath:
+			val.BusLine.BusLinePaths = nil
+		case options.tolerance > 0:
+			val.BusLine.BusLinePaths = simplifyBusLinePath(val.BusLine.BusLinePaths, options.tolerance)
+		}
+		selected = append(selected, val)
+	}
+	return selected
+}
+
+func simplifyBusLinePath(busLinePaths []entity.BusLinePath, tolerance float64) []entity.BusLinePath {
+	path := make([]location.Location, 0, len(busLinePaths))
+	for _, val := range busLinePaths {
+		path = append(path, location.Location{Lat: val.Lat, Lng: val.Lng})
+	}
+
+	simplified := make([]entity.BusLinePath, 0, len(path))
+	for _, val := range location.Simplify(path, tolerance) {
+		simplified = append(simplified, entity.BusLinePath{Lat: val.Lat, Lng: val.Lng})
+	}
+	return simplified
+}
+
+// GetBusLines answers the bus lines selected by the ids parameter, see queryBusLineOptions,
+// in the json, geojson or polyline format.
 func (port *BusLinePort) GetBusLines(ctx *gin.Context) {
+	options, err := queryBusLineOptions(ctx)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	format, err := queryFormat(ctx)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
 	busLines, err := port.BusLineService.GetBusLines(ctx)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		ctx.Error(err)
 		return
 	}
-
-	resp := transformBusLinesResponse(busLines)
-
-	ctx.JSON(http.StatusOK, resp)
+	busLines = options.apply(busLines)
+
+	switch format {
+	case formatGeoJSON:
+		writeGeoJSON(ctx, busLinesFeatureCollection(busLines))
+	case formatPolyline:
+		ctx.JSON(http.StatusOK, GetBusLinePolylineResponse{
+			Payload: transformBusLinesPolyline(busLines),
+			Status:  statusSuccess,
+		})
+	default:
+		ctx.JSON(http.StatusOK, transformBusLinesResponse(busLines, options))
+	}
 }
 
-func transformBusLinesResponse(busLineBusStops []aggregate.BusLineBusStop) GetBusLineResponse {
+// transformBusLinesResponse answers the fields options include
+func transformBusLinesResponse(busLineBusStops []aggregate.BusLineBusStop, options busLineOptions) GetBusLineResponse {
 	busLinePayloads := make([]BusLinePayload, 0)
 	for _, val := range busLineBusStops {
-		busStops := make([]BusStop, 0, len(val.BusStops))
-		for _, busStop := range val.BusStops {
-			busStops = append(busStops, BusStop{
-				ID:   busStop.ID,
-				Name: busStop.Name,
-				Lat:  busStop.Lat,
-				Lng:  busStop.Lng,
-			})
-		}
-		paths := make([][]float64, 0, len(val.BusLine.BusLinePaths))
-		for _, path := range val.BusLine.BusLinePaths {
-			paths = append(paths, []float64{path.Lat, path.Lng})
-		}
-
 		busLine := BusLinePayload{
-			BusStops:  busStops,
 			FullName:  val.BusLine.FullName,
 			ShortName: val.BusLine.ShortName,
 			Origin:    val.BusLine.Origin,
 			ID:        val.BusLine.ID,
-			Path:      paths,
+		}
+		if options.withBusStops {
+			busStops := make([]BusStop, 0, len(val.BusStops))
+			for _, busStop := range val.BusStops {
+				busStops = append(busStops, BusStop{
+					ID:   busStop.ID,
+					Name: busStop.Name,
+					Lat:  busStop.Lat,
+					Lng:  busStop.Lng,
+				})
+			}
+			busLine.BusStops = &busStops
+		}
+		if options.withPath {
+			paths := make([][]float64, 0, len(val.BusLine.BusLinePaths))
+			for _, path := range val.BusLine.BusLinePaths {
+				paths = append(paths, []float64{path.Lat, path.Lng})
+			}
+			busLine.Path = &paths
 		}
 
 		busLinePayloads = append(busLinePayloads, busLine)
@@ -0,0 +1,64 @@
+package port
+
+import (
+	"encoding/json"
+	"testing"
+
+	"bus-timing/internal/aggregate"
+	"bus-timing/internal/entity"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransformBusLinesResponse(t *testing.T) {
+	t.Parallel()
+
+	busLineBusStops := []aggregate.BusLineBusStop{
+		{
+			BusLine:  entity.BusLine{ID: "44478", FullName: "Campus Loop Red (CL-R)", ShortName: "CL-R", Origin: "Kent Ridge MRT", BusLinePaths: []entity.BusLinePath{{Lat: 1.29, Lng: 103.78}}},
+			BusStops: []entity.BusStop{{ID: "377906", Name: "Opp Kent Ridge MRT", Lat: 1.29, Lng: 103.78}},
+		},
+		{
+			BusLine: entity.BusLine{ID: "44479", FullName: "Campus Loop - Blue (CL-B)", ShortName: "CL-B", Origin: "Kent Ridge MRT"},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		options  busLineOptions
+		expected string
+	}{
+		{
+			name:    "happy case: bus lines without bus stops or path answer empty lists",
+			options: busLineOptions{withBusStops: true, withPath: true},
+			expected: `{"status": 100000, "payload": [
+				{"id": "44478", "fullName": "Campus Loop Red (CL-R)", "shortName": "CL-R", "origin": "Kent Ridge MRT",
+					"busStops": [{"id": "377906", "name": "Opp Kent Ridge MRT", "lat": 1.29, "lng": 103.78}], "path": [[1.29, 103.78]]},
+				{"id": "44479", "fullName": "Campus Loop - Blue (CL-B)", "shortName": "CL-B", "origin": "Kent Ridge MRT",
+					"busStops": [], "path": []}
+			]}`,
+		},
+		{
+			name:    "happy case: include leaves the bus stops out",
+			options: busLineOptions{withPath: true},
+			expected: `{"status": 100000, "payload": [
+				{"id": "44478", "fullName": "Campus Loop Red (CL-R)", "shortName": "CL-R", "origin": "Kent Ridge MRT", "path": [[1.29, 103.78]]},
+				{"id": "44479", "fullName": "Campus Loop - Blue (CL-B)", "shortName": "CL-B", "origin": "Kent Ridge MRT", "path": []}
+			]}`,
+		},
+		{
+			name: "happy case: include leaves both out",
+			expected: `{"status": 100000, "payload": [
+				{"id": "44478", "fullName": "Campus Loop Red (CL-R)", "shortName": "CL-R", "origin": "Kent Ridge MRT"},
+				{"id": "44479", "fullName": "Campus Loop - Blue (CL-B)", "shortName": "CL-B", "origin": "Kent Ridge MRT"}
+			]}`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(tt *testing.T) {
+			body, err := json.Marshal(transformBusLinesResponse(busLineBusStops, test.options))
+			assert.NoError(tt, err)
+			assert.JSONEq(tt, test.expected, string(body))
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package port
+
+import (
+	"net/http"
+
+	"bus-timing/internal/aggregate"
+
+	"github.com/gin-gonic/gin"
+)
+
+// v2 responses have their payload in data and no status, failures are told by the HTTP status
+
+type GetBusLinesV2Response struct {
+	Data []BusLineV2Payload `json:"data"`
+}
+
+// BusLineV2Payload bus stops and path are nil only when include leaves them out, like BusLinePayload
+type BusLineV2Payload struct {
+	ID        string              `json:"id"`
+	FullName  string              `json:"fullName"`
+	ShortName string              `json:"shortName"`
+	Origin    string              `json:"origin"`
+	BusStops  *[]BusStopV2Payload `json:"busStops,omitempty"`
+	Path      *[]LocationV2       `json:"path,omitempty"`
+}
+
+type BusStopV2Payload struct {
+	ID   string  `json:"id"`
+	Name string  `json:"name"`
+	Lat  float64 `json:"lat"`
+	Lng  float64 `json:"lng"`
+}
+
+type LocationV2 struct {
+	Lat float64 `json:"lat"`
+	Lng float64 `json:"lng"`
+}
+
+// GetBusLinesV2 takes the options and formats of GetBusLines.
+func (port *BusLinePort) GetBusLinesV2(ctx *gin.Context) {
+	options, err := queryBusLineOptions(ctx)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	format, err := queryFormat(ctx)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	busLines, err := port.BusLineService.GetBusLines(ctx)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	busLines = options.apply(busLines)
+
+	switch format {
+	case formatGeoJSON:
+		writeGeoJSON(ctx, busLinesFeatureCollection(busLines))
+	case formatPolyline:
+		ctx.JSON(http.StatusOK, GetBusLinesPolylineV2Response{Data: transformBusLinesPolyline(busLines)})
+	default:
+		ctx.JSON(http.StatusOK, transformBusLinesV2Response(busLines, options))
+	}
+}
+
+// transformBusLinesV2Response answers the fields options include
+func transformBusLinesV2Response(busLineBusStops []aggregate.BusLineBusStop, options busLineOptions) GetBusLinesV2Response {
+	payload := make([]BusLineV2Payload, 0, len(busLineBusStops))
+	for _, val := range busLineBusStops {
+		busLine := BusLineV2Payload{
+			ID:        val.BusLine.ID,
+			FullName:  val.BusLine.FullName,
+			ShortName: val.BusLine.ShortName,
+			Origin:    val.BusLine.Origin,
+		}
+		if options.withBusStops {
+			busStops := make([]BusStopV2Payload, 0, len(val.BusStops))
+			for _, busStop := range val.BusStops {
+				busStops = append(busStops, BusStopV2Payload{
+					ID:   busStop.ID,
+					Name: busStop.Name,
+					Lat:  busStop.Lat,
+					Lng:  busStop.Lng,
+				})
+			}
+			busLine.BusStops = &busStops
+		}
+		if options.withPath {
+			path := make([]LocationV2, 0, len(val.BusLine.BusLinePaths))
+			for _, position := range val.BusLine.BusLinePaths {
+				path = append(path, LocationV2{Lat: position.Lat, Lng: position.Lng})
+			}
+			busLine.Path = &path
+		}
+
+		payload = append(payload, busLine)
+	}
+	return GetBusLinesV2Response{Data: payload}
+}
@@ -0,0 +1,87 @@
+package port
+
+import (
+	"testing"
+
+	"bus-timing/internal/aggregate"
+	"bus-timing/internal/entity"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransformBusLinesV2Response(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name            string
+		busLineBusStops []aggregate.BusLineBusStop
+		options         busLineOptions
+		expected        GetBusLinesV2Response
+	}{
+		{
+			name:     "happy case: no bus line",
+			options:  busLineOptions{withBusStops: true, withPath: true},
+			expected: GetBusLinesV2Response{Data: []BusLineV2Payload{}},
+		},
+		{
+			name: "happy case: bus lines with their bus stops and path",
+			busLineBusStops: []aggregate.BusLineBusStop{
+				{
+					BusLine: entity.BusLine{
+						ID:           "44478",
+						FullName:     "Campus Loop Red (CL-R)",
+						ShortName:    "CL-R",
+						Origin:       "Kent Ridge MRT",
+						BusLinePaths: []entity.BusLinePath{{Lat: 1.29, Lng: 103.78}, {Lat: 1.30, Lng: 103.77}},
+					},
+					BusStops: []entity.BusStop{
+						{ID: "377906", Name: "Opp Kent Ridge MRT", Lat: 1.29, Lng: 103.78},
+						{ID: "378224", Name: "LT13", Lat: 1.30, Lng: 103.77},
+					},
+				},
+				{
+					BusLine: entity.BusLine{ID: "44479", FullName: "Campus Loop - Blue (CL-B)", ShortName: "CL-B"},
+				},
+			},
+			options: busLineOptions{withBusStops: true, withPath: true},
+			expected: GetBusLinesV2Response{Data: []BusLineV2Payload{
+				{
+					ID:        "44478",
+					FullName:  "Campus Loop Red (CL-R)",
+					ShortName: "CL-R",
+					Origin:    "Kent Ridge MRT",
+					BusStops: &[]BusStopV2Payload{
+						{ID: "377906", Name: "Opp Kent Ridge MRT", Lat: 1.29, Lng: 103.78},
+						{ID: "378224", Name: "LT13", Lat: 1.30, Lng: 103.77},
+					},
+					Path: &[]LocationV2{{Lat: 1.29, Lng: 103.78}, {Lat: 1.30, Lng: 103.77}},
+				},
+				{
+					ID:        "44479",
+					FullName:  "Campus Loop - Blue (CL-B)",
+					ShortName: "CL-B",
+					BusStops:  &[]BusStopV2Payload{},
+					Path:      &[]LocationV2{},
+				},
+			}},
+		},
+		{
+			name: "happy case: include leaves the path out",
+			busLineBusStops: []aggregate.BusLineBusStop{
+				{
+					BusLine:  entity.BusLine{ID: "44479", ShortName: "CL-B", BusLinePaths: []entity.BusLinePath{{Lat: 1.29, Lng: 103.78}}},
+					BusStops: []entity.BusStop{{ID: "377906", Name: "Opp Kent Ridge MRT"}},
+				},
+			},
+			options: busLineOptions{withBusStops: true},
+			expected: GetBusLinesV2Response{Data: []BusLineV2Payload{
+				{ID: "44479", ShortName: "CL-B", BusStops: &[]BusStopV2Payload{{ID: "377906", Name: "Opp Kent Ridge MRT"}}},
+			}},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(tt *testing.T) {
+			assert.Equal(tt, test.expected, transformBusLinesV2Response(test.busLineBusStops, test.options))
+		})
+	}
+}
@@ -2,9 +2,10 @@ package port
 
 import (
 	"bus-timing/internal/aggregate"
+	"bus-timing/pkg/apperror"
 	"context"
-	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
@@ -25,27 +26,111 @@ type RunningBusPayload struct {
 	VehiclePlate string  `json:"vehiclePlate"`
 }
 
+type GetBusPositionHistoryResponse struct {
+	Payload []BusTrackPayload `json:"payload"`
+	Status  int               `json:"status"`
+}
+
+type BusTrackPayload struct {
+	VehiclePlate string                      `json:"vehiclePlate"`
+	Positions    []BusPositionHistoryPayload `json:"positions"`
+}
+
+type BusPositionHistoryPayload struct {
+	Bearing    float64   `json:"bearing"`
+	CrowdLevel string    `json:"crowdLevel"`
+	Lat        float64   `json:"lat"`
+	Lng        float64   `json:"lng"`
+	Timestamp  time.Time `json:"timestamp"`
+}
+
+// defaultHistoryWindow is used when the history request has no `from`
+const defaultHistoryWindow = time.Hour
+
 type BusPositionPort struct {
 	BusPositionService interface {
 		GetBusPosition(ctx context.Context, busLineID string) ([]aggregate.BusPosition, error)
+		GetBusPositionHistory(ctx context.Context, busLineID string, from, to time.Time) ([]aggregate.BusTrack, error)
 	}
+	// StaleAfter is the age from which v2 flags a bus position as stale
+	StaleAfter time.Duration
 }
 
+// GetBusPosition answers the running buses of a bus line in the json, geojson or polyline format.
 func (port *BusPositionPort) GetBusPosition(ctx *gin.Context) {
 	busLineID := ctx.Param("busLineID")
 	if busLineID == "" {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid bus line: %s", busLineID)})
+		ctx.Error(apperror.InvalidInput("invalid bus line: %s", busLineID))
+		return
+	}
+	format, err := queryFormat(ctx)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	busPositions, err := port.BusPositionService.GetBusPosition(ctx, busLineID)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	switch format {
+	case formatGeoJSON:
+		writeGeoJSON(ctx, busPositionsFeatureCollection(busPositions, port.StaleAfter))
+	case formatPolyline:
+		ctx.JSON(http.StatusOK, GetBusPositionPolylineResponse{
+			Payload: transformBusPositionsPolyline(busPositions),
+			Status:  statusSuccess,
+		})
+	default:
+		ctx.JSON(http.StatusOK, transformBusPositionsResponse(busPositions))
+	}
+}
+
+func (port *BusPositionPort) GetBusPositionHistory(ctx *gin.Context) {
+	busLineID := ctx.Param("busLineID")
+	if busLineID == "" {
+		ctx.Error(apperror.InvalidInput("invalid bus line: %s", busLineID))
 		return
 	}
-	busLines, err := port.BusPositionService.GetBusPosition(ctx, busLineID)
+
+	from, to, err := queryHistoryWindow(ctx)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		ctx.Error(err)
 		return
 	}
 
-	resp := transformBusPositionsResponse(busLines)
+	tracks, err := port.BusPositionService.GetBusPositionHistory(ctx, busLineID, from, to)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
 
-	ctx.JSON(http.StatusOK, resp)
+	ctx.JSON(http.StatusOK, transformBusTracksResponse(tracks))
+}
+
+// queryHistoryWindow reads the RFC 3339 from and to parameters, to defaults to now and from to an hour before to
+func queryHistoryWindow(ctx *gin.Context) (time.Time, time.Time, error) {
+	to := time.Now()
+	if val := ctx.Query("to"); val != "" {
+		parsed, err := time.Parse(time.RFC3339, val)
+		if err != nil {
+			return time.Time{}, time.Time{}, apperror.InvalidInput("invalid to: %s", val)
+		}
+		to = parsed
+	}
+	from := to.Add(-defaultHistoryWindow)
+	if val := ctx.Query("from"); val != "" {
+		parsed, err := time.Parse(time.RFC3339, val)
+		if err != nil {
+			return time.Time{}, time.Time{}, apperror.InvalidInput("invalid from: %s", val)
+		}
+		from = parsed
+	}
+	if from.After(to) {
+		return time.Time{}, time.Time{}, apperror.InvalidInput("from must be before to")
+	}
+	return from, to, nil
 }
 
 func transformBusPositionsResponse(runningBuses []aggregate.BusPosition) GetBusPositionResponse {
@@ -64,3 +149,27 @@ func transformBusPositionsResponse(runningBuses []aggregate.BusPosition) GetBusP
 		Status:  statusSuccess,
 	}
 }
+
+func transformBusTracksResponse(tracks []aggregate.BusTrack) GetBusPositionHistoryResponse {
+	payload := make([]BusTrackPayload, 0, len(tracks))
+	for _, track := range tracks {
+		positions := make([]BusPositionHistoryPayload, 0, len(track.Positions))
+		for _, val := range track.Positions {
+			positions = append(positions, BusPositionHistoryPayload{
+				Bearing:    val.Bus.Bearing,
+				CrowdLevel: string(val.RunningBusPosition.CrowdLevel),
+				Lat:        val.RunningBusPosition.Lat,
+				Lng:        val.RunningBusPosition.Lng,
+				Timestamp:  val.RecordedAt,
+			})
+		}
+		payload = append(payload, BusTrackPayload{
+			VehiclePlate: track.VehiclePlate,
+			Positions:    positions,
+		})
+	}
+	return GetBusPositionHistoryResponse{
+		Payload: payload,
+		Status:  statusSuccess,
+	}
+}
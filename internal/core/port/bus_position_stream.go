@@ -0,0 +1,123 @@
+package port
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"bus-timing/internal/aggregate"
+	"bus-timing/pkg/apperror"
+
+	"github.com/gin-gonic/gin"
+)
+
+const defaultHeartbeatInterval = 15 * time.Second
+
+type BusPositionStreamPort struct {
+	BusLineCatalogue interface {
+		GetBusLine(ctx context.Context, busLineID string) (aggregate.BusLineBusStop, error)
+	}
+	BusPositionService interface {
+		GetBusPosition(ctx context.Context, busLineID string) ([]aggregate.BusPosition, error)
+	}
+	BusPositionBroadcaster interface {
+		Publish(busLineID string, busPositions []aggregate.BusPosition)
+		Subscribe(busLineID string, lastEventID uint64) ([]aggregate.BusPositionEvent, <-chan aggregate.BusPositionEvent, func())
+	}
+	HeartbeatInterval time.Duration
+}
+
+// StreamBusPosition pushes the running buses of a bus line as Server-Sent Events every time they move.
+// A client reconnecting with the Last-Event-ID header gets the events it missed.
+func (port *BusPositionStreamPort) StreamBusPosition(ctx *gin.Context) {
+	busLineID := ctx.Param("busLineID")
+	if busLineID == "" {
+		ctx.Error(apperror.InvalidInput("invalid bus line: %s", busLineID))
+		return
+	}
+
+	lastEventID := uint64(0)
+	if val := ctx.GetHeader("Last-Event-ID"); val != "" {
+		parsed, err := strconv.ParseUint(val, 10, 64)
+		if err != nil {
+			ctx.Error(apperror.InvalidInput("invalid Last-Event-ID: %s", val))
+			return
+		}
+		lastEventID = parsed
+	}
+
+	// unknown bus lines would get a feed of their own
+	if _, err := port.BusLineCatalogue.GetBusLine(ctx, busLineID); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	replay, events, cancel := port.BusPositionBroadcaster.Subscribe(busLineID, lastEventID)
+	defer cancel()
+
+	if len(replay) == 0 {
+		// nothing polled for this bus line yet or the client is up to date,
+		// publish the current positions so a change is sent as the first event
+		busPositions, err := port.BusPositionService.GetBusPosition(ctx, busLineID)
+		if err != nil {
+			ctx.Error(err)
+			return
+		}
+		port.BusPositionBroadcaster.Publish(busLineID, busPositions)
+	}
+
+	// the stream outlives the server write timeout
+	if err := http.NewResponseController(ctx.Writer).SetWriteDeadline(time.Time{}); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	header := ctx.Writer.Header()
+	header.Set("Content-Type", "text/event-stream")
+	header.Set("Cache-Control", "no-cache")
+	header.Set("Connection", "keep-alive")
+	header.Set("X-Accel-Buffering", "no")
+	ctx.Status(http.StatusOK)
+
+	for _, event := range replay {
+		if err := writeBusPositionEvent(ctx, event); err != nil {
+			return
+		}
+	}
+	ctx.Writer.Flush()
+
+	heartbeatInterval := port.HeartbeatInterval
+	if heartbeatInterval <= 0 {
+		heartbeatInterval = defaultHeartbeatInterval
+	}
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-ctx.Request.Context().Done():
+			return
+		case event := <-events:
+			if err := writeBusPositionEvent(ctx, event); err != nil {
+				return
+			}
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(ctx.Writer, ": heartbeat\n\n"); err != nil {
+				return
+			}
+		}
+		ctx.Writer.Flush()
+	}
+}
+
+func writeBusPositionEvent(ctx *gin.Context, event aggregate.BusPositionEvent) error {
+	data, err := json.Marshal(transformBusPositionsResponse(event.BusPositions))
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(ctx.Writer, "id: %d\nevent: busPosition\ndata: %s\n\n", event.ID, data)
+	return err
+}
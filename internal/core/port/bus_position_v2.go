@@ -0,0 +1,141 @@
+package port
+
+import (
+	"net/http"
+	"time"
+
+	"bus-timing/internal/aggregate"
+	"bus-timing/pkg/apperror"
+
+	"github.com/gin-gonic/gin"
+)
+
+// defaultStaleAfter is used when the port has no StaleAfter
+const defaultStaleAfter = 30 * time.Second
+
+type GetBusPositionsV2Response struct {
+	Data []BusPositionV2Payload `json:"data"`
+}
+
+type BusPositionV2Payload struct {
+	VehiclePlate string    `json:"vehiclePlate"`
+	Bearing      float64   `json:"bearing"`
+	CrowdLevel   string    `json:"crowdLevel"`
+	Lat          float64   `json:"lat"`
+	Lng          float64   `json:"lng"`
+	UpdatedAt    time.Time `json:"updatedAt"`
+	// Stale tells the position was fetched too long ago to be trusted
+	Stale bool `json:"stale"`
+}
+
+type GetBusPositionHistoryV2Response struct {
+	Data []BusTrackV2Payload `json:"data"`
+}
+
+type BusTrackV2Payload struct {
+	VehiclePlate string                       `json:"vehiclePlate"`
+	Positions    []BusPositionRecordV2Payload `json:"positions"`
+}
+
+type BusPositionRecordV2Payload struct {
+	Bearing    float64   `json:"bearing"`
+	CrowdLevel string    `json:"crowdLevel"`
+	Lat        float64   `json:"lat"`
+	Lng        float64   `json:"lng"`
+	RecordedAt time.Time `json:"recordedAt"`
+}
+
+// GetBusPositionV2 takes the formats of GetBusPosition.
+func (port *BusPositionPort) GetBusPositionV2(ctx *gin.Context) {
+	busLineID := ctx.Param("busLineID")
+	if busLineID == "" {
+		ctx.Error(apperror.InvalidInput("invalid bus line: %s", busLineID))
+		return
+	}
+	format, err := queryFormat(ctx)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	busPositions, err := port.BusPositionService.GetBusPosition(ctx, busLineID)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	switch format {
+	case formatGeoJSON:
+		writeGeoJSON(ctx, busPositionsFeatureCollection(busPositions, port.StaleAfter))
+		return
+	case formatPolyline:
+		ctx.JSON(http.StatusOK, GetBusPositionsPolylineV2Response{Data: transformBusPositionsPolyline(busPositions)})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, transformBusPositionsV2Response(busPositions, port.StaleAfter))
+}
+
+func (port *BusPositionPort) GetBusPositionHistoryV2(ctx *gin.Context) {
+	busLineID := ctx.Param("busLineID")
+	if busLineID == "" {
+		ctx.Error(apperror.InvalidInput("invalid bus line: %s", busLineID))
+		return
+	}
+	from, to, err := queryHistoryWindow(ctx)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	tracks, err := port.BusPositionService.GetBusPositionHistory(ctx, busLineID, from, to)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, transformBusPositionHistoryV2Response(tracks))
+}
+
+func transformBusPositionsV2Response(busPositions []aggregate.BusPosition, staleAfter time.Duration) GetBusPositionsV2Response {
+	payload := make([]BusPositionV2Payload, 0, len(busPositions))
+	for _, val := range busPositions {
+		payload = append(payload, BusPositionV2Payload{
+			VehiclePlate: val.Bus.VehiclePlate,
+			Bearing:      val.Bus.Bearing,
+			CrowdLevel:   string(val.RunningBusPosition.CrowdLevel),
+			Lat:          val.RunningBusPosition.Lat,
+			Lng:          val.RunningBusPosition.Lng,
+			UpdatedAt:    val.UpdatedAt,
+			Stale:        isStale(val.UpdatedAt, staleAfter),
+		})
+	}
+	return GetBusPositionsV2Response{Data: payload}
+}
+
+func transformBusPositionHistoryV2Response(tracks []aggregate.BusTrack) GetBusPositionHistoryV2Response {
+	payload := make([]BusTrackV2Payload, 0, len(tracks))
+	for _, track := range tracks {
+		positions := make([]BusPositionRecordV2Payload, 0, len(track.Positions))
+		for _, val := range track.Positions {
+			positions = append(positions, BusPositionRecordV2Payload{
+				Bearing:    val.Bus.Bearing,
+				CrowdLevel: string(val.RunningBusPosition.CrowdLevel),
+				Lat:        val.RunningBusPosition.Lat,
+				Lng:        val.RunningBusPosition.Lng,
+				RecordedAt: val.RecordedAt,
+			})
+		}
+		payload = append(payload, BusTrackV2Payload{
+			VehiclePlate: track.VehiclePlate,
+			Positions:    positions,
+		})
+	}
+	return GetBusPositionHistoryV2Response{Data: payload}
+}
+
+// isStale tells whether a position fetched at updatedAt is older than staleAfter
+func isStale(updatedAt time.Time, staleAfter time.Duration) bool {
+	if staleAfter <= 0 {
+		staleAfter = defaultStaleAfter
+	}
+	return time.Since(updatedAt) > staleAfter
+}
@@ -0,0 +1,120 @@
+package port
+
+import (
+	"testing"
+	"time"
+
+	"bus-timing/internal/aggregate"
+	"bus-timing/internal/entity"
+	"bus-timing/pkg/common"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransformBusPositionsV2Response(t *testing.T) {
+	t.Parallel()
+
+	fresh := time.Now()
+	old := fresh.Add(-time.Minute)
+
+	tests := []struct {
+		name         string
+		busPositions []aggregate.BusPosition
+		staleAfter   time.Duration
+		expected     GetBusPositionsV2Response
+	}{
+		{
+			name:     "happy case: no running bus",
+			expected: GetBusPositionsV2Response{Data: []BusPositionV2Payload{}},
+		},
+		{
+			name: "happy case: positions with their staleness",
+			busPositions: []aggregate.BusPosition{
+				{
+					Bus:                entity.Bus{VehiclePlate: "PD1064Z", Bearing: 159.4},
+					RunningBusPosition: entity.RunningBusPosition{Lat: 1.29, Lng: 103.78, CrowdLevel: common.LowCrowd},
+					UpdatedAt:          fresh,
+				},
+				{
+					Bus:                entity.Bus{VehiclePlate: "PD698B", Bearing: 12.5},
+					RunningBusPosition: entity.RunningBusPosition{Lat: 1.30, Lng: 103.77, CrowdLevel: common.HighCrowd},
+					UpdatedAt:          old,
+				},
+			},
+			staleAfter: 10 * time.Second,
+			expected: GetBusPositionsV2Response{Data: []BusPositionV2Payload{
+				{VehiclePlate: "PD1064Z", Bearing: 159.4, CrowdLevel: "low", Lat: 1.29, Lng: 103.78, UpdatedAt: fresh},
+				{VehiclePlate: "PD698B", Bearing: 12.5, CrowdLevel: "high", Lat: 1.30, Lng: 103.77, UpdatedAt: old, Stale: true},
+			}},
+		},
+		{
+			name: "happy case: default staleAfter without one",
+			busPositions: []aggregate.BusPosition{
+				{Bus: entity.Bus{VehiclePlate: "PD1064Z"}, UpdatedAt: old},
+			},
+			expected: GetBusPositionsV2Response{Data: []BusPositionV2Payload{
+				{VehiclePlate: "PD1064Z", UpdatedAt: old, Stale: true},
+			}},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(tt *testing.T) {
+			assert.Equal(tt, test.expected, transformBusPositionsV2Response(test.busPositions, test.staleAfter))
+		})
+	}
+}
+
+func TestTransformBusPositionHistoryV2Response(t *testing.T) {
+	t.Parallel()
+
+	recordedAt := time.Date(2024, 3, 1, 8, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		tracks   []aggregate.BusTrack
+		expected GetBusPositionHistoryV2Response
+	}{
+		{
+			name:     "happy case: no history",
+			expected: GetBusPositionHistoryV2Response{Data: []BusTrackV2Payload{}},
+		},
+		{
+			name: "happy case: tracks of each vehicle",
+			tracks: []aggregate.BusTrack{
+				{
+					VehiclePlate: "PD1064Z",
+					Positions: []aggregate.BusPositionRecord{
+						{
+							BusLineID:          "44480",
+							Bus:                entity.Bus{VehiclePlate: "PD1064Z", Bearing: 159.4},
+							RunningBusPosition: entity.RunningBusPosition{Lat: 1.29, Lng: 103.78, CrowdLevel: common.LowCrowd},
+							RecordedAt:         recordedAt,
+						},
+						{
+							BusLineID:          "44480",
+							Bus:                entity.Bus{VehiclePlate: "PD1064Z", Bearing: 160},
+							RunningBusPosition: entity.RunningBusPosition{Lat: 1.28, Lng: 103.79, CrowdLevel: common.MediumCrowd},
+							RecordedAt:         recordedAt.Add(time.Minute),
+						},
+					},
+				},
+				{VehiclePlate: "PD698B"},
+			},
+			expected: GetBusPositionHistoryV2Response{Data: []BusTrackV2Payload{
+				{
+					VehiclePlate: "PD1064Z",
+					Positions: []BusPositionRecordV2Payload{
+						{Bearing: 159.4, CrowdLevel: "low", Lat: 1.29, Lng: 103.78, RecordedAt: recordedAt},
+						{Bearing: 160, CrowdLevel: "medium", Lat: 1.28, Lng: 103.79, RecordedAt: recordedAt.Add(time.Minute)},
+					},
+				},
+				{VehiclePlate: "PD698B", Positions: []BusPositionRecordV2Payload{}},
+			}},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(tt *testing.T) {
+			assert.Equal(tt, test.expected, transformBusPositionHistoryV2Response(test.tracks))
+		})
+	}
+}
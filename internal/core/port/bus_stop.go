@@ -0,0 +1,218 @@
+package port
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strconv"
+
+	"bus-timing/internal/aggregate"
+	"bus-timing/pkg/apperror"
+	"bus-timing/pkg/location"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultBusStopLimit = 50
+	maxBusStopLimit     = 500
+
+	defaultNearbyRadius = 500.0
+	maxNearbyRadius     = 5000.0
+	defaultNearbyLimit  = 10
+)
+
+type BusStopPort struct {
+	BusStopService interface {
+		GetBusStops(ctx context.Context, offset, limit int) ([]aggregate.BusStopBusLines, int, error)
+		GetBusStop(ctx context.Context, busStopID string) (aggregate.BusStopBusLines, error)
+		GetNearbyBusStops(ctx context.Context, center location.Location, radius float64, limit int) ([]aggregate.NearbyBusStop, error)
+	}
+}
+
+type GetBusStopsResponse struct {
+	Payload    []BusStopPayload `json:"payload"`
+	Pagination Pagination       `json:"pagination"`
+	Status     int              `json:"status"`
+}
+
+type GetBusStopResponse struct {
+	Payload BusStopPayload `json:"payload"`
+	Status  int            `json:"status"`
+}
+
+type GetNearbyBusStopsResponse struct {
+	Payload []NearbyBusStopPayload `json:"payload"`
+	Status  int                    `json:"status"`
+}
+
+type NearbyBusStopPayload struct {
+	BusStopPayload
+	Distance float64 `json:"distance"`
+}
+
+type Pagination struct {
+	Offset int `json:"offset"`
+	Limit  int `json:"limit"`
+	Total  int `json:"total"`
+}
+
+type BusStopPayload struct {
+	ID       string                  `json:"id"`
+	Lat      float64                 `json:"lat"`
+	Lng      float64                 `json:"lng"`
+	Name     string                  `json:"name"`
+	BusLines []BusStopBusLinePayload `json:"busLines,omitempty"`
+}
+
+type BusStopBusLinePayload struct {
+	ID        string `json:"id"`
+	FullName  string `json:"fullName"`
+	ShortName string `json:"shortName"`
+	Origin    string `json:"origin"`
+	StopOrder int    `json:"stopOrder"`
+}
+
+func (port *BusStopPort) GetBusStops(ctx *gin.Context) {
+	offset, err := queryInt(ctx, "offset", 0)
+	if err != nil || offset < 0 {
+		ctx.Error(apperror.InvalidInput("invalid offset: %s", ctx.Query("offset")))
+		return
+	}
+	limit, err := queryInt(ctx, "limit", defaultBusStopLimit)
+	if err != nil || limit <= 0 || limit > maxBusStopLimit {
+		ctx.Error(apperror.InvalidInput("invalid limit: %s", ctx.Query("limit")))
+		return
+	}
+
+	busStops, total, err := port.BusStopService.GetBusStops(ctx, offset, limit)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	payload := make([]BusStopPayload, 0, len(busStops))
+	for _, val := range busStops {
+		payload = append(payload, toBusStopPayload(val, false))
+	}
+
+	ctx.JSON(http.StatusOK, GetBusStopsResponse{
+		Payload: payload,
+		Pagination: Pagination{
+			Offset: offset,
+			Limit:  limit,
+			Total:  total,
+		},
+		Status: statusSuccess,
+	})
+}
+
+func (port *BusStopPort) GetBusStop(ctx *gin.Context) {
+	busStopID := ctx.Param("busStopID")
+	if busStopID == "" {
+		ctx.Error(apperror.InvalidInput("invalid bus stop: %s", busStopID))
+		return
+	}
+
+	busStop, err := port.BusStopService.GetBusStop(ctx, busStopID)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, GetBusStopResponse{
+		Payload: toBusStopPayload(busStop, true),
+		Status:  statusSuccess,
+	})
+}
+
+func (port *BusStopPort) GetNearbyBusStops(ctx *gin.Context) {
+	center, err := parseLatLng(ctx, "lat", "lng")
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	radius := defaultNearbyRadius
+	if val := ctx.Query("radius"); val != "" {
+		radius, err = strconv.ParseFloat(val, 64)
+		if err != nil || !isFinite(radius) || radius <= 0 || radius > maxNearbyRadius {
+			ctx.Error(apperror.InvalidInput("invalid radius: %s", val))
+			return
+		}
+	}
+	limit, err := queryInt(ctx, "limit", defaultNearbyLimit)
+	if err != nil || limit <= 0 || limit > maxBusStopLimit {
+		ctx.Error(apperror.InvalidInput("invalid limit: %s", ctx.Query("limit")))
+		return
+	}
+
+	busStops, err := port.BusStopService.GetNearbyBusStops(ctx, center, radius, limit)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	payload := make([]NearbyBusStopPayload, 0, len(busStops))
+	for _, val := range busStops {
+		payload = append(payload, NearbyBusStopPayload{
+			BusStopPayload: toBusStopPayload(val.BusStop, true),
+			Distance:       val.Distance,
+		})
+	}
+
+	ctx.JSON(http.StatusOK, GetNearbyBusStopsResponse{
+		Payload: payload,
+		Status:  statusSuccess,
+	})
+}
+
+func toBusStopPayload(busStop aggregate.BusStopBusLines, withBusLines bool) BusStopPayload {
+	payload := BusStopPayload{
+		ID:   busStop.BusStop.ID,
+		Name: busStop.BusStop.Name,
+		Lat:  busStop.BusStop.Lat,
+		Lng:  busStop.BusStop.Lng,
+	}
+	if !withBusLines {
+		return payload
+	}
+
+	payload.BusLines = make([]BusStopBusLinePayload, 0, len(busStop.BusLines))
+	for _, val := range busStop.BusLines {
+		payload.BusLines = append(payload.BusLines, BusStopBusLinePayload{
+			ID:        val.BusLine.ID,
+			FullName:  val.BusLine.FullName,
+			ShortName: val.BusLine.ShortName,
+			Origin:    val.BusLine.Origin,
+			StopOrder: val.StopOrder,
+		})
+	}
+	return payload
+}
+
+// queryInt reads an integer query parameter, returning def when it is absent
+func queryInt(ctx *gin.Context, key string, def int) (int, error) {
+	val := ctx.Query(key)
+	if val == "" {
+		return def, nil
+	}
+	return strconv.Atoi(val)
+}
+
+// parseLatLng reads a coordinate from the latKey and lngKey parameters,
+// NaN and infinities are refused like the values out of range
+func parseLatLng(ctx *gin.Context, latKey, lngKey string) (location.Location, error) {
+	lat, err := strconv.ParseFloat(ctx.Query(latKey), 64)
+	if err != nil || !isFinite(lat) || lat < -90 || lat > 90 {
+		return location.Location{}, apperror.InvalidInput("invalid %s: %s", latKey, ctx.Query(latKey))
+	}
+	lng, err := strconv.ParseFloat(ctx.Query(lngKey), 64)
+	if err != nil || !isFinite(lng) || lng < -180 || lng > 180 {
+		return location.Location{}, apperror.InvalidInput("invalid %s: %s", lngKey, ctx.Query(lngKey))
+	}
+	return location.Location{Lat: lat, Lng: lng}, nil
+}
+
+func isFinite(val float64) bool {
+	return !math.IsNaN(val) && !math.IsInf(val, 0)
+}
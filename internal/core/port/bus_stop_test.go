@@ -0,0 +1,73 @@
+package port
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"bus-timing/internal/aggregate"
+	"bus-timing/internal/entity"
+	"bus-timing/pkg/location"
+	"bus-timing/pkg/middlewares/errorhandler"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockBusStopService struct {
+	nearbyCalls int
+}
+
+func (m *mockBusStopService) GetBusStops(ctx context.Context, offset, limit int) ([]aggregate.BusStopBusLines, int, error) {
+	return nil, 0, nil
+}
+
+func (m *mockBusStopService) GetBusStop(ctx context.Context, busStopID string) (aggregate.BusStopBusLines, error) {
+	return aggregate.BusStopBusLines{}, nil
+}
+
+func (m *mockBusStopService) GetNearbyBusStops(ctx context.Context, center location.Location, radius float64, limit int) ([]aggregate.NearbyBusStop, error) {
+	m.nearbyCalls++
+	return []aggregate.NearbyBusStop{{BusStop: aggregate.BusStopBusLines{BusStop: entity.BusStop{ID: "377906"}}, Distance: 120}}, nil
+}
+
+func TestBusStopPort_GetNearbyBusStops(t *testing.T) {
+	t.Parallel()
+
+	gin.SetMode(gin.TestMode)
+	serve := func(service *mockBusStopService, query string) *httptest.ResponseRecorder {
+		port := &BusStopPort{BusStopService: service}
+		router := gin.New()
+		router.Use(errorhandler.ErrorHandlerMiddleware())
+		router.GET("/api/bus-stops/nearby", port.GetNearbyBusStops)
+
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/api/bus-stops/nearby?"+query, nil))
+		return recorder
+	}
+
+	t.Run("happy case", func(tt *testing.T) {
+		service := &mockBusStopService{}
+		recorder := serve(service, "lat=1.29&lng=103.78&radius=800")
+		assert.Equal(tt, http.StatusOK, recorder.Code)
+		assert.Equal(tt, 1, service.nearbyCalls)
+	})
+
+	t.Run("bad case: invalid coordinates and radius", func(tt *testing.T) {
+		for _, query := range []string{
+			"lat=NaN&lng=103.78",
+			"lat=1.29&lng=NaN",
+			"lat=1.29&lng=103.78&radius=NaN",
+			"lat=Inf&lng=103.78",
+			"lat=1.29&lng=-Inf",
+			"lat=91&lng=103.78",
+			"lat=1.29",
+		} {
+			service := &mockBusStopService{}
+			recorder := serve(service, query)
+			assert.Equal(tt, http.StatusBadRequest, recorder.Code, query)
+			assert.Zero(tt, service.nearbyCalls, query)
+		}
+	})
+}
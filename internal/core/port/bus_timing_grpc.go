@@ -0,0 +1,208 @@
+package port
+
+import (
+	"context"
+
+	bustimingv1 "bus-timing/api/bustiming/v1"
+	"bus-timing/internal/aggregate"
+	"bus-timing/pkg/apperror"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// BusTimingGRPCServer serves the bustiming.v1.BusTiming gRPC service on the services behind the HTTP routes.
+type BusTimingGRPCServer struct {
+	bustimingv1.UnimplementedBusTimingServer
+
+	BusLineService interface {
+		GetBusLines(ctx context.Context) ([]aggregate.BusLineBusStop, error)
+	}
+	BusLineCatalogue interface {
+		GetBusLine(ctx context.Context, busLineID string) (aggregate.BusLineBusStop, error)
+	}
+	BusPositionService interface {
+		GetBusPosition(ctx context.Context, busLineID string) ([]aggregate.BusPosition, error)
+	}
+	RunningBusService interface {
+		EstimatedArrivalTime(ctx context.Context, busStopID string) ([]aggregate.IncomingBus, error)
+	}
+	BusPositionBroadcaster interface {
+		Publish(busLineID string, busPositions []aggregate.BusPosition)
+		Subscribe(busLineID string, lastEventID uint64) ([]aggregate.BusPositionEvent, <-chan aggregate.BusPositionEvent, func())
+	}
+}
+
+func (server *BusTimingGRPCServer) GetBusLines(ctx context.Context, req *bustimingv1.GetBusLinesRequest) (*bustimingv1.GetBusLinesResponse, error) {
+	busLines, err := server.BusLineService.GetBusLines(ctx)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	resp := &bustimingv1.GetBusLinesResponse{
+		BusLines: make([]*bustimingv1.BusLine, 0, len(busLines)),
+	}
+	for _, val := range busLines {
+		resp.BusLines = append(resp.BusLines, toBusLineMessage(val, req.GetIncludePaths()))
+	}
+	return resp, nil
+}
+
+func (server *BusTimingGRPCServer) GetBusPositions(ctx context.Context, req *bustimingv1.GetBusPositionsRequest) (*bustimingv1.GetBusPositionsResponse, error) {
+	if req.GetBusLineId() == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid bus line: %s", req.GetBusLineId())
+	}
+
+	busPositions, err := server.BusPositionService.GetBusPosition(ctx, req.GetBusLineId())
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return &bustimingv1.GetBusPositionsResponse{
+		BusPositions: toBusPositionMessages(busPositions),
+	}, nil
+}
+
+func (server *BusTimingGRPCServer) GetStopArrivals(ctx context.Context, req *bustimingv1.GetStopArrivalsRequest) (*bustimingv1.GetStopArrivalsResponse, error) {
+	if req.GetBusStopId() == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid bus stop: %s", req.GetBusStopId())
+	}
+
+	incomingBuses, err := server.RunningBusService.EstimatedArrivalTime(ctx, req.GetBusStopId())
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	resp := &bustimingv1.GetStopArrivalsResponse{
+		Arrivals: make([]*bustimingv1.Arrival, 0, len(incomingBuses)),
+	}
+	for _, val := range incomingBuses {
+		resp.Arrivals = append(resp.Arrivals, &bustimingv1.Arrival{
+			BusLineId:    val.BusLine.ID,
+			FullName:     val.BusLine.FullName,
+			ShortName:    val.BusLine.ShortName,
+			Origin:       val.BusLine.Origin,
+			VehiclePlate: val.Bus.VehiclePlate,
+			Lat:          val.BusPosition.Lat,
+			Lng:          val.BusPosition.Lng,
+			Eta:          durationpb.New(val.ArrivalTime),
+			Distance:     val.Distance,
+		})
+	}
+	return resp, nil
+}
+
+// WatchBusPositions sends the running buses of a bus line every time the poller sees them move,
+// like the Server-Sent Events stream of the HTTP API.
+func (server *BusTimingGRPCServer) WatchBusPositions(req *bustimingv1.WatchBusPositionsRequest, stream bustimingv1.BusTiming_WatchBusPositionsServer) error {
+	busLineID := req.GetBusLineId()
+	if busLineID == "" {
+		return status.Errorf(codes.InvalidArgument, "invalid bus line: %s", busLineID)
+	}
+	ctx := stream.Context()
+
+	// unknown bus lines would get a feed of their own
+	if _, err := server.BusLineCatalogue.GetBusLine(ctx, busLineID); err != nil {
+		return grpcError(err)
+	}
+
+	replay, events, cancel := server.BusPositionBroadcaster.Subscribe(busLineID, req.GetLastEventId())
+	defer cancel()
+
+	if len(replay) == 0 {
+		// nothing polled for this bus line yet or the client is up to date,
+		// publish the current positions so a change is sent as the first update
+		busPositions, err := server.BusPositionService.GetBusPosition(ctx, busLineID)
+		if err != nil {
+			return grpcError(err)
+		}
+		server.BusPositionBroadcaster.Publish(busLineID, busPositions)
+	}
+
+	for _, event := range replay {
+		if err := stream.Send(toBusPositionsUpdate(event)); err != nil {
+			return err
+		}
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event := <-events:
+			if err := stream.Send(toBusPositionsUpdate(event)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func toBusLineMessage(busLine aggregate.BusLineBusStop, withPath bool) *bustimingv1.BusLine {
+	msg := &bustimingv1.BusLine{
+		Id:        busLine.BusLine.ID,
+		FullName:  busLine.BusLine.FullName,
+		ShortName: busLine.BusLine.ShortName,
+		Origin:    busLine.BusLine.Origin,
+		BusStops:  make([]*bustimingv1.BusStop, 0, len(busLine.BusStops)),
+	}
+	for _, val := range busLine.BusStops {
+		msg.BusStops = append(msg.BusStops, &bustimingv1.BusStop{
+			Id:   val.ID,
+			Name: val.Name,
+			Lat:  val.Lat,
+			Lng:  val.Lng,
+		})
+	}
+	if !withPath {
+		return msg
+	}
+
+	msg.Path = make([]*bustimingv1.LatLng, 0, len(busLine.BusLine.BusLinePaths))
+	for _, val := range busLine.BusLine.BusLinePaths {
+		msg.Path = append(msg.Path, &bustimingv1.LatLng{
+			Lat: val.Lat,
+			Lng: val.Lng,
+		})
+	}
+	return msg
+}
+
+func toBusPositionMessages(busPositions []aggregate.BusPosition) []*bustimingv1.BusPosition {
+	msgs := make([]*bustimingv1.BusPosition, 0, len(busPositions))
+	for _, val := range busPositions {
+		msgs = append(msgs, &bustimingv1.BusPosition{
+			VehiclePlate: val.Bus.VehiclePlate,
+			Bearing:      val.Bus.Bearing,
+			CrowdLevel:   string(val.RunningBusPosition.CrowdLevel),
+			Lat:          val.RunningBusPosition.Lat,
+			Lng:          val.RunningBusPosition.Lng,
+		})
+	}
+	return msgs
+}
+
+func toBusPositionsUpdate(event aggregate.BusPositionEvent) *bustimingv1.BusPositionsUpdate {
+	return &bustimingv1.BusPositionsUpdate{
+		EventId:      event.ID,
+		BusLineId:    event.BusLineID,
+		BusPositions: toBusPositionMessages(event.BusPositions),
+		PublishedAt:  timestamppb.New(event.PublishedAt),
+	}
+}
+
+var grpcCodeByKind = map[apperror.Kind]codes.Code{
+	apperror.KindInvalidInput:        codes.InvalidArgument,
+	apperror.KindNotFound:            codes.NotFound,
+	apperror.KindUnauthorized:        codes.Unauthenticated,
+	apperror.KindForbidden:           codes.PermissionDenied,
+	apperror.KindRateLimited:         codes.ResourceExhausted,
+	apperror.KindUpstreamUnavailable: codes.Unavailable,
+	apperror.KindTimeout:             codes.DeadlineExceeded,
+	apperror.KindInternal:            codes.Internal,
+}
+
+// grpcError gives err the status code of its kind, the same way the HTTP ports are answered
+func grpcError(err error) error {
+	return status.Error(grpcCodeByKind[apperror.KindOf(err)], apperror.MessageOf(err))
+}
@@ -0,0 +1,96 @@
+package port
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"strings"
+
+	"bus-timing/pkg/apperror"
+	"bus-timing/pkg/middlewares/apikey"
+	"bus-timing/pkg/middlewares/jwt"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// GRPCAuth runs on gRPC calls the checks the auth middlewares run on HTTP requests,
+// the token and the API key are read from the authorization and x-api-key metadata.
+type GRPCAuth struct {
+	// Authenticator checks the bearer token of the calls, they need none when nil
+	Authenticator interface {
+		Parse(tokenString string) (*jwt.JWTClaims, error)
+	}
+	// APIKeyAuthorizer checks the API key of the calls and counts them against its limits, they need none when nil
+	APIKeyAuthorizer apikey.Authorizer
+}
+
+// UnaryInterceptor refuses the unary calls authorize refuses.
+func (auth GRPCAuth) UnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if err := auth.authorize(ctx); err != nil {
+			if md := retryAfterMD(err); md != nil {
+				grpc.SetHeader(ctx, md)
+			}
+			return nil, grpcError(err)
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamInterceptor refuses the streams authorize refuses.
+func (auth GRPCAuth) StreamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, stream grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := auth.authorize(stream.Context()); err != nil {
+			if md := retryAfterMD(err); md != nil {
+				stream.SetHeader(md)
+			}
+			return grpcError(err)
+		}
+		return handler(srv, stream)
+	}
+}
+
+// authorize tells why a call with the metadata of ctx is refused, like jwt Authorized and apikey.APIKeyMiddleware
+func (auth GRPCAuth) authorize(ctx context.Context) error {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if auth.Authenticator != nil {
+		token, ok := jwt.BearerToken(firstValue(md, "authorization"))
+		if !ok {
+			return apperror.Unauthorized("missing token")
+		}
+		claims, err := auth.Authenticator.Parse(token)
+		if err != nil {
+			return err
+		}
+		if claims.TokenType == jwt.RefreshToken {
+			return apperror.Unauthorized("invalid token: refresh tokens cannot authorize requests")
+		}
+	}
+	if auth.APIKeyAuthorizer != nil {
+		key := firstValue(md, strings.ToLower(apikey.Header))
+		if key == "" {
+			return apperror.Unauthorized("missing api key")
+		}
+		if _, err := auth.APIKeyAuthorizer.Authorize(ctx, key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func firstValue(md metadata.MD, key string) string {
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// retryAfterMD tells rate limited callers when to retry, in seconds as the Retry-After header of the HTTP routes
+func retryAfterMD(err error) metadata.MD {
+	retryAfter := apperror.RetryAfterOf(err)
+	if retryAfter <= 0 {
+		return nil
+	}
+	return metadata.Pairs("retry-after", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+}
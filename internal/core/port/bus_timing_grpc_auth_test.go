@@ -0,0 +1,123 @@
+package port
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	bustimingv1 "bus-timing/api/bustiming/v1"
+	"bus-timing/internal/aggregate"
+	"bus-timing/internal/core/service"
+	"bus-timing/pkg/apperror"
+	"bus-timing/pkg/middlewares/jwt"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+type mockAuthorizer map[string]error
+
+func (authorizer mockAuthorizer) Authorize(ctx context.Context, key string) (string, error) {
+	if err, ok := authorizer[key]; ok {
+		return "", err
+	}
+	return "id-" + key, nil
+}
+
+func TestGRPCAuth(t *testing.T) {
+	t.Parallel()
+
+	authenticator := &jwt.Authenticator{SecretKey: []byte("test-secret-key-of-at-least-32-bytes")}
+	tokens, err := authenticator.GenerateJWT(jwt.JWTClaims{ClientID: "app"})
+	assert.NoError(t, err)
+	forged, err := (&jwt.Authenticator{SecretKey: []byte("change-me")}).GenerateJWT(jwt.JWTClaims{ClientID: "app"})
+	assert.NoError(t, err)
+
+	auth := GRPCAuth{
+		Authenticator: authenticator,
+		APIKeyAuthorizer: mockAuthorizer{
+			"unknown": apperror.Unauthorized("invalid api key"),
+			"limited": apperror.RateLimited(2*time.Second, "rate limit exceeded"),
+		},
+	}
+	client := serveGRPC(t, &BusTimingGRPCServer{
+		BusLineService: mockBusLineService{
+			getBusLines: func(ctx context.Context) ([]aggregate.BusLineBusStop, error) {
+				return nil, nil
+			},
+		},
+		BusLineCatalogue: mockBusLineCatalogue{"44480"},
+		BusPositionService: mockBusPositionService{
+			getBusPosition: func(ctx context.Context, busLineID string) ([]aggregate.BusPosition, error) {
+				return nil, nil
+			},
+		},
+		BusPositionBroadcaster: service.NewBusPositionBroadcaster(4),
+	}, grpc.ChainUnaryInterceptor(auth.UnaryInterceptor()), grpc.ChainStreamInterceptor(auth.StreamInterceptor()))
+
+	withCredentials := func(token, key string) context.Context {
+		md := metadata.MD{}
+		if token != "" {
+			md.Set("authorization", "Bearer "+token)
+		}
+		if key != "" {
+			md.Set("x-api-key", key)
+		}
+		return metadata.NewOutgoingContext(context.Background(), md)
+	}
+
+	t.Run("happy case", func(tt *testing.T) {
+		_, err := client.GetBusLines(withCredentials(tokens.AccessToken, "valid"), &bustimingv1.GetBusLinesRequest{})
+		assert.NoError(tt, err)
+
+		ctx, cancel := context.WithCancel(withCredentials(tokens.AccessToken, "valid"))
+		defer cancel()
+		stream, err := client.WatchBusPositions(ctx, &bustimingv1.WatchBusPositionsRequest{BusLineId: "44480"})
+		assert.NoError(tt, err)
+		_, err = stream.Recv()
+		assert.NoError(tt, err)
+	})
+
+	t.Run("bad case: refused unary calls", func(tt *testing.T) {
+		tests := []struct {
+			token   string
+			key     string
+			code    codes.Code
+			message string
+		}{
+			{"", "valid", codes.Unauthenticated, "missing token"},
+			{forged.AccessToken, "valid", codes.Unauthenticated, "invalid token: token signature is invalid: signature is invalid"},
+			{tokens.RefreshToken, "valid", codes.Unauthenticated, "invalid token: refresh tokens cannot authorize requests"},
+			{tokens.AccessToken, "", codes.Unauthenticated, "missing api key"},
+			{tokens.AccessToken, "unknown", codes.Unauthenticated, "invalid api key"},
+			{tokens.AccessToken, "limited", codes.ResourceExhausted, "rate limit exceeded"},
+		}
+		for _, test := range tests {
+			var header metadata.MD
+			_, err := client.GetBusLines(withCredentials(test.token, test.key), &bustimingv1.GetBusLinesRequest{}, grpc.Header(&header))
+			assert.Equal(tt, test.code, status.Code(err), test.message)
+			assert.Equal(tt, test.message, status.Convert(err).Message())
+			if test.code == codes.ResourceExhausted {
+				assert.Equal(tt, []string{"2"}, header.Get("retry-after"))
+			}
+		}
+	})
+
+	t.Run("bad case: refused streams", func(tt *testing.T) {
+		stream, err := client.WatchBusPositions(withCredentials("", "valid"), &bustimingv1.WatchBusPositionsRequest{BusLineId: "44480"})
+		assert.NoError(tt, err)
+		_, err = stream.Recv()
+		assert.Equal(tt, codes.Unauthenticated, status.Code(err))
+
+		stream, err = client.WatchBusPositions(withCredentials(tokens.AccessToken, "limited"), &bustimingv1.WatchBusPositionsRequest{BusLineId: "44480"})
+		assert.NoError(tt, err)
+		_, err = stream.Recv()
+		assert.Equal(tt, codes.ResourceExhausted, status.Code(err))
+		header, err := stream.Header()
+		assert.NoError(tt, err)
+		assert.Equal(tt, []string{"2"}, header.Get("retry-after"))
+	})
+}
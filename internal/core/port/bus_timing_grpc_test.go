@@ -0,0 +1,278 @@
+package port
+
+import (
+	"context"
+	"errors"
+	"net"
+	"slices"
+	"testing"
+	"time"
+
+	bustimingv1 "bus-timing/api/bustiming/v1"
+	"bus-timing/internal/aggregate"
+	"bus-timing/internal/core/service"
+	"bus-timing/internal/entity"
+	"bus-timing/pkg/apperror"
+	"bus-timing/pkg/common"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+type mockBusLineService struct {
+	getBusLines func(ctx context.Context) ([]aggregate.BusLineBusStop, error)
+}
+
+func (m mockBusLineService) GetBusLines(ctx context.Context) ([]aggregate.BusLineBusStop, error) {
+	return m.getBusLines(ctx)
+}
+
+// mockBusLineCatalogue has the bus lines of its IDs
+type mockBusLineCatalogue []string
+
+func (m mockBusLineCatalogue) GetBusLine(ctx context.Context, busLineID string) (aggregate.BusLineBusStop, error) {
+	if !slices.Contains(m, busLineID) {
+		return aggregate.BusLineBusStop{}, apperror.NotFound("cannot find bus line with ID: %s", busLineID)
+	}
+	return aggregate.BusLineBusStop{BusLine: entity.BusLine{ID: busLineID}}, nil
+}
+
+type mockBusPositionService struct {
+	getBusPosition func(ctx context.Context, busLineID string) ([]aggregate.BusPosition, error)
+}
+
+func (m mockBusPositionService) GetBusPosition(ctx context.Context, busLineID string) ([]aggregate.BusPosition, error) {
+	return m.getBusPosition(ctx, busLineID)
+}
+
+type mockRunningBusService struct {
+	estimatedArrivalTime func(ctx context.Context, busStopID string) ([]aggregate.IncomingBus, error)
+}
+
+func (m mockRunningBusService) EstimatedArrivalTime(ctx context.Context, busStopID string) ([]aggregate.IncomingBus, error) {
+	return m.estimatedArrivalTime(ctx, busStopID)
+}
+
+// serveGRPC serves server over an in-memory connection for the duration of the test
+func serveGRPC(tt *testing.T, server *BusTimingGRPCServer, options ...grpc.ServerOption) bustimingv1.BusTimingClient {
+	listener := bufconn.Listen(1 << 20)
+	grpcServer := grpc.NewServer(options...)
+	bustimingv1.RegisterBusTimingServer(grpcServer, server)
+	go grpcServer.Serve(listener)
+	tt.Cleanup(grpcServer.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	assert.NoError(tt, err)
+	tt.Cleanup(func() { conn.Close() })
+	return bustimingv1.NewBusTimingClient(conn)
+}
+
+func TestBusTimingGRPCServer_GetStopArrivals(t *testing.T) {
+	t.Parallel()
+
+	t.Run("happy case: ETA of the distance at the crowd level speed", func(tt *testing.T) {
+		client := serveGRPC(tt, &BusTimingGRPCServer{
+			RunningBusService: mockRunningBusService{
+				estimatedArrivalTime: func(ctx context.Context, busStopID string) ([]aggregate.IncomingBus, error) {
+					assert.Equal(tt, "377906", busStopID)
+					return []aggregate.IncomingBus{{
+						Bus:         entity.Bus{VehiclePlate: "PD1064Z"},
+						BusLine:     entity.BusLine{ID: "44480", ShortName: "D1"},
+						BusPosition: entity.RunningBusPosition{CrowdLevel: common.MediumCrowd, Lat: 1.29, Lng: 103.77},
+						Distance:    3000,
+						ArrivalTime: 216 * time.Second,
+					}}, nil
+				},
+			},
+		})
+
+		resp, err := client.GetStopArrivals(context.Background(), &bustimingv1.GetStopArrivalsRequest{BusStopId: "377906"})
+		assert.NoError(tt, err)
+		assert.Len(tt, resp.GetArrivals(), 1)
+		arrival := resp.GetArrivals()[0]
+		assert.Equal(tt, "44480", arrival.GetBusLineId())
+		assert.Equal(tt, "PD1064Z", arrival.GetVehiclePlate())
+		assert.Equal(tt, 3000.0, arrival.GetDistance())
+		// 3 km at 50 km/h
+		assert.Equal(tt, 216*time.Second, arrival.GetEta().AsDuration())
+	})
+
+	t.Run("bad case: error codes", func(tt *testing.T) {
+		tests := []struct {
+			err  error
+			code codes.Code
+		}{
+			{apperror.NotFound("bus stop not found"), codes.NotFound},
+			{apperror.InvalidInput("invalid bus stop"), codes.InvalidArgument},
+			{apperror.Upstream(errors.New("connection refused"), "uwave is unavailable"), codes.Unavailable},
+			{apperror.Upstream(context.DeadlineExceeded, "uwave is unavailable"), codes.DeadlineExceeded},
+			{apperror.RateLimited(time.Second, "rate limit exceeded"), codes.ResourceExhausted},
+			{errors.New("boom"), codes.Internal},
+		}
+		for _, test := range tests {
+			client := serveGRPC(tt, &BusTimingGRPCServer{
+				RunningBusService: mockRunningBusService{
+					estimatedArrivalTime: func(ctx context.Context, busStopID string) ([]aggregate.IncomingBus, error) {
+						return nil, test.err
+					},
+				},
+			})
+			_, err := client.GetStopArrivals(context.Background(), &bustimingv1.GetStopArrivalsRequest{BusStopId: "377906"})
+			assert.Equal(tt, test.code, status.Code(err), test.err.Error())
+			assert.Equal(tt, apperror.MessageOf(test.err), status.Convert(err).Message())
+		}
+	})
+
+	t.Run("bad case: missing bus stop", func(tt *testing.T) {
+		client := serveGRPC(tt, &BusTimingGRPCServer{})
+		_, err := client.GetStopArrivals(context.Background(), &bustimingv1.GetStopArrivalsRequest{})
+		assert.Equal(tt, codes.InvalidArgument, status.Code(err))
+	})
+}
+
+func TestBusTimingGRPCServer_GetBusLines(t *testing.T) {
+	t.Parallel()
+
+	busLines := []aggregate.BusLineBusStop{{
+		BusLine: entity.BusLine{
+			ID:           "44480",
+			FullName:     "Campus Loop Red",
+			BusLinePaths: []entity.BusLinePath{{Lat: 1.29, Lng: 103.77}, {Lat: 1.30, Lng: 103.78}},
+		},
+		BusStops: []entity.BusStop{{ID: "377906", Name: "Opp Kent Ridge MRT", Lat: 1.29, Lng: 103.78}},
+	}}
+
+	t.Run("happy case: paths only when asked", func(tt *testing.T) {
+		client := serveGRPC(tt, &BusTimingGRPCServer{
+			BusLineService: mockBusLineService{
+				getBusLines: func(ctx context.Context) ([]aggregate.BusLineBusStop, error) {
+					return busLines, nil
+				},
+			},
+		})
+
+		resp, err := client.GetBusLines(context.Background(), &bustimingv1.GetBusLinesRequest{})
+		assert.NoError(tt, err)
+		assert.Len(tt, resp.GetBusLines(), 1)
+		assert.Equal(tt, "Campus Loop Red", resp.GetBusLines()[0].GetFullName())
+		assert.Equal(tt, "377906", resp.GetBusLines()[0].GetBusStops()[0].GetId())
+		assert.Empty(tt, resp.GetBusLines()[0].GetPath())
+
+		resp, err = client.GetBusLines(context.Background(), &bustimingv1.GetBusLinesRequest{IncludePaths: true})
+		assert.NoError(tt, err)
+		assert.Len(tt, resp.GetBusLines()[0].GetPath(), 2)
+	})
+
+	t.Run("bad case: uWave is unavailable", func(tt *testing.T) {
+		client := serveGRPC(tt, &BusTimingGRPCServer{
+			BusLineService: mockBusLineService{
+				getBusLines: func(ctx context.Context) ([]aggregate.BusLineBusStop, error) {
+					return nil, apperror.Upstream(errors.New("connection refused"), "uwave is unavailable")
+				},
+			},
+		})
+
+		_, err := client.GetBusLines(context.Background(), &bustimingv1.GetBusLinesRequest{})
+		assert.Equal(tt, codes.Unavailable, status.Code(err))
+		assert.Equal(tt, "uwave is unavailable", status.Convert(err).Message())
+	})
+}
+
+func TestBusTimingGRPCServer_WatchBusPositions(t *testing.T) {
+	t.Parallel()
+
+	busPositions := func(vehiclePlate string, lat float64) []aggregate.BusPosition {
+		return []aggregate.BusPosition{{
+			Bus:                entity.Bus{VehiclePlate: vehiclePlate},
+			RunningBusPosition: entity.RunningBusPosition{Lat: lat, Lng: 103.77, CrowdLevel: common.LowCrowd},
+		}}
+	}
+
+	t.Run("happy case: current positions, then every move", func(tt *testing.T) {
+		broadcaster := service.NewBusPositionBroadcaster(4)
+		client := serveGRPC(tt, &BusTimingGRPCServer{
+			BusLineCatalogue: mockBusLineCatalogue{"44480"},
+			BusPositionService: mockBusPositionService{
+				getBusPosition: func(ctx context.Context, busLineID string) ([]aggregate.BusPosition, error) {
+					return busPositions("PD1064Z", 1.29), nil
+				},
+			},
+			BusPositionBroadcaster: broadcaster,
+		})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		stream, err := client.WatchBusPositions(ctx, &bustimingv1.WatchBusPositionsRequest{BusLineId: "44480"})
+		assert.NoError(tt, err)
+
+		update, err := stream.Recv()
+		assert.NoError(tt, err)
+		assert.Equal(tt, "44480", update.GetBusLineId())
+		assert.Equal(tt, "PD1064Z", update.GetBusPositions()[0].GetVehiclePlate())
+		assert.Equal(tt, string(common.LowCrowd), update.GetBusPositions()[0].GetCrowdLevel())
+
+		broadcaster.Publish("44480", busPositions("PD1064Z", 1.30))
+		next, err := stream.Recv()
+		assert.NoError(tt, err)
+		assert.Greater(tt, next.GetEventId(), update.GetEventId())
+		assert.Equal(tt, 1.30, next.GetBusPositions()[0].GetLat())
+	})
+
+	t.Run("happy case: resumed from the last event", func(tt *testing.T) {
+		broadcaster := service.NewBusPositionBroadcaster(4)
+		broadcaster.Publish("44480", busPositions("PD1064Z", 1.29))
+		replay, _, cancelSubscription := broadcaster.Subscribe("44480", 0)
+		cancelSubscription()
+		broadcaster.Publish("44480", busPositions("PD1064Z", 1.30))
+
+		client := serveGRPC(tt, &BusTimingGRPCServer{
+			BusLineCatalogue:       mockBusLineCatalogue{"44480"},
+			BusPositionBroadcaster: broadcaster,
+		})
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		stream, err := client.WatchBusPositions(ctx, &bustimingv1.WatchBusPositionsRequest{BusLineId: "44480", LastEventId: replay[0].ID})
+		assert.NoError(tt, err)
+
+		update, err := stream.Recv()
+		assert.NoError(tt, err)
+		assert.Equal(tt, 1.30, update.GetBusPositions()[0].GetLat())
+	})
+
+	t.Run("bad case: error codes", func(tt *testing.T) {
+		client := serveGRPC(tt, &BusTimingGRPCServer{
+			BusLineCatalogue: mockBusLineCatalogue{"44480"},
+			BusPositionService: mockBusPositionService{
+				getBusPosition: func(ctx context.Context, busLineID string) ([]aggregate.BusPosition, error) {
+					return nil, apperror.Upstream(errors.New("connection refused"), "uwave is unavailable")
+				},
+			},
+			BusPositionBroadcaster: service.NewBusPositionBroadcaster(4),
+		})
+
+		stream, err := client.WatchBusPositions(context.Background(), &bustimingv1.WatchBusPositionsRequest{BusLineId: "0"})
+		assert.NoError(tt, err)
+		_, err = stream.Recv()
+		assert.Equal(tt, codes.NotFound, status.Code(err))
+		assert.Equal(tt, "cannot find bus line with ID: 0", status.Convert(err).Message())
+
+		stream, err = client.WatchBusPositions(context.Background(), &bustimingv1.WatchBusPositionsRequest{BusLineId: "44480"})
+		assert.NoError(tt, err)
+		_, err = stream.Recv()
+		assert.Equal(tt, codes.Unavailable, status.Code(err))
+
+		stream, err = client.WatchBusPositions(context.Background(), &bustimingv1.WatchBusPositionsRequest{})
+		assert.NoError(tt, err)
+		_, err = stream.Recv()
+		assert.Equal(tt, codes.InvalidArgument, status.Code(err))
+	})
+}
@@ -0,0 +1,458 @@
+package port
+
+import (
+	"context"
+	_ "embed"
+	"log/slog"
+	"net/http"
+	"sync"
+
+	"bus-timing/internal/aggregate"
+	"bus-timing/pkg/apperror"
+
+	"github.com/gin-gonic/gin"
+	graphql "github.com/graph-gophers/graphql-go"
+	gqlerrors "github.com/graph-gophers/graphql-go/errors"
+)
+
+//go:embed schema.graphql
+var graphQLSchema string
+
+const (
+	maxGraphQLBusStopLimit = 500
+	// maxGraphQLDepth stops queries nesting bus stops and bus lines without end
+	maxGraphQLDepth = 8
+)
+
+type GraphQLPort struct {
+	BusLineService interface {
+		GetBusLines(ctx context.Context) ([]aggregate.BusLineBusStop, error)
+	}
+	BusStopService interface {
+		GetBusStops(ctx context.Context, offset, limit int) ([]aggregate.BusStopBusLines, int, error)
+		GetBusStop(ctx context.Context, busStopID string) (aggregate.BusStopBusLines, error)
+	}
+	BusPositionService interface {
+		GetBusPosition(ctx context.Context, busLineID string) ([]aggregate.BusPosition, error)
+	}
+	RunningBusService interface {
+		EstimatedArrivalTime(ctx context.Context, busStopID string) ([]aggregate.IncomingBus, error)
+	}
+
+	once   sync.Once
+	schema *graphql.Schema
+}
+
+type GraphQLRequest struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+// Query executes a GraphQL request, every service call is made at most once per request.
+func (port *GraphQLPort) Query(ctx *gin.Context) {
+	port.once.Do(func() {
+		port.schema = graphql.MustParseSchema(graphQLSchema, &graphQLResolver{}, graphql.UseFieldResolvers(), graphql.MaxDepth(maxGraphQLDepth),
+			graphql.PanicHandler(graphQLPanicHandler{}))
+	})
+
+	req := GraphQLRequest{}
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Error(apperror.InvalidInput("invalid body: %s", err))
+		return
+	}
+
+	loader := &graphQLLoader{port: port}
+	reqCtx := context.WithValue(ctx.Request.Context(), graphQLLoaderKey{}, loader)
+	resp := port.schema.Exec(reqCtx, req.Query, req.OperationName, req.Variables)
+	for _, err := range resp.Errors {
+		maskResolverError(reqCtx, err)
+	}
+	ctx.JSON(http.StatusOK, resp)
+}
+
+// maskResolverError answers a resolver error like the error handler answers the routes,
+// with the message and code of its kind. Internal errors are logged and not detailed to clients.
+func maskResolverError(ctx context.Context, err *gqlerrors.QueryError) {
+	if err.ResolverError == nil {
+		return
+	}
+	kind := apperror.KindOf(err.ResolverError)
+	if kind == apperror.KindInternal {
+		slog.ErrorContext(ctx, "graphql resolver failed", "path", err.Path, "error", err.ResolverError)
+	}
+	err.Message = apperror.MessageOf(err.ResolverError)
+	if err.Extensions == nil {
+		err.Extensions = make(map[string]interface{})
+	}
+	err.Extensions["code"] = kind
+}
+
+// graphQLPanicHandler does not detail panics of resolvers to clients, graphql-go logs them
+type graphQLPanicHandler struct{}
+
+func (graphQLPanicHandler) MakePanicError(ctx context.Context, value interface{}) *gqlerrors.QueryError {
+	return &gqlerrors.QueryError{
+		Message:    "internal error",
+		Extensions: map[string]interface{}{"code": apperror.KindInternal},
+	}
+}
+
+type graphQLLoaderKey struct{}
+
+// graphQLLoader memoizes service calls of a request, so bus lines sharing a bus stop
+// or bus stops sharing a bus line fetch positions and arrivals once.
+type graphQLLoader struct {
+	port *GraphQLPort
+
+	busLinesOnce sync.Once
+	busLines     []aggregate.BusLineBusStop
+	busLineByID  map[string]aggregate.BusLineBusStop
+	busLinesErr  error
+
+	mu           sync.Mutex
+	busPositions map[string]*graphQLCall[[]aggregate.BusPosition]
+	arrivals     map[string]*graphQLCall[[]aggregate.IncomingBus]
+}
+
+type graphQLCall[T any] struct {
+	once sync.Once
+	val  T
+	err  error
+}
+
+func loaderFrom(ctx context.Context) *graphQLLoader {
+	return ctx.Value(graphQLLoaderKey{}).(*graphQLLoader)
+}
+
+// allBusLines reads the catalogue once per request, in its order
+func (loader *graphQLLoader) allBusLines(ctx context.Context) ([]aggregate.BusLineBusStop, error) {
+	loader.busLinesOnce.Do(func() {
+		loader.busLines, loader.busLinesErr = loader.port.BusLineService.GetBusLines(ctx)
+		loader.busLineByID = make(map[string]aggregate.BusLineBusStop, len(loader.busLines))
+		for _, val := range loader.busLines {
+			loader.busLineByID[val.BusLine.ID] = val
+		}
+	})
+	return loader.busLines, loader.busLinesErr
+}
+
+func (loader *graphQLLoader) busLine(ctx context.Context, busLineID string) (aggregate.BusLineBusStop, bool, error) {
+	if _, err := loader.allBusLines(ctx); err != nil {
+		return aggregate.BusLineBusStop{}, false, err
+	}
+	busLine, ok := loader.busLineByID[busLineID]
+	return busLine, ok, nil
+}
+
+func (loader *graphQLLoader) busPosition(ctx context.Context, busLineID string) ([]aggregate.BusPosition, error) {
+	loader.mu.Lock()
+	if loader.busPositions == nil {
+		loader.busPositions = make(map[string]*graphQLCall[[]aggregate.BusPosition])
+	}
+	call, ok := loader.busPositions[busLineID]
+	if !ok {
+		call = &graphQLCall[[]aggregate.BusPosition]{}
+		loader.busPositions[busLineID] = call
+	}
+	loader.mu.Unlock()
+
+	call.once.Do(func() {
+		call.val, call.err = loader.port.BusPositionService.GetBusPosition(ctx, busLineID)
+	})
+	return call.val, call.err
+}
+
+func (loader *graphQLLoader) arrival(ctx context.Context, busStopID string) ([]aggregate.IncomingBus, error) {
+	loader.mu.Lock()
+	if loader.arrivals == nil {
+		loader.arrivals = make(map[string]*graphQLCall[[]aggregate.IncomingBus])
+	}
+	call, ok := loader.arrivals[busStopID]
+	if !ok {
+		call = &graphQLCall[[]aggregate.IncomingBus]{}
+		loader.arrivals[busStopID] = call
+	}
+	loader.mu.Unlock()
+
+	call.once.Do(func() {
+		call.val, call.err = loader.port.RunningBusService.EstimatedArrivalTime(ctx, busStopID)
+	})
+	return call.val, call.err
+}
+
+type graphQLResolver struct{}
+
+type busLinesArgs struct {
+	IDs *[]graphql.ID
+}
+
+func (r *graphQLResolver) BusLines(ctx context.Context, args busLinesArgs) ([]*busLineResolver, error) {
+	loader := loaderFrom(ctx)
+	if args.IDs != nil {
+		resolvers := make([]*busLineResolver, 0, len(*args.IDs))
+		for _, id := range *args.IDs {
+			busLine, ok, err := loader.busLine(ctx, string(id))
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				resolvers = append(resolvers, &busLineResolver{busLine: busLine})
+			}
+		}
+		return resolvers, nil
+	}
+
+	busLines, err := loader.allBusLines(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resolvers := make([]*busLineResolver, 0, len(busLines))
+	for _, val := range busLines {
+		resolvers = append(resolvers, &busLineResolver{busLine: val})
+	}
+	return resolvers, nil
+}
+
+func (r *graphQLResolver) BusLine(ctx context.Context, args struct{ ID graphql.ID }) (*busLineResolver, error) {
+	busLine, ok, err := loaderFrom(ctx).busLine(ctx, string(args.ID))
+	if err != nil || !ok {
+		return nil, err
+	}
+	return &busLineResolver{busLine: busLine}, nil
+}
+
+type busStopsArgs struct {
+	Offset int32
+	Limit  int32
+}
+
+func (r *graphQLResolver) BusStops(ctx context.Context, args busStopsArgs) ([]*busStopResolver, error) {
+	if args.Offset < 0 {
+		return nil, apperror.InvalidInput("invalid offset: %d", args.Offset)
+	}
+	if args.Limit <= 0 || args.Limit > maxGraphQLBusStopLimit {
+		return nil, apperror.InvalidInput("invalid limit: %d", args.Limit)
+	}
+
+	busStops, _, err := loaderFrom(ctx).port.BusStopService.GetBusStops(ctx, int(args.Offset), int(args.Limit))
+	if err != nil {
+		return nil, err
+	}
+	resolvers := make([]*busStopResolver, 0, len(busStops))
+	for _, val := range busStops {
+		resolvers = append(resolvers, &busStopResolver{busStop: val})
+	}
+	return resolvers, nil
+}
+
+func (r *graphQLResolver) BusStop(ctx context.Context, args struct{ ID graphql.ID }) (*busStopResolver, error) {
+	busStop, err := loaderFrom(ctx).port.BusStopService.GetBusStop(ctx, string(args.ID))
+	if err != nil {
+		return nil, err
+	}
+	return &busStopResolver{busStop: busStop}, nil
+}
+
+type busLineResolver struct {
+	busLine aggregate.BusLineBusStop
+	// busStopID is the bus stop the bus line was reached from, if any
+	busStopID string
+}
+
+func (r *busLineResolver) ID() graphql.ID {
+	return graphql.ID(r.busLine.BusLine.ID)
+}
+
+func (r *busLineResolver) FullName() string {
+	return r.busLine.BusLine.FullName
+}
+
+func (r *busLineResolver) ShortName() string {
+	return r.busLine.BusLine.ShortName
+}
+
+func (r *busLineResolver) Origin() string {
+	return r.busLine.BusLine.Origin
+}
+
+func (r *busLineResolver) BusStops(ctx context.Context) ([]*busStopResolver, error) {
+	loader := loaderFrom(ctx)
+	resolvers := make([]*busStopResolver, 0, len(r.busLine.BusStops))
+	for _, val := range r.busLine.BusStops {
+		busStop, err := loader.port.BusStopService.GetBusStop(ctx, val.ID)
+		if err != nil {
+			return nil, err
+		}
+		resolvers = append(resolvers, &busStopResolver{busStop: busStop})
+	}
+	return resolvers, nil
+}
+
+func (r *busLineResolver) Path() []*latLngResolver {
+	resolvers := make([]*latLngResolver, 0, len(r.busLine.BusLine.BusLinePaths))
+	for _, val := range r.busLine.BusLine.BusLinePaths {
+		resolvers = append(resolvers, &latLngResolver{Lat: val.Lat, Lng: val.Lng})
+	}
+	return resolvers
+}
+
+func (r *busLineResolver) Buses(ctx context.Context) ([]*busResolver, error) {
+	busPositions, err := loaderFrom(ctx).busPosition(ctx, r.busLine.BusLine.ID)
+	if err != nil {
+		return nil, err
+	}
+	resolvers := make([]*busResolver, 0, len(busPositions))
+	for _, val := range busPositions {
+		resolvers = append(resolvers, &busResolver{
+			busLineID:    r.busLine.BusLine.ID,
+			busStopID:    r.busStopID,
+			vehiclePlate: val.Bus.VehiclePlate,
+			bearing:      val.Bus.Bearing,
+			crowdLevel:   string(val.RunningBusPosition.CrowdLevel),
+			lat:          val.RunningBusPosition.Lat,
+			lng:          val.RunningBusPosition.Lng,
+		})
+	}
+	return resolvers, nil
+}
+
+type busStopResolver struct {
+	busStop aggregate.BusStopBusLines
+}
+
+func (r *busStopResolver) ID() graphql.ID {
+	return graphql.ID(r.busStop.BusStop.ID)
+}
+
+func (r *busStopResolver) Name() string {
+	return r.busStop.BusStop.Name
+}
+
+func (r *busStopResolver) Lat() float64 {
+	return r.busStop.BusStop.Lat
+}
+
+func (r *busStopResolver) Lng() float64 {
+	return r.busStop.BusStop.Lng
+}
+
+func (r *busStopResolver) BusLines(ctx context.Context) ([]*busLineResolver, error) {
+	loader := loaderFrom(ctx)
+	resolvers := make([]*busLineResolver, 0, len(r.busStop.BusLines))
+	for _, val := range r.busStop.BusLines {
+		busLine, ok, err := loader.busLine(ctx, val.BusLine.ID)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			busLine = aggregate.BusLineBusStop{BusLine: val.BusLine}
+		}
+		resolvers = append(resolvers, &busLineResolver{busLine: busLine, busStopID: r.busStop.BusStop.ID})
+	}
+	return resolvers, nil
+}
+
+func (r *busStopResolver) Arrivals(ctx context.Context) ([]*arrivalResolver, error) {
+	incomingBuses, err := loaderFrom(ctx).arrival(ctx, r.busStop.BusStop.ID)
+	if err != nil {
+		return nil, err
+	}
+	resolvers := make([]*arrivalResolver, 0, len(incomingBuses))
+	for _, val := range incomingBuses {
+		resolvers = append(resolvers, &arrivalResolver{incomingBus: val, busStopID: r.busStop.BusStop.ID})
+	}
+	return resolvers, nil
+}
+
+type busResolver struct {
+	busLineID    string
+	busStopID    string
+	vehiclePlate string
+	bearing      float64
+	crowdLevel   string
+	lat          float64
+	lng          float64
+}
+
+func (r *busResolver) VehiclePlate() string {
+	return r.vehiclePlate
+}
+
+func (r *busResolver) Bearing() float64 {
+	return r.bearing
+}
+
+func (r *busResolver) CrowdLevel() string {
+	return r.crowdLevel
+}
+
+func (r *busResolver) Lat() float64 {
+	return r.lat
+}
+
+func (r *busResolver) Lng() float64 {
+	return r.lng
+}
+
+func (r *busResolver) Eta(ctx context.Context) (*int32, error) {
+	if r.busStopID == "" {
+		return nil, nil
+	}
+	incomingBuses, err := loaderFrom(ctx).arrival(ctx, r.busStopID)
+	if err != nil {
+		return nil, err
+	}
+	for _, val := range incomingBuses {
+		if val.BusLine.ID == r.busLineID && val.Bus.VehiclePlate == r.vehiclePlate {
+			eta := etaSeconds(val)
+			return &eta, nil
+		}
+	}
+	return nil, nil
+}
+
+type arrivalResolver struct {
+	incomingBus aggregate.IncomingBus
+	busStopID   string
+}
+
+func (r *arrivalResolver) BusLine(ctx context.Context) (*busLineResolver, error) {
+	busLine, ok, err := loaderFrom(ctx).busLine(ctx, r.incomingBus.BusLine.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		busLine = aggregate.BusLineBusStop{BusLine: r.incomingBus.BusLine}
+	}
+	return &busLineResolver{busLine: busLine, busStopID: r.busStopID}, nil
+}
+
+func (r *arrivalResolver) Bus() *busResolver {
+	return &busResolver{
+		busLineID:    r.incomingBus.BusLine.ID,
+		busStopID:    r.busStopID,
+		vehiclePlate: r.incomingBus.Bus.VehiclePlate,
+		bearing:      r.incomingBus.Bus.Bearing,
+		crowdLevel:   string(r.incomingBus.BusPosition.CrowdLevel),
+		lat:          r.incomingBus.BusPosition.Lat,
+		lng:          r.incomingBus.BusPosition.Lng,
+	}
+}
+
+func (r *arrivalResolver) Eta() int32 {
+	return etaSeconds(r.incomingBus)
+}
+
+func (r *arrivalResolver) Distance() float64 {
+	return r.incomingBus.Distance
+}
+
+type latLngResolver struct {
+	Lat float64
+	Lng float64
+}
+
+func etaSeconds(incomingBus aggregate.IncomingBus) int32 {
+	return int32(incomingBus.ArrivalTime.Seconds())
+}
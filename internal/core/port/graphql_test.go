@@ -0,0 +1,272 @@
+package port
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"bus-timing/internal/aggregate"
+	"bus-timing/internal/entity"
+	"bus-timing/pkg/apperror"
+	"bus-timing/pkg/common"
+	"bus-timing/pkg/middlewares/errorhandler"
+
+	"github.com/gin-gonic/gin"
+	pkgerrors "github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+// mockGraphQLServices serves two bus lines sharing bus stop 377906 and counts the calls by service and argument
+type mockGraphQLServices struct {
+	mu    sync.Mutex
+	calls map[string]int
+	// err fails the calls it is keyed by, e.g. "GetBusPosition 44480"
+	err map[string]error
+}
+
+func (m *mockGraphQLServices) call(name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.calls == nil {
+		m.calls = make(map[string]int)
+	}
+	m.calls[name]++
+	return m.err[name]
+}
+
+func (m *mockGraphQLServices) count(name string) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.calls[name]
+}
+
+var (
+	graphQLBusLines = []aggregate.BusLineBusStop{
+		{
+			BusLine:  entity.BusLine{ID: "44480", FullName: "Campus Loop Red", ShortName: "CL-R"},
+			BusStops: []entity.BusStop{{ID: "377906"}, {ID: "378204"}},
+		},
+		{
+			BusLine:  entity.BusLine{ID: "44481", FullName: "Campus Loop Blue", ShortName: "CL-B"},
+			BusStops: []entity.BusStop{{ID: "377906"}},
+		},
+	}
+	graphQLBusStop = aggregate.BusStopBusLines{
+		BusStop: entity.BusStop{ID: "377906", Name: "Opp Kent Ridge MRT"},
+		BusLines: []aggregate.ServedBusLine{
+			{BusLine: graphQLBusLines[0].BusLine, StopOrder: 1},
+			{BusLine: graphQLBusLines[1].BusLine, StopOrder: 1},
+		},
+	}
+)
+
+func (m *mockGraphQLServices) GetBusLines(ctx context.Context) ([]aggregate.BusLineBusStop, error) {
+	if err := m.call("GetBusLines"); err != nil {
+		return nil, err
+	}
+	return graphQLBusLines, nil
+}
+
+func (m *mockGraphQLServices) GetBusStops(ctx context.Context, offset, limit int) ([]aggregate.BusStopBusLines, int, error) {
+	if err := m.call("GetBusStops"); err != nil {
+		return nil, 0, err
+	}
+	return []aggregate.BusStopBusLines{graphQLBusStop}, 1, nil
+}
+
+func (m *mockGraphQLServices) GetBusStop(ctx context.Context, busStopID string) (aggregate.BusStopBusLines, error) {
+	if err := m.call("GetBusStop " + busStopID); err != nil {
+		return aggregate.BusStopBusLines{}, err
+	}
+	if busStopID != graphQLBusStop.BusStop.ID {
+		return aggregate.BusStopBusLines{BusStop: entity.BusStop{ID: busStopID}}, nil
+	}
+	return graphQLBusStop, nil
+}
+
+func (m *mockGraphQLServices) GetBusPosition(ctx context.Context, busLineID string) ([]aggregate.BusPosition, error) {
+	if err := m.call("GetBusPosition " + busLineID); err != nil {
+		return nil, err
+	}
+	return []aggregate.BusPosition{{
+		Bus:                entity.Bus{VehiclePlate: "PD" + busLineID},
+		RunningBusPosition: entity.RunningBusPosition{CrowdLevel: common.MediumCrowd},
+	}}, nil
+}
+
+func (m *mockGraphQLServices) EstimatedArrivalTime(ctx context.Context, busStopID string) ([]aggregate.IncomingBus, error) {
+	if err := m.call("EstimatedArrivalTime " + busStopID); err != nil {
+		return nil, err
+	}
+	return []aggregate.IncomingBus{{
+		Bus:         entity.Bus{VehiclePlate: "PD44480"},
+		BusLine:     graphQLBusLines[0].BusLine,
+		BusPosition: entity.RunningBusPosition{CrowdLevel: common.MediumCrowd},
+		Distance:    3000,
+		ArrivalTime: 216 * time.Second,
+	}}, nil
+}
+
+type graphQLResponse struct {
+	Data   json.RawMessage `json:"data"`
+	Errors []struct {
+		Message    string                 `json:"message"`
+		Path       []interface{}          `json:"path"`
+		Extensions map[string]interface{} `json:"extensions"`
+	} `json:"errors"`
+}
+
+func TestGraphQLPort_Query(t *testing.T) {
+	t.Parallel()
+
+	gin.SetMode(gin.TestMode)
+	serve := func(services *mockGraphQLServices, body string) (*httptest.ResponseRecorder, graphQLResponse) {
+		port := &GraphQLPort{
+			BusLineService:     services,
+			BusStopService:     services,
+			BusPositionService: services,
+			RunningBusService:  services,
+		}
+		router := gin.New()
+		router.Use(errorhandler.ErrorHandlerMiddleware())
+		router.POST("/api/graphql", port.Query)
+
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/api/graphql", strings.NewReader(body)))
+		resp := graphQLResponse{}
+		json.Unmarshal(recorder.Body.Bytes(), &resp)
+		return recorder, resp
+	}
+	query := func(query string) string {
+		body, _ := json.Marshal(GraphQLRequest{Query: query})
+		return string(body)
+	}
+
+	t.Run("happy case: one call per bus line and bus stop across a stop, lines, buses and ETAs query", func(tt *testing.T) {
+		services := &mockGraphQLServices{}
+		recorder, resp := serve(services, query(`{
+			busStop(id: "377906") {
+				name
+				busLines { id buses { vehiclePlate eta } }
+				arrivals { eta distance busLine { id buses { vehiclePlate eta } } bus { vehiclePlate eta } }
+			}
+		}`))
+
+		assert.Equal(tt, http.StatusOK, recorder.Code)
+		assert.Empty(tt, resp.Errors)
+		assert.JSONEq(tt, `{"busStop": {
+			"name": "Opp Kent Ridge MRT",
+			"busLines": [
+				{"id": "44480", "buses": [{"vehiclePlate": "PD44480", "eta": 216}]},
+				{"id": "44481", "buses": [{"vehiclePlate": "PD44481", "eta": null}]}
+			],
+			"arrivals": [{
+				"eta": 216,
+				"distance": 3000,
+				"busLine": {"id": "44480", "buses": [{"vehiclePlate": "PD44480", "eta": 216}]},
+				"bus": {"vehiclePlate": "PD44480", "eta": 216}
+			}]
+		}}`, string(resp.Data))
+		assert.Equal(tt, 1, services.count("GetBusStop 377906"))
+		assert.Equal(tt, 1, services.count("GetBusLines"))
+		assert.Equal(tt, 1, services.count("GetBusPosition 44480"))
+		assert.Equal(tt, 1, services.count("GetBusPosition 44481"))
+		assert.Equal(tt, 1, services.count("EstimatedArrivalTime 377906"))
+	})
+
+	t.Run("happy case: the catalogue is read once per request", func(tt *testing.T) {
+		services := &mockGraphQLServices{}
+		_, resp := serve(services, query(`{
+			busLines { id }
+			selected: busLines(ids: ["44481", "0"]) { id }
+			busLine(id: "44480") { shortName }
+		}`))
+
+		assert.Empty(tt, resp.Errors)
+		assert.JSONEq(tt, `{
+			"busLines": [{"id": "44480"}, {"id": "44481"}],
+			"selected": [{"id": "44481"}],
+			"busLine": {"shortName": "CL-R"}
+		}`, string(resp.Data))
+		assert.Equal(tt, 1, services.count("GetBusLines"))
+	})
+
+	t.Run("bad case: failed service calls", func(tt *testing.T) {
+		services := &mockGraphQLServices{err: map[string]error{
+			"GetBusPosition 44481": apperror.Upstream(errors.New("connection refused"), "uwave is unavailable"),
+		}}
+		recorder, resp := serve(services, query(`{ busLines { id buses { vehiclePlate } } }`))
+
+		assert.Equal(tt, http.StatusOK, recorder.Code)
+		assert.Len(tt, resp.Errors, 1)
+		assert.Equal(tt, "uwave is unavailable", resp.Errors[0].Message)
+		assert.Equal(tt, "upstream_unavailable", resp.Errors[0].Extensions["code"])
+		assert.Equal(tt, []interface{}{"busLines", 1.0, "buses"}, resp.Errors[0].Path)
+		assert.Equal(tt, 1, services.count("GetBusPosition 44481"))
+
+		services = &mockGraphQLServices{err: map[string]error{
+			"GetBusLines": apperror.Upstream(errors.New("connection refused"), "uwave is unavailable"),
+		}}
+		_, resp = serve(services, query(`{ busLines { id } busLine(id: "44480") { id } }`))
+		assert.NotEmpty(tt, resp.Errors)
+		assert.Contains(tt, resp.Errors[0].Message, "uwave is unavailable")
+		assert.Equal(tt, 1, services.count("GetBusLines"))
+	})
+
+	t.Run("bad case: internal errors and upstream details are not sent to clients", func(tt *testing.T) {
+		services := &mockGraphQLServices{err: map[string]error{
+			"GetBusStop 377906": errors.New("open /var/lib/bus-timing/catalogue.json: permission denied"),
+			"EstimatedArrivalTime 378204": pkgerrors.Wrap(
+				apperror.Upstream(errors.New("dial tcp: lookup internal-uwave.svc"), "uwave is unavailable"),
+				"UWaveClient.GetRunningBusByBusLineID: https://internal-uwave.svc/busPositions/44480",
+			),
+		}}
+		_, resp := serve(services, query(`{
+			a: busStop(id: "377906") { id }
+			b: busStop(id: "378204") { arrivals { eta } }
+		}`))
+
+		if assert.Len(tt, resp.Errors, 2) {
+			messages := map[string]interface{}{}
+			for _, val := range resp.Errors {
+				messages[val.Message] = val.Extensions["code"]
+			}
+			assert.Equal(tt, map[string]interface{}{
+				"internal error":       "internal",
+				"uwave is unavailable": "upstream_unavailable",
+			}, messages)
+		}
+	})
+
+	t.Run("bad case: limits", func(tt *testing.T) {
+		tests := []struct {
+			query   string
+			message string
+		}{
+			{`{ busStops(limit: 501) { id } }`, "invalid limit: 501"},
+			{`{ busStops(limit: 0) { id } }`, "invalid limit: 0"},
+			{`{ busStops(offset: -1) { id } }`, "invalid offset: -1"},
+			{`{ busStop(id: "377906") { busLines { busStops { busLines { busStops { busLines { busStops { busLines { id } } } } } } } } }`, "exceeds max depth 8"},
+		}
+		for _, test := range tests {
+			services := &mockGraphQLServices{}
+			_, resp := serve(services, query(test.query))
+			if assert.Len(tt, resp.Errors, 1, test.query) {
+				assert.Contains(tt, resp.Errors[0].Message, test.message)
+			}
+			assert.Zero(tt, services.count("GetBusStops"))
+			assert.Zero(tt, services.count("GetBusStop 377906"))
+		}
+	})
+
+	t.Run("bad case: invalid body", func(tt *testing.T) {
+		recorder, _ := serve(&mockGraphQLServices{}, `{"query": 1}`)
+		assert.Equal(tt, http.StatusBadRequest, recorder.Code)
+	})
+}
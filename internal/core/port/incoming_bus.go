@@ -2,8 +2,9 @@ package port
 
 import (
 	"bus-timing/internal/aggregate"
+	"bus-timing/pkg/apperror"
+	"bus-timing/pkg/common"
 	"context"
-	"fmt"
 	"net/http"
 	"time"
 
@@ -14,6 +15,8 @@ type RunningBusPort struct {
 	BusTimingService interface {
 		EstimatedArrivalTime(ctx context.Context, busStopID string) ([]aggregate.IncomingBus, error)
 	}
+	// StaleAfter is the age from which v2 flags a bus position as stale
+	StaleAfter time.Duration
 }
 
 type GetIncomingBusRequest struct {
@@ -43,19 +46,25 @@ type Bus struct {
 func (port *RunningBusPort) EstimatedArrival(ctx *gin.Context) {
 	busStopID := ctx.Param("busStopID")
 	if busStopID == "" {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid bus stop: %s", busStopID)})
+		ctx.Error(apperror.InvalidInput("invalid bus stop: %s", busStopID))
 		return
 	}
 
 	incomingBuses, err := port.BusTimingService.EstimatedArrivalTime(ctx, busStopID)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		ctx.Error(err)
 		return
 	}
 
 	ctx.JSON(http.StatusOK, transformIncomingBusToEstimatedArrival(incomingBuses))
 }
 
+// legacyTimeDuration is the v1 timeDuration, the distance divided by the crowd level speed in km/h
+// read as nanoseconds. v1 clients depend on it, v2 and the other APIs answer the arrival time.
+func legacyTimeDuration(incomingBus aggregate.IncomingBus) time.Duration {
+	return time.Duration(incomingBus.Distance / common.MapCrowdLevelAndSpeed[incomingBus.BusPosition.CrowdLevel])
+}
+
 func transformIncomingBusToEstimatedArrival(incomingBuses []aggregate.IncomingBus) IncomingBusResponse {
 	payload := make([]BusLine, 0, len(incomingBuses))
 	for _, val := range incomingBuses {
@@ -68,7 +77,7 @@ func transformIncomingBusToEstimatedArrival(incomingBuses []aggregate.IncomingBu
 				Lat:          val.BusPosition.Lat,
 				Lng:          val.BusPosition.Lng,
 				VehiclePlate: val.Bus.VehiclePlate,
-				TimeDuration: val.ArrivalTime,
+				TimeDuration: legacyTimeDuration(val),
 				Distance:     val.Distance,
 			},
 		})
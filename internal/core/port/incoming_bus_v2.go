@@ -0,0 +1,85 @@
+package port
+
+import (
+	"net/http"
+	"time"
+
+	"bus-timing/internal/aggregate"
+	"bus-timing/pkg/apperror"
+
+	"github.com/gin-gonic/gin"
+)
+
+type GetArrivalsV2Response struct {
+	Data []ArrivalBusLineV2Payload `json:"data"`
+}
+
+type ArrivalBusLineV2Payload struct {
+	ID        string                 `json:"id"`
+	FullName  string                 `json:"fullName"`
+	ShortName string                 `json:"shortName"`
+	Origin    string                 `json:"origin"`
+	Buses     []ArrivingBusV2Payload `json:"buses"`
+}
+
+// ArrivingBusV2Payload distance is in meters and eta in seconds
+type ArrivingBusV2Payload struct {
+	VehiclePlate string    `json:"vehiclePlate"`
+	Lat          float64   `json:"lat"`
+	Lng          float64   `json:"lng"`
+	Distance     float64   `json:"distance"`
+	ETA          int64     `json:"eta"`
+	ArrivesAt    time.Time `json:"arrivesAt"`
+	UpdatedAt    time.Time `json:"updatedAt"`
+	// Stale tells the position the estimation is based on was fetched too long ago to be trusted
+	Stale bool `json:"stale"`
+}
+
+// EstimatedArrivalV2 answers the incoming buses of a bus stop grouped by bus line.
+func (port *RunningBusPort) EstimatedArrivalV2(ctx *gin.Context) {
+	busStopID := ctx.Param("busStopID")
+	if busStopID == "" {
+		ctx.Error(apperror.InvalidInput("invalid bus stop: %s", busStopID))
+		return
+	}
+
+	incomingBuses, err := port.BusTimingService.EstimatedArrivalTime(ctx, busStopID)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, transformArrivalsV2Response(incomingBuses, port.StaleAfter, time.Now()))
+}
+
+func transformArrivalsV2Response(incomingBuses []aggregate.IncomingBus, staleAfter time.Duration, now time.Time) GetArrivalsV2Response {
+	payload := make([]ArrivalBusLineV2Payload, 0, len(incomingBuses))
+	busLineIndex := make(map[string]int, len(incomingBuses))
+	for _, val := range incomingBuses {
+		idx, ok := busLineIndex[val.BusLine.ID]
+		if !ok {
+			idx = len(payload)
+			busLineIndex[val.BusLine.ID] = idx
+			payload = append(payload, ArrivalBusLineV2Payload{
+				ID:        val.BusLine.ID,
+				FullName:  val.BusLine.FullName,
+				ShortName: val.BusLine.ShortName,
+				Origin:    val.BusLine.Origin,
+				Buses:     make([]ArrivingBusV2Payload, 0, 1),
+			})
+		}
+
+		eta := val.ArrivalTime
+		payload[idx].Buses = append(payload[idx].Buses, ArrivingBusV2Payload{
+			VehiclePlate: val.Bus.VehiclePlate,
+			Lat:          val.BusPosition.Lat,
+			Lng:          val.BusPosition.Lng,
+			Distance:     val.Distance,
+			ETA:          int64(eta.Seconds()),
+			ArrivesAt:    now.Add(eta).Truncate(time.Second),
+			UpdatedAt:    val.UpdatedAt,
+			Stale:        isStale(val.UpdatedAt, staleAfter),
+		})
+	}
+	return GetArrivalsV2Response{Data: payload}
+}
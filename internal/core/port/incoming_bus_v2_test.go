@@ -0,0 +1,100 @@
+package port
+
+import (
+	"testing"
+	"time"
+
+	"bus-timing/internal/aggregate"
+	"bus-timing/internal/entity"
+	"bus-timing/pkg/common"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransformArrivalsV2Response(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, 3, 1, 8, 0, 0, 500_000_000, time.UTC)
+	fresh := time.Now()
+	old := fresh.Add(-time.Hour)
+	red := entity.BusLine{ID: "44478", FullName: "Campus Loop Red (CL-R)", ShortName: "CL-R", Origin: "Kent Ridge MRT"}
+	blue := entity.BusLine{ID: "44479", FullName: "Campus Loop - Blue (CL-B)", ShortName: "CL-B", Origin: "Kent Ridge MRT"}
+
+	tests := []struct {
+		name          string
+		incomingBuses []aggregate.IncomingBus
+		staleAfter    time.Duration
+		expected      GetArrivalsV2Response
+	}{
+		{
+			name:     "happy case: no incoming bus",
+			expected: GetArrivalsV2Response{Data: []ArrivalBusLineV2Payload{}},
+		},
+		{
+			name: "happy case: buses grouped by bus line in arrival order",
+			incomingBuses: []aggregate.IncomingBus{
+				{
+					Bus:         entity.Bus{VehiclePlate: "PD1064Z"},
+					BusLine:     red,
+					BusPosition: entity.RunningBusPosition{Lat: 1.29, Lng: 103.78, CrowdLevel: common.LowCrowd},
+					Distance:    1000,
+					ArrivalTime: time.Minute,
+					UpdatedAt:   fresh,
+				},
+				{
+					Bus:         entity.Bus{VehiclePlate: "PD621Y"},
+					BusLine:     blue,
+					BusPosition: entity.RunningBusPosition{Lat: 1.30, Lng: 103.77, CrowdLevel: common.HighCrowd},
+					Distance:    2000,
+					ArrivalTime: 3 * time.Minute,
+					UpdatedAt:   fresh,
+				},
+				{
+					Bus:         entity.Bus{VehiclePlate: "PD698B"},
+					BusLine:     red,
+					BusPosition: entity.RunningBusPosition{Lat: 1.31, Lng: 103.76, CrowdLevel: common.MediumCrowd},
+					Distance:    3000,
+					ArrivalTime: 216 * time.Second,
+					UpdatedAt:   fresh,
+				},
+			},
+			expected: GetArrivalsV2Response{Data: []ArrivalBusLineV2Payload{
+				{
+					ID: red.ID, FullName: red.FullName, ShortName: red.ShortName, Origin: red.Origin,
+					Buses: []ArrivingBusV2Payload{
+						{VehiclePlate: "PD1064Z", Lat: 1.29, Lng: 103.78, Distance: 1000, ETA: 60, ArrivesAt: time.Date(2024, 3, 1, 8, 1, 0, 0, time.UTC), UpdatedAt: fresh},
+						{VehiclePlate: "PD698B", Lat: 1.31, Lng: 103.76, Distance: 3000, ETA: 216, ArrivesAt: time.Date(2024, 3, 1, 8, 3, 36, 0, time.UTC), UpdatedAt: fresh},
+					},
+				},
+				{
+					ID: blue.ID, FullName: blue.FullName, ShortName: blue.ShortName, Origin: blue.Origin,
+					Buses: []ArrivingBusV2Payload{
+						{VehiclePlate: "PD621Y", Lat: 1.30, Lng: 103.77, Distance: 2000, ETA: 180, ArrivesAt: time.Date(2024, 3, 1, 8, 3, 0, 0, time.UTC), UpdatedAt: fresh},
+					},
+				},
+			}},
+		},
+		{
+			name: "happy case: positions older than staleAfter are stale",
+			incomingBuses: []aggregate.IncomingBus{
+				{Bus: entity.Bus{VehiclePlate: "PD1064Z"}, BusLine: red, UpdatedAt: old},
+				{Bus: entity.Bus{VehiclePlate: "PD698B"}, BusLine: red, UpdatedAt: fresh},
+			},
+			staleAfter: time.Minute,
+			expected: GetArrivalsV2Response{Data: []ArrivalBusLineV2Payload{
+				{
+					ID: red.ID, FullName: red.FullName, ShortName: red.ShortName, Origin: red.Origin,
+					Buses: []ArrivingBusV2Payload{
+						{VehiclePlate: "PD1064Z", ArrivesAt: now.Truncate(time.Second), UpdatedAt: old, Stale: true},
+						{VehiclePlate: "PD698B", ArrivesAt: now.Truncate(time.Second), UpdatedAt: fresh},
+					},
+				},
+			}},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(tt *testing.T) {
+			assert.Equal(tt, test.expected, transformArrivalsV2Response(test.incomingBuses, test.staleAfter, now))
+		})
+	}
+}
@@ -0,0 +1,138 @@
+package port
+
+import (
+	"context"
+	"net/http"
+
+	"bus-timing/internal/aggregate"
+	"bus-timing/internal/entity"
+	"bus-timing/pkg/apperror"
+
+	"github.com/gin-gonic/gin"
+)
+
+type JourneyPort struct {
+	JourneyPlanner interface {
+		PlanJourney(ctx context.Context, from, to aggregate.JourneyPlace, maxTransfers int) ([]aggregate.Journey, error)
+	}
+	MaxTransfers int
+}
+
+type GetJourneysResponse struct {
+	Payload []JourneyPayload `json:"payload"`
+	Status  int              `json:"status"`
+}
+
+// JourneyPayload durations are in seconds
+type JourneyPayload struct {
+	Duration  int64               `json:"duration"`
+	Transfers int                 `json:"transfers"`
+	Legs      []JourneyLegPayload `json:"legs"`
+}
+
+type JourneyLegPayload struct {
+	Mode     string                `json:"mode"`
+	From     JourneyPlacePayload   `json:"from"`
+	To       JourneyPlacePayload   `json:"to"`
+	BusLine  *SearchBusLinePayload `json:"busLine,omitempty"`
+	Distance float64               `json:"distance"`
+	Duration int64                 `json:"duration"`
+	Live     bool                  `json:"live"`
+}
+
+// JourneyPlacePayload has no ID and name for a coordinate given in the request
+type JourneyPlacePayload struct {
+	ID   string  `json:"id,omitempty"`
+	Lat  float64 `json:"lat"`
+	Lng  float64 `json:"lng"`
+	Name string  `json:"name,omitempty"`
+}
+
+// GetJourneys plans from/to bus stop IDs, or fromLat/fromLng and toLat/toLng coordinates.
+func (port *JourneyPort) GetJourneys(ctx *gin.Context) {
+	from, err := queryJourneyPlace(ctx, "from")
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	to, err := queryJourneyPlace(ctx, "to")
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	maxTransfers, err := queryInt(ctx, "maxTransfers", port.MaxTransfers)
+	if err != nil || maxTransfers < 0 || maxTransfers > port.MaxTransfers {
+		ctx.Error(apperror.InvalidInput("invalid maxTransfers: %s", ctx.Query("maxTransfers")))
+		return
+	}
+
+	journeys, err := port.JourneyPlanner.PlanJourney(ctx, from, to, maxTransfers)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, transformJourneysResponse(journeys))
+}
+
+// queryJourneyPlace reads a bus stop ID from the key parameter, or a coordinate from key+"Lat" and key+"Lng"
+func queryJourneyPlace(ctx *gin.Context, key string) (aggregate.JourneyPlace, error) {
+	if busStopID := ctx.Query(key); busStopID != "" {
+		return aggregate.JourneyPlace{BusStopID: busStopID}, nil
+	}
+
+	latKey, lngKey := key+"Lat", key+"Lng"
+	if ctx.Query(latKey) == "" && ctx.Query(lngKey) == "" {
+		return aggregate.JourneyPlace{}, apperror.InvalidInput("missing %s bus stop or coordinate", key)
+	}
+	place, err := parseLatLng(ctx, latKey, lngKey)
+	if err != nil {
+		return aggregate.JourneyPlace{}, err
+	}
+	return aggregate.JourneyPlace{Location: place}, nil
+}
+
+func transformJourneysResponse(journeys []aggregate.Journey) GetJourneysResponse {
+	payload := make([]JourneyPayload, 0, len(journeys))
+	for _, journey := range journeys {
+		legs := make([]JourneyLegPayload, 0, len(journey.Legs))
+		for _, leg := range journey.Legs {
+			legPayload := JourneyLegPayload{
+				Mode:     string(leg.Mode),
+				From:     toJourneyPlacePayload(leg.From),
+				To:       toJourneyPlacePayload(leg.To),
+				Distance: leg.Distance,
+				Duration: int64(leg.Duration.Seconds()),
+				Live:     leg.Live,
+			}
+			if leg.BusLine.ID != "" {
+				legPayload.BusLine = &SearchBusLinePayload{
+					ID:        leg.BusLine.ID,
+					FullName:  leg.BusLine.FullName,
+					ShortName: leg.BusLine.ShortName,
+					Origin:    leg.BusLine.Origin,
+				}
+			}
+			legs = append(legs, legPayload)
+		}
+
+		payload = append(payload, JourneyPayload{
+			Duration:  int64(journey.Duration.Seconds()),
+			Transfers: journey.Transfers,
+			Legs:      legs,
+		})
+	}
+	return GetJourneysResponse{
+		Payload: payload,
+		Status:  statusSuccess,
+	}
+}
+
+func toJourneyPlacePayload(busStop entity.BusStop) JourneyPlacePayload {
+	return JourneyPlacePayload{
+		ID:   busStop.ID,
+		Name: busStop.Name,
+		Lat:  busStop.Lat,
+		Lng:  busStop.Lng,
+	}
+}
@@ -0,0 +1,61 @@
+package port
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"bus-timing/internal/aggregate"
+	"bus-timing/pkg/middlewares/errorhandler"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockJourneyPlanner struct {
+	calls int
+}
+
+func (m *mockJourneyPlanner) PlanJourney(ctx context.Context, from, to aggregate.JourneyPlace, maxTransfers int) ([]aggregate.Journey, error) {
+	m.calls++
+	return nil, nil
+}
+
+func TestJourneyPort_GetJourneys(t *testing.T) {
+	t.Parallel()
+
+	gin.SetMode(gin.TestMode)
+	serve := func(planner *mockJourneyPlanner, query string) *httptest.ResponseRecorder {
+		port := &JourneyPort{JourneyPlanner: planner, MaxTransfers: 2}
+		router := gin.New()
+		router.Use(errorhandler.ErrorHandlerMiddleware())
+		router.GET("/api/journeys", port.GetJourneys)
+
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/api/journeys?"+query, nil))
+		return recorder
+	}
+
+	t.Run("happy case", func(tt *testing.T) {
+		planner := &mockJourneyPlanner{}
+		recorder := serve(planner, "fromLat=1.29&fromLng=103.78&to=377906")
+		assert.Equal(tt, http.StatusOK, recorder.Code)
+		assert.Equal(tt, 1, planner.calls)
+	})
+
+	t.Run("bad case: invalid coordinates", func(tt *testing.T) {
+		for _, query := range []string{
+			"fromLat=NaN&fromLng=0&to=377906",
+			"from=377906&toLat=1.29&toLng=NaN",
+			"fromLat=Inf&fromLng=103.78&to=377906",
+			"fromLat=1.29&fromLng=181&to=377906",
+			"to=377906",
+		} {
+			planner := &mockJourneyPlanner{}
+			recorder := serve(planner, query)
+			assert.Equal(tt, http.StatusBadRequest, recorder.Code, query)
+			assert.Zero(tt, planner.calls, query)
+		}
+	})
+}
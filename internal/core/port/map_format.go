@@ -0,0 +1,158 @@
+package port
+
+import (
+	"net/http"
+	"time"
+
+	"bus-timing/internal/aggregate"
+	"bus-timing/pkg/apperror"
+	"bus-timing/pkg/location"
+
+	"github.com/gin-gonic/gin"
+)
+
+// formats of the bus line and bus position routes for map SDKs
+const (
+	formatJSON     = "json"
+	formatGeoJSON  = "geojson"
+	formatPolyline = "polyline"
+
+	geoJSONContentType = "application/geo+json"
+)
+
+// BusLinePolylinePayload has the path of the bus line as a Google encoded polyline
+type BusLinePolylinePayload struct {
+	ID        string    `json:"id"`
+	FullName  string    `json:"fullName"`
+	ShortName string    `json:"shortName"`
+	Origin    string    `json:"origin"`
+	BusStops  []BusStop `json:"busStops,omitempty"`
+	Path      string    `json:"path,omitempty"`
+}
+
+// BusPositionsPolylinePayload has the positions of the buses as a Google encoded polyline,
+// in the order of VehiclePlates
+type BusPositionsPolylinePayload struct {
+	VehiclePlates []string `json:"vehiclePlates"`
+	Positions     string   `json:"positions"`
+}
+
+type GetBusLinePolylineResponse struct {
+	Payload []BusLinePolylinePayload `json:"payload"`
+	Status  int                      `json:"status"`
+}
+
+type GetBusPositionPolylineResponse struct {
+	Payload BusPositionsPolylinePayload `json:"payload"`
+	Status  int                         `json:"status"`
+}
+
+type GetBusLinesPolylineV2Response struct {
+	Data []BusLinePolylinePayload `json:"data"`
+}
+
+type GetBusPositionsPolylineV2Response struct {
+	Data BusPositionsPolylinePayload `json:"data"`
+}
+
+// queryFormat reads the format parameter, json by default
+func queryFormat(ctx *gin.Context) (string, error) {
+	switch format := ctx.DefaultQuery("format", formatJSON); format {
+	case formatJSON, formatGeoJSON, formatPolyline:
+		return format, nil
+	default:
+		return "", apperror.InvalidInput("invalid format: %s", format)
+	}
+}
+
+func writeGeoJSON(ctx *gin.Context, collection location.FeatureCollection) {
+	ctx.Header("Content-Type", geoJSONContentType)
+	ctx.JSON(http.StatusOK, collection)
+}
+
+// busLinesFeatureCollection has a LineString per bus line path and a Point per bus stop of each bus line
+func busLinesFeatureCollection(busLineBusStops []aggregate.BusLineBusStop) location.FeatureCollection {
+	features := make([]location.Feature, 0)
+	for _, val := range busLineBusStops {
+		if len(val.BusLine.BusLinePaths) > 0 {
+			features = append(features, location.NewFeature(location.NewLineString(busLinePath(val)), map[string]interface{}{
+				"kind":      "busLine",
+				"id":        val.BusLine.ID,
+				"fullName":  val.BusLine.FullName,
+				"shortName": val.BusLine.ShortName,
+				"origin":    val.BusLine.Origin,
+			}))
+		}
+		for _, busStop := range val.BusStops {
+			features = append(features, location.NewFeature(location.NewPoint(location.Location{Lat: busStop.Lat, Lng: busStop.Lng}), map[string]interface{}{
+				"kind":      "busStop",
+				"id":        busStop.ID,
+				"name":      busStop.Name,
+				"busLineID": val.BusLine.ID,
+			}))
+		}
+	}
+	return location.NewFeatureCollection(features)
+}
+
+// busPositionsFeatureCollection has a Point per bus
+func busPositionsFeatureCollection(busPositions []aggregate.BusPosition, staleAfter time.Duration) location.FeatureCollection {
+	features := make([]location.Feature, 0, len(busPositions))
+	for _, val := range busPositions {
+		point := location.Location{Lat: val.RunningBusPosition.Lat, Lng: val.RunningBusPosition.Lng}
+		features = append(features, location.NewFeature(location.NewPoint(point), map[string]interface{}{
+			"kind":         "bus",
+			"vehiclePlate": val.Bus.VehiclePlate,
+			"bearing":      val.Bus.Bearing,
+			"crowdLevel":   string(val.RunningBusPosition.CrowdLevel),
+			"updatedAt":    val.UpdatedAt,
+			"stale":        isStale(val.UpdatedAt, staleAfter),
+		}))
+	}
+	return location.NewFeatureCollection(features)
+}
+
+func transformBusLinesPolyline(busLineBusStops []aggregate.BusLineBusStop) []BusLinePolylinePayload {
+	payload := make([]BusLinePolylinePayload, 0, len(busLineBusStops))
+	for _, val := range busLineBusStops {
+		busStops := make([]BusStop, 0, len(val.BusStops))
+		for _, busStop := range val.BusStops {
+			busStops = append(busStops, BusStop{
+				ID:   busStop.ID,
+				Name: busStop.Name,
+				Lat:  busStop.Lat,
+				Lng:  busStop.Lng,
+			})
+		}
+		payload = append(payload, BusLinePolylinePayload{
+			ID:        val.BusLine.ID,
+			FullName:  val.BusLine.FullName,
+			ShortName: val.BusLine.ShortName,
+			Origin:    val.BusLine.Origin,
+			BusStops:  busStops,
+			Path:      location.EncodePolyline(busLinePath(val)),
+		})
+	}
+	return payload
+}
+
+func transformBusPositionsPolyline(busPositions []aggregate.BusPosition) BusPositionsPolylinePayload {
+	vehiclePlates := make([]string, 0, len(busPositions))
+	positions := make([]location.Location, 0, len(busPositions))
+	for _, val := range busPositions {
+		vehiclePlates = append(vehiclePlates, val.Bus.VehiclePlate)
+		positions = append(positions, location.Location{Lat: val.RunningBusPosition.Lat, Lng: val.RunningBusPosition.Lng})
+	}
+	return BusPositionsPolylinePayload{
+		VehiclePlates: vehiclePlates,
+		Positions:     location.EncodePolyline(positions),
+	}
+}
+
+func busLinePath(busLineBusStop aggregate.BusLineBusStop) []location.Location {
+	path := make([]location.Location, 0, len(busLineBusStop.BusLine.BusLinePaths))
+	for _, val := range busLineBusStop.BusLine.BusLinePaths {
+		path = append(path, location.Location{Lat: val.Lat, Lng: val.Lng})
+	}
+	return path
+}
@@ -0,0 +1,441 @@
+package port
+
+import (
+	"io/fs"
+	"net/http"
+	"strings"
+	"sync"
+
+	"bus-timing/pkg/location"
+	"bus-timing/pkg/middlewares/apikey"
+	"bus-timing/pkg/middlewares/errorhandler"
+	"bus-timing/pkg/openapi"
+
+	"github.com/gin-gonic/gin"
+	swaggerFiles "github.com/swaggo/files/v2"
+)
+
+var (
+	openAPIOnce     sync.Once
+	openAPIDocument *openapi.Document
+)
+
+// OpenAPIDocument describes every route of the HTTP API, schemas are derived from the response types of the ports.
+func OpenAPIDocument() *openapi.Document {
+	openAPIOnce.Do(func() {
+		openAPIDocument = newOpenAPIDocument()
+	})
+	return openAPIDocument
+}
+
+func OpenAPI(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, OpenAPIDocument())
+}
+
+// swaggerInitializer replaces the one of the Swagger UI bundle to load the API document
+const swaggerInitializer = `window.onload = function() {
+  window.ui = SwaggerUIBundle({
+    url: "/openapi.json",
+    dom_id: '#swagger-ui',
+    deepLinking: true,
+    presets: [SwaggerUIBundle.presets.apis, SwaggerUIStandalonePreset],
+    plugins: [SwaggerUIBundle.plugins.DownloadUrl],
+    layout: "StandaloneLayout"
+  });
+};
+`
+
+// SwaggerUI serves the bundled Swagger UI under a *filepath route.
+func SwaggerUI(ctx *gin.Context) {
+	filePath := strings.TrimPrefix(ctx.Param("filepath"), "/")
+	switch filePath {
+	case "", "index.html":
+		// served as is, the file server redirects index.html to its directory
+		index, err := fs.ReadFile(swaggerFiles.FS, "index.html")
+		if err != nil {
+			ctx.Error(err)
+			return
+		}
+		ctx.Data(http.StatusOK, "text/html; charset=utf-8", index)
+		return
+	case "swagger-initializer.js":
+		ctx.Data(http.StatusOK, "application/javascript", []byte(swaggerInitializer))
+		return
+	}
+	ctx.FileFromFS(filePath, http.FS(swaggerFiles.FS))
+}
+
+func newOpenAPIDocument() *openapi.Document {
+	doc := openapi.NewDocument(openapi.Info{
+		Title:       "Bus Timing API",
+		Description: "Bus lines, live bus positions and arrival estimations.",
+		Version:     "1.0.0",
+	})
+
+	errorSchema := jsonContent(doc.SchemaOf(errorhandler.ErrorResponse{}))
+	errorResponses := map[string]openapi.Response{
+		"400": {Description: "Invalid input", Content: errorSchema},
+		"401": {Description: "Missing or invalid token", Content: errorSchema},
+		"403": {Description: "The token does not have the required role", Content: errorSchema},
+		"404": {Description: "Not found", Content: errorSchema},
+		"429": {Description: "Rate limit or daily quota exceeded, retry after the Retry-After header seconds", Content: errorSchema},
+		"500": {Description: "Internal error", Content: errorSchema},
+		"502": {Description: "Upstream unavailable", Content: errorSchema},
+		"504": {Description: "Timeout", Content: errorSchema},
+	}
+	// withErrors adds the responses of the error handler middleware
+	withErrors := func(responses map[string]openapi.Response) map[string]openapi.Response {
+		for code, response := range errorResponses {
+			responses[code] = response
+		}
+		return responses
+	}
+	ok := func(description string, body interface{}) map[string]openapi.Response {
+		return withErrors(map[string]openapi.Response{
+			"200": {Description: description, Content: jsonContent(doc.SchemaOf(body))},
+		})
+	}
+	// formatted documents the json (default) and polyline bodies, and the geojson FeatureCollection
+	formatted := func(description string, body, polylineBody interface{}) map[string]openapi.Response {
+		return withErrors(map[string]openapi.Response{
+			"200": {Description: description, Content: map[string]openapi.MediaType{
+				"application/json": {Schema: &openapi.Schema{OneOf: []*openapi.Schema{doc.SchemaOf(body), doc.SchemaOf(polylineBody)}}},
+				geoJSONContentType: {Schema: doc.SchemaOf(location.FeatureCollection{})},
+			}},
+		})
+	}
+	format := queryParameter("format", "Response format, geojson answers a FeatureCollection and polyline Google encoded polylines", &openapi.Schema{Type: "string", Enum: []string{formatJSON, formatGeoJSON, formatPolyline}, Default: formatJSON})
+	busLineID := pathParameter("busLineID", "Bus line ID")
+	busStopID := pathParameter("busStopID", "Bus stop ID")
+	alertID := pathParameter("alertID", "Arrival alert ID")
+	limit := func(def, max int) openapi.Parameter {
+		return queryParameter("limit", "Maximum number of results", integerSchema(def, 1, max))
+	}
+
+	doc.Components.SecuritySchemes = map[string]*openapi.SecurityScheme{
+		"bearerAuth": {Type: "http", Scheme: "bearer", BearerFormat: "JWT", Description: "Required on the route groups listed in the auth.groups configuration"},
+		"apiKey":     {Type: "apiKey", In: "header", Name: apikey.Header, Description: "Required on the route groups listed in the api_keys.groups configuration"},
+	}
+	doc.Security = []openapi.SecurityRequirement{{}, {"bearerAuth": {}}, {"apiKey": {}}, {"bearerAuth": {}, "apiKey": {}}}
+	tokenSecurity := []openapi.SecurityRequirement{{"bearerAuth": {}}}
+
+	doc.Add(http.MethodGet, "/health", openapi.Operation{
+		Summary:   "Health check",
+		Tags:      []string{"health"},
+		Responses: map[string]openapi.Response{"200": {Description: "Service is up", Content: jsonContent(doc.SchemaOf(HealthCheckResponse{}))}},
+	})
+	doc.Add(http.MethodGet, "/openapi.json", openapi.Operation{
+		Summary:   "This document",
+		Tags:      []string{"docs"},
+		Responses: map[string]openapi.Response{"200": {Description: "OpenAPI document", Content: jsonContent(&openapi.Schema{Type: "object"})}},
+	})
+
+	historyWindow := []openapi.Parameter{
+		busLineID,
+		queryParameter("from", "RFC 3339 start, an hour before `to` by default", &openapi.Schema{Type: "string", Format: "date-time"}),
+		queryParameter("to", "RFC 3339 end, now by default", &openapi.Schema{Type: "string", Format: "date-time"}),
+	}
+	busLineOptions := []openapi.Parameter{
+		queryParameter("ids", "Comma separated bus line IDs, every bus line by default", &openapi.Schema{Type: "string"}),
+		queryParameter("include", "Comma separated fields among busStops and path, both by default", &openapi.Schema{Type: "string"}),
+		queryParameter("tolerance", "Simplify paths with Douglas-Peucker, dropping points closer than tolerance meters", numberSchema(0, maxPathTolerance)),
+		format,
+	}
+	doc.Add(http.MethodGet, "/tiles/{z}/{x}/{y}", openapi.Operation{
+		Summary:     "Mapbox Vector Tile of the bus network",
+		Description: "The busLines layer has the paths of the bus lines, simplified to a pixel at the zoom, and the busStops layer the bus stops from a configured zoom.",
+		Tags:        []string{"tiles"},
+		Parameters: []openapi.Parameter{
+			pathParameter("z", "Zoom"),
+			pathParameter("x", "Tile column"),
+			pathParameter("y", "Tile row followed by .mvt, e.g. 16262.mvt"),
+		},
+		Responses: withErrors(map[string]openapi.Response{
+			"200": {Description: "Vector tile, empty when no bus line crosses it", Content: map[string]openapi.MediaType{vectorTileContentType: {Schema: &openapi.Schema{Type: "string", Format: "binary"}}}},
+		}),
+	})
+	doc.Add(http.MethodGet, "/api/busLines", openapi.Operation{
+		Summary:     "Bus lines with their bus stops and paths",
+		Description: "Deprecated, use /api/v2/busLines.",
+		Tags:        []string{"bus lines"},
+		Parameters:  busLineOptions,
+		Responses:   formatted("Bus lines", GetBusLineResponse{}, GetBusLinePolylineResponse{}),
+		Deprecated:  true,
+	})
+	doc.Add(http.MethodGet, "/api/busPosition/{busLineID}", openapi.Operation{
+		Summary:     "Running buses of a bus line",
+		Description: "Deprecated, use /api/v2/busLines/{busLineID}/positions.",
+		Tags:        []string{"bus positions"},
+		Parameters:  []openapi.Parameter{busLineID, format},
+		Responses:   formatted("Running buses", GetBusPositionResponse{}, GetBusPositionPolylineResponse{}),
+		Deprecated:  true,
+	})
+	doc.Add(http.MethodGet, "/api/busPosition/{busLineID}/history", openapi.Operation{
+		Summary:     "Recorded positions of the buses of a bus line",
+		Description: "Deprecated, use /api/v2/busLines/{busLineID}/history.",
+		Tags:        []string{"bus positions"},
+		Parameters:  historyWindow,
+		Responses:   ok("Bus tracks", GetBusPositionHistoryResponse{}),
+		Deprecated:  true,
+	})
+	doc.Add(http.MethodGet, "/api/busPosition/{busLineID}/stream", openapi.Operation{
+		Summary:     "Server-Sent Events of the running buses of a bus line",
+		Description: "Every `busPosition` event carries a GetBusPositionResponse. Clients resume with the Last-Event-ID header.",
+		Tags:        []string{"bus positions"},
+		Parameters: []openapi.Parameter{
+			busLineID,
+			{Name: "Last-Event-ID", In: "header", Description: "ID of the last event received", Schema: &openapi.Schema{Type: "integer", Format: "int64"}},
+		},
+		Responses: withErrors(map[string]openapi.Response{
+			"200": {Description: "Event stream", Content: map[string]openapi.MediaType{"text/event-stream": {Schema: doc.SchemaOf(GetBusPositionResponse{})}}},
+		}),
+	})
+	doc.Add(http.MethodGet, "/api/busStop/{busStopID}", openapi.Operation{
+		Summary:     "Incoming buses of a bus stop",
+		Description: "Deprecated, use /api/v2/busStops/{busStopID}/arrivals.",
+		Tags:        []string{"arrivals"},
+		Parameters:  []openapi.Parameter{busStopID},
+		Responses:   ok("Incoming buses", IncomingBusResponse{}),
+		Deprecated:  true,
+	})
+	doc.Add(http.MethodGet, "/api/busStops/arrivals/ws", openapi.Operation{
+		Summary:     "WebSocket of bus stop arrivals",
+		Description: "Clients send ArrivalMessage to subscribe and unsubscribe, the server sends ArrivalUpdate.",
+		Tags:        []string{"arrivals"},
+		Responses: map[string]openapi.Response{
+			"101": {Description: "Switching to the WebSocket protocol", Content: jsonContent(doc.SchemaOf(ArrivalUpdate{}))},
+		},
+	})
+	doc.SchemaOf(ArrivalMessage{})
+	doc.Add(http.MethodGet, "/api/busStops", openapi.Operation{
+		Summary: "Page of bus stops",
+		Tags:    []string{"bus stops"},
+		Parameters: []openapi.Parameter{
+			queryParameter("offset", "Number of bus stops to skip", integerSchema(0, 0, 0)),
+			limit(defaultBusStopLimit, maxBusStopLimit),
+		},
+		Responses: ok("Bus stops", GetBusStopsResponse{}),
+	})
+	doc.Add(http.MethodGet, "/api/busStops/nearby", openapi.Operation{
+		Summary: "Bus stops around a coordinate, nearest first",
+		Tags:    []string{"bus stops"},
+		Parameters: []openapi.Parameter{
+			requiredQueryParameter("lat", "Latitude", &openapi.Schema{Type: "number", Format: "double"}),
+			requiredQueryParameter("lng", "Longitude", &openapi.Schema{Type: "number", Format: "double"}),
+			queryParameter("radius", "Radius in meters", &openapi.Schema{Type: "number", Format: "double", Default: defaultNearbyRadius}),
+			limit(defaultNearbyLimit, maxBusStopLimit),
+		},
+		Responses: ok("Nearby bus stops", GetNearbyBusStopsResponse{}),
+	})
+	doc.Add(http.MethodGet, "/api/busStops/{busStopID}", openapi.Operation{
+		Summary:    "Bus stop with the bus lines passing it",
+		Tags:       []string{"bus stops"},
+		Parameters: []openapi.Parameter{busStopID},
+		Responses:  ok("Bus stop", GetBusStopResponse{}),
+	})
+
+	doc.Add(http.MethodPost, "/api/auth/token", openapi.Operation{
+		Summary:     "Exchange client credentials for tokens",
+		Description: "The access token authorizes requests with an `Authorization: Bearer <token>` header, the refresh token gets new tokens.",
+		Tags:        []string{"auth"},
+		RequestBody: &openapi.RequestBody{Required: true, Content: jsonContent(doc.SchemaOf(TokenRequest{}))},
+		Responses:   ok("Tokens", GetTokenResponse{}),
+	})
+	doc.Add(http.MethodPost, "/api/auth/refresh", openapi.Operation{
+		Summary:     "Exchange a refresh token for new tokens",
+		Tags:        []string{"auth"},
+		RequestBody: &openapi.RequestBody{Required: true, Content: jsonContent(doc.SchemaOf(RefreshTokenRequest{}))},
+		Responses:   ok("Tokens", GetTokenResponse{}),
+	})
+
+	doc.Add(http.MethodPost, "/api/admin/apiKeys", openapi.Operation{
+		Summary:     "Issue an API key",
+		Description: "Requires the admin role. The key is only answered here, limits left out take the configured defaults.",
+		Tags:        []string{"admin"},
+		Security:    tokenSecurity,
+		RequestBody: &openapi.RequestBody{Required: true, Content: jsonContent(doc.SchemaOf(APIKeyRequest{}))},
+		Responses: withErrors(map[string]openapi.Response{
+			"201": {Description: "Issued API key", Content: jsonContent(doc.SchemaOf(GetAPIKeyResponse{}))},
+		}),
+	})
+	doc.Add(http.MethodGet, "/api/admin/apiKeys", openapi.Operation{
+		Summary:     "API keys",
+		Description: "Requires the operator or admin role.",
+		Tags:        []string{"admin"},
+		Security:    tokenSecurity,
+		Responses:   ok("API keys", GetAPIKeysResponse{}),
+	})
+	doc.Add(http.MethodDelete, "/api/admin/apiKeys/{apiKeyID}", openapi.Operation{
+		Summary:     "Revoke an API key",
+		Description: "Requires the admin role.",
+		Tags:        []string{"admin"},
+		Security:    tokenSecurity,
+		Parameters:  []openapi.Parameter{pathParameter("apiKeyID", "API key ID")},
+		Responses: withErrors(map[string]openapi.Response{
+			"204": {Description: "Revoked"},
+		}),
+	})
+	doc.Add(http.MethodGet, "/api/admin/usage", openapi.Operation{
+		Summary:     "Usage of the API keys",
+		Description: "Requires the operator or admin role. requests counts the requests within the rate limit, quotaExceeded of them were refused.",
+		Tags:        []string{"admin"},
+		Security:    tokenSecurity,
+		Parameters:  []openapi.Parameter{queryParameter("day", "YYYY-MM-DD UTC day, today by default", &openapi.Schema{Type: "string", Format: "date"})},
+		Responses:   ok("Usage per API key", GetAPIKeyUsagesResponse{}),
+	})
+
+	doc.Add(http.MethodPost, "/api/admin/catalogue/refresh", openapi.Operation{
+		Summary:     "Reload the bus lines and bus stops",
+		Description: "Requires the operator or admin role. Search, journeys and tiles are rebuilt from the reloaded catalogue.",
+		Tags:        []string{"admin"},
+		Security:    tokenSecurity,
+		Responses: withErrors(map[string]openapi.Response{
+			"204": {Description: "Reloaded"},
+		}),
+	})
+
+	alertBody := &openapi.RequestBody{Required: true, Content: jsonContent(doc.SchemaOf(ArrivalAlertRequest{}))}
+	doc.Add(http.MethodPost, "/api/alerts", openapi.Operation{
+		Summary:     "Create an arrival alert",
+		Description: "Requires the partner role. The callback receives a POST signed with HMAC-SHA256 in the X-Signature header once per bus coming within threshold seconds.",
+		Tags:        []string{"alerts"},
+		Security:    tokenSecurity,
+		RequestBody: alertBody,
+		Responses: withErrors(map[string]openapi.Response{
+			"201": {Description: "Created alert", Content: jsonContent(doc.SchemaOf(GetArrivalAlertResponse{}))},
+		}),
+	})
+	doc.Add(http.MethodGet, "/api/alerts", openapi.Operation{
+		Summary:     "Arrival alerts",
+		Description: "Requires the partner role. Answers the alerts created with a token of the same subject.",
+		Tags:        []string{"alerts"},
+		Security:    tokenSecurity,
+		Responses:   ok("Arrival alerts", GetArrivalAlertsResponse{}),
+	})
+	doc.Add(http.MethodGet, "/api/alerts/{alertID}", openapi.Operation{
+		Summary:     "Arrival alert",
+		Description: "Requires the partner role. Alerts created with a token of another subject are not found.",
+		Tags:        []string{"alerts"},
+		Security:    tokenSecurity,
+		Parameters:  []openapi.Parameter{alertID},
+		Responses:   ok("Arrival alert", GetArrivalAlertResponse{}),
+	})
+	doc.Add(http.MethodPut, "/api/alerts/{alertID}", openapi.Operation{
+		Summary:     "Replace an arrival alert",
+		Description: "Requires the partner role. Alerts created with a token of another subject are not found.",
+		Tags:        []string{"alerts"},
+		Security:    tokenSecurity,
+		Parameters:  []openapi.Parameter{alertID},
+		RequestBody: alertBody,
+		Responses:   ok("Updated alert", GetArrivalAlertResponse{}),
+	})
+	doc.Add(http.MethodDelete, "/api/alerts/{alertID}", openapi.Operation{
+		Summary:     "Delete an arrival alert",
+		Description: "Requires the partner role. Alerts created with a token of another subject are not found.",
+		Tags:        []string{"alerts"},
+		Security:    tokenSecurity,
+		Parameters:  []openapi.Parameter{alertID},
+		Responses: withErrors(map[string]openapi.Response{
+			"204": {Description: "Deleted"},
+		}),
+	})
+
+	doc.Add(http.MethodGet, "/api/search", openapi.Operation{
+		Summary: "Typo tolerant search over bus stops and bus lines",
+		Tags:    []string{"search"},
+		Parameters: []openapi.Parameter{
+			requiredQueryParameter("q", "Search query", &openapi.Schema{Type: "string"}),
+			queryParameter("type", "Only return one type of result", &openapi.Schema{Type: "string", Enum: []string{"busStop", "busLine"}}),
+			limit(defaultSearchLimit, maxSearchLimit),
+		},
+		Responses: ok("Search results, best first", SearchResponse{}),
+	})
+
+	journeyParameters := make([]openapi.Parameter, 0, 7)
+	for _, key := range []string{"from", "to"} {
+		journeyParameters = append(journeyParameters,
+			queryParameter(key, "Bus stop ID, or use "+key+"Lat and "+key+"Lng", &openapi.Schema{Type: "string"}),
+			queryParameter(key+"Lat", "Latitude", &openapi.Schema{Type: "number", Format: "double"}),
+			queryParameter(key+"Lng", "Longitude", &openapi.Schema{Type: "number", Format: "double"}),
+		)
+	}
+	journeyParameters = append(journeyParameters, queryParameter("maxTransfers", "Maximum number of transfers", &openapi.Schema{Type: "integer", Format: "int32"}))
+	doc.Add(http.MethodGet, "/api/journeys", openapi.Operation{
+		Summary:    "Journeys between two bus stops or coordinates",
+		Tags:       []string{"journeys"},
+		Parameters: journeyParameters,
+		Responses:  ok("Journeys, fastest first", GetJourneysResponse{}),
+	})
+
+	doc.Add(http.MethodPost, "/api/graphql", openapi.Operation{
+		Summary:     "GraphQL query over bus lines, bus stops, buses and arrivals",
+		Tags:        []string{"graphql"},
+		RequestBody: &openapi.RequestBody{Required: true, Content: jsonContent(doc.SchemaOf(GraphQLRequest{}))},
+		Responses: withErrors(map[string]openapi.Response{
+			"200": {Description: "GraphQL response with data and errors", Content: jsonContent(&openapi.Schema{Type: "object"})},
+		}),
+	})
+
+	doc.Add(http.MethodGet, "/api/v2/busLines", openapi.Operation{
+		Summary:    "Bus lines with their bus stops and paths",
+		Tags:       []string{"v2"},
+		Parameters: busLineOptions,
+		Responses:  formatted("Bus lines", GetBusLinesV2Response{}, GetBusLinesPolylineV2Response{}),
+	})
+	doc.Add(http.MethodGet, "/api/v2/busLines/{busLineID}/positions", openapi.Operation{
+		Summary:     "Running buses of a bus line",
+		Description: "Positions fetched longer ago than the configured age are flagged stale.",
+		Tags:        []string{"v2"},
+		Parameters:  []openapi.Parameter{busLineID, format},
+		Responses:   formatted("Running buses", GetBusPositionsV2Response{}, GetBusPositionsPolylineV2Response{}),
+	})
+	doc.Add(http.MethodGet, "/api/v2/busLines/{busLineID}/history", openapi.Operation{
+		Summary:    "Recorded positions of the buses of a bus line",
+		Tags:       []string{"v2"},
+		Parameters: historyWindow,
+		Responses:  ok("Bus tracks", GetBusPositionHistoryV2Response{}),
+	})
+	doc.Add(http.MethodGet, "/api/v2/busStops/{busStopID}/arrivals", openapi.Operation{
+		Summary:     "Incoming buses of a bus stop by bus line",
+		Description: "eta is in seconds, distance in meters.",
+		Tags:        []string{"v2"},
+		Parameters:  []openapi.Parameter{busStopID},
+		Responses:   ok("Incoming buses", GetArrivalsV2Response{}),
+	})
+
+	return doc
+}
+
+func jsonContent(schema *openapi.Schema) map[string]openapi.MediaType {
+	return map[string]openapi.MediaType{"application/json": {Schema: schema}}
+}
+
+func pathParameter(name, description string) openapi.Parameter {
+	return openapi.Parameter{Name: name, In: "path", Description: description, Required: true, Schema: &openapi.Schema{Type: "string"}}
+}
+
+func queryParameter(name, description string, schema *openapi.Schema) openapi.Parameter {
+	return openapi.Parameter{Name: name, In: "query", Description: description, Schema: schema}
+}
+
+func requiredQueryParameter(name, description string, schema *openapi.Schema) openapi.Parameter {
+	parameter := queryParameter(name, description, schema)
+	parameter.Required = true
+	return parameter
+}
+
+// integerSchema has no maximum when max is 0
+func numberSchema(min, max float64) *openapi.Schema {
+	return &openapi.Schema{Type: "number", Format: "double", Minimum: &min, Maximum: &max}
+}
+
+func integerSchema(def, min, max int) *openapi.Schema {
+	schema := &openapi.Schema{Type: "integer", Format: "int32", Default: def}
+	minimum := float64(min)
+	schema.Minimum = &minimum
+	if max > 0 {
+		maximum := float64(max)
+		schema.Maximum = &maximum
+	}
+	return schema
+}
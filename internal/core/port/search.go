@@ -0,0 +1,100 @@
+package port
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"bus-timing/internal/aggregate"
+	"bus-timing/pkg/apperror"
+	"bus-timing/pkg/common"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultSearchLimit = 10
+	maxSearchLimit     = 100
+)
+
+type SearchPort struct {
+	SearchService interface {
+		Search(ctx context.Context, query string, resultType common.SearchResultType, limit int) ([]aggregate.SearchResult, error)
+	}
+}
+
+type SearchResponse struct {
+	Payload []SearchResultPayload `json:"payload"`
+	Status  int                   `json:"status"`
+}
+
+type SearchResultPayload struct {
+	Type    string                `json:"type"`
+	Score   float64               `json:"score"`
+	BusStop *BusStop              `json:"busStop,omitempty"`
+	BusLine *SearchBusLinePayload `json:"busLine,omitempty"`
+}
+
+type SearchBusLinePayload struct {
+	ID        string `json:"id"`
+	FullName  string `json:"fullName"`
+	ShortName string `json:"shortName"`
+	Origin    string `json:"origin"`
+}
+
+func (port *SearchPort) Search(ctx *gin.Context) {
+	query := strings.TrimSpace(ctx.Query("q"))
+	if query == "" {
+		ctx.Error(apperror.InvalidInput("missing search query"))
+		return
+	}
+	resultType := common.SearchResultType(ctx.Query("type"))
+	if resultType != "" && resultType != common.SearchResultBusStop && resultType != common.SearchResultBusLine {
+		ctx.Error(apperror.InvalidInput("invalid type: %s", resultType))
+		return
+	}
+	limit, err := queryInt(ctx, "limit", defaultSearchLimit)
+	if err != nil || limit <= 0 || limit > maxSearchLimit {
+		ctx.Error(apperror.InvalidInput("invalid limit: %s", ctx.Query("limit")))
+		return
+	}
+
+	results, err := port.SearchService.Search(ctx, query, resultType, limit)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, transformSearchResponse(results))
+}
+
+func transformSearchResponse(results []aggregate.SearchResult) SearchResponse {
+	payload := make([]SearchResultPayload, 0, len(results))
+	for _, val := range results {
+		result := SearchResultPayload{
+			Type:  string(val.Type),
+			Score: val.Score,
+		}
+		switch val.Type {
+		case common.SearchResultBusStop:
+			result.BusStop = &BusStop{
+				ID:   val.BusStop.ID,
+				Name: val.BusStop.Name,
+				Lat:  val.BusStop.Lat,
+				Lng:  val.BusStop.Lng,
+			}
+		case common.SearchResultBusLine:
+			result.BusLine = &SearchBusLinePayload{
+				ID:        val.BusLine.ID,
+				FullName:  val.BusLine.FullName,
+				ShortName: val.BusLine.ShortName,
+				Origin:    val.BusLine.Origin,
+			}
+		}
+		payload = append(payload, result)
+	}
+	return SearchResponse{
+		Payload: payload,
+		Status:  statusSuccess,
+	}
+}
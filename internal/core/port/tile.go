@@ -0,0 +1,51 @@
+package port
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"bus-timing/pkg/apperror"
+	"bus-timing/pkg/mvt"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	vectorTileContentType = "application/vnd.mapbox-vector-tile"
+	vectorTileExtension   = ".mvt"
+)
+
+type TilePort struct {
+	TileService interface {
+		GetTile(ctx context.Context, tile mvt.Tile) ([]byte, error)
+	}
+	// MaxAge is how long clients may cache a tile
+	MaxAge time.Duration
+}
+
+// GetTile answers the Mapbox Vector Tile of the bus network, the y parameter ends with .mvt.
+func (port *TilePort) GetTile(ctx *gin.Context) {
+	z, errZ := strconv.Atoi(ctx.Param("z"))
+	x, errX := strconv.Atoi(ctx.Param("x"))
+	y, errY := strconv.Atoi(strings.TrimSuffix(ctx.Param("y"), vectorTileExtension))
+	tile := mvt.Tile{Z: z, X: x, Y: y}
+	if errZ != nil || errX != nil || errY != nil || !strings.HasSuffix(ctx.Param("y"), vectorTileExtension) || !tile.Valid() {
+		ctx.Error(apperror.InvalidInput("invalid tile: %s/%s/%s", ctx.Param("z"), ctx.Param("x"), ctx.Param("y")))
+		return
+	}
+
+	data, err := port.TileService.GetTile(ctx, tile)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	if port.MaxAge > 0 {
+		ctx.Header("Cache-Control", fmt.Sprintf("public, max-age=%d", int(port.MaxAge.Seconds())))
+	}
+	ctx.Data(http.StatusOK, vectorTileContentType, data)
+}
@@ -0,0 +1,180 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"bus-timing/internal/aggregate"
+	"bus-timing/pkg/apperror"
+)
+
+const (
+	defaultAPIKeyRateLimit  = 10
+	defaultAPIKeyBurst      = 20
+	defaultAPIKeyDailyQuota = 10000
+	defaultAPIKeyPrefix     = "bus-timing:api-keys"
+
+	apiKeyPrefix = "bt_"
+	// usageRetention keeps the counters of yesterday queryable
+	usageRetention = 48 * time.Hour
+)
+
+type APIKeyRepository interface {
+	Create(ctx context.Context, apiKey aggregate.APIKey) (aggregate.APIKey, error)
+	GetByHash(ctx context.Context, keyHash string) (aggregate.APIKey, error)
+	List(ctx context.Context) ([]aggregate.APIKey, error)
+	Delete(ctx context.Context, apiKeyID string) error
+}
+
+// APIKeyService issues API keys and authorizes the requests made with them,
+// within the rate limit and the daily quota of each key.
+type APIKeyService struct {
+	APIKeyRepository APIKeyRepository
+	Limiter          interface {
+		Take(ctx context.Context, key string, rate float64, burst int) (bool, time.Duration, error)
+		Increment(ctx context.Context, key string, ttl time.Duration) (int64, error)
+		Count(ctx context.Context, key string) (int64, error)
+	}
+	// KeyPrefix namespaces the buckets and counters of the limiter
+	KeyPrefix string
+	// DefaultRateLimit, DefaultBurst and DefaultDailyQuota apply to keys issued without limits
+	DefaultRateLimit  float64
+	DefaultBurst      int
+	DefaultDailyQuota int64
+}
+
+// CreateAPIKey issues a key, it is returned along with the saved API key and cannot be read afterwards.
+func (service *APIKeyService) CreateAPIKey(ctx context.Context, apiKey aggregate.APIKey) (aggregate.APIKey, string, error) {
+	if apiKey.Name == "" {
+		return aggregate.APIKey{}, "", apperror.InvalidInput("name is required")
+	}
+	if apiKey.RateLimit < 0 || apiKey.Burst < 0 || apiKey.DailyQuota < 0 {
+		return aggregate.APIKey{}, "", apperror.InvalidInput("limits must not be negative")
+	}
+	if apiKey.RateLimit == 0 {
+		apiKey.RateLimit = orDefault(service.DefaultRateLimit, defaultAPIKeyRateLimit)
+	}
+	if apiKey.Burst == 0 {
+		apiKey.Burst = orDefault(service.DefaultBurst, defaultAPIKeyBurst)
+	}
+	if apiKey.DailyQuota == 0 {
+		apiKey.DailyQuota = orDefault(service.DefaultDailyQuota, defaultAPIKeyDailyQuota)
+	}
+
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return aggregate.APIKey{}, "", err
+	}
+	key := apiKeyPrefix + hex.EncodeToString(buf)
+	apiKey.KeyHash = hashAPIKey(key)
+
+	apiKey, err := service.APIKeyRepository.Create(ctx, apiKey)
+	if err != nil {
+		return aggregate.APIKey{}, "", err
+	}
+	return apiKey, key, nil
+}
+
+func (service *APIKeyService) GetAPIKeys(ctx context.Context) ([]aggregate.APIKey, error) {
+	return service.APIKeyRepository.List(ctx)
+}
+
+func (service *APIKeyService) DeleteAPIKey(ctx context.Context, apiKeyID string) error {
+	return service.APIKeyRepository.Delete(ctx, apiKeyID)
+}
+
+// Authorize counts a request made with key and returns the ID of its API key, it fails when the key
+// is unknown, its rate limit is exceeded or its daily quota is used up.
+func (service *APIKeyService) Authorize(ctx context.Context, key string) (string, error) {
+	apiKey, err := service.APIKeyRepository.GetByHash(ctx, hashAPIKey(key))
+	if apperror.KindOf(err) == apperror.KindNotFound {
+		return "", apperror.Unauthorized("invalid api key")
+	}
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now().UTC()
+	allowed, retryAfter, err := service.Limiter.Take(ctx, service.key(apiKey.ID, "bucket"), apiKey.RateLimit, apiKey.Burst)
+	if err != nil {
+		return "", err
+	}
+	if !allowed {
+		if _, err := service.Limiter.Increment(ctx, service.usageKey(apiKey.ID, now, "rateLimited"), usageRetention); err != nil {
+			return "", err
+		}
+		return "", apperror.RateLimited(retryAfter, "rate limit of %g requests per second exceeded", apiKey.RateLimit)
+	}
+
+	requests, err := service.Limiter.Increment(ctx, service.usageKey(apiKey.ID, now, "requests"), usageRetention)
+	if err != nil {
+		return "", err
+	}
+	if requests > apiKey.DailyQuota {
+		if _, err := service.Limiter.Increment(ctx, service.usageKey(apiKey.ID, now, "quotaExceeded"), usageRetention); err != nil {
+			return "", err
+		}
+		tomorrow := now.Truncate(24 * time.Hour).Add(24 * time.Hour)
+		return "", apperror.RateLimited(tomorrow.Sub(now), "daily quota of %d requests exceeded", apiKey.DailyQuota)
+	}
+	return apiKey.ID, nil
+}
+
+// GetUsages returns the usage of every API key during the UTC day of day.
+func (service *APIKeyService) GetUsages(ctx context.Context, day time.Time) ([]aggregate.APIKeyUsage, error) {
+	apiKeys, err := service.APIKeyRepository.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	day = day.UTC().Truncate(24 * time.Hour)
+	usages := make([]aggregate.APIKeyUsage, 0, len(apiKeys))
+	for _, apiKey := range apiKeys {
+		usage := aggregate.APIKeyUsage{
+			APIKeyID: apiKey.ID,
+			Name:     apiKey.Name,
+			Day:      day,
+		}
+		counters := map[string]*int64{
+			"requests":      &usage.Requests,
+			"rateLimited":   &usage.RateLimited,
+			"quotaExceeded": &usage.QuotaExceeded,
+		}
+		for name, counter := range counters {
+			if *counter, err = service.Limiter.Count(ctx, service.usageKey(apiKey.ID, day, name)); err != nil {
+				return nil, err
+			}
+		}
+		usages = append(usages, usage)
+	}
+	return usages, nil
+}
+
+func (service *APIKeyService) key(apiKeyID, name string) string {
+	prefix := service.KeyPrefix
+	if prefix == "" {
+		prefix = defaultAPIKeyPrefix
+	}
+	return fmt.Sprintf("%s:%s:%s", prefix, apiKeyID, name)
+}
+
+// usageKey is the key of a counter of the UTC day of t
+func (service *APIKeyService) usageKey(apiKeyID string, t time.Time, name string) string {
+	return service.key(apiKeyID, t.UTC().Format(time.DateOnly)+":"+name)
+}
+
+func hashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+func orDefault[T int | int64 | float64](val, def T) T {
+	if val > 0 {
+		return val
+	}
+	return def
+}
@@ -0,0 +1,101 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"bus-timing/internal/aggregate"
+	"bus-timing/internal/repository"
+	"bus-timing/pkg/apperror"
+	"bus-timing/pkg/ratelimit"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func mockAPIKeyService(tt *testing.T) *APIKeyService {
+	repo, err := repository.NewAPIKeyRepository("")
+	assert.NoError(tt, err)
+	return &APIKeyService{
+		APIKeyRepository: repo,
+		Limiter:          ratelimit.NewMemoryLimiter(),
+	}
+}
+
+func TestAPIKeyService_CreateAPIKey(t *testing.T) {
+	t.Parallel()
+
+	t.Run("happy case: default limits", func(tt *testing.T) {
+		service := mockAPIKeyService(tt)
+		apiKey, key, err := service.CreateAPIKey(context.Background(), aggregate.APIKey{Name: "partner"})
+		assert.NoError(tt, err)
+		assert.True(tt, strings.HasPrefix(key, apiKeyPrefix))
+		assert.NotContains(tt, apiKey.KeyHash, key)
+		assert.Equal(tt, float64(defaultAPIKeyRateLimit), apiKey.RateLimit)
+		assert.Equal(tt, defaultAPIKeyBurst, apiKey.Burst)
+		assert.Equal(tt, int64(defaultAPIKeyDailyQuota), apiKey.DailyQuota)
+
+		apiKeyID, err := service.Authorize(context.Background(), key)
+		assert.NoError(tt, err)
+		assert.Equal(tt, apiKey.ID, apiKeyID)
+	})
+
+	t.Run("bad case: invalid API key", func(tt *testing.T) {
+		service := mockAPIKeyService(tt)
+		_, _, err := service.CreateAPIKey(context.Background(), aggregate.APIKey{})
+		assert.Equal(tt, apperror.InvalidInput("name is required"), err)
+		_, _, err = service.CreateAPIKey(context.Background(), aggregate.APIKey{Name: "partner", Burst: -1})
+		assert.Equal(tt, apperror.InvalidInput("limits must not be negative"), err)
+	})
+}
+
+func TestAPIKeyService_Authorize(t *testing.T) {
+	t.Parallel()
+
+	t.Run("happy case: usage is counted", func(tt *testing.T) {
+		service := mockAPIKeyService(tt)
+		_, key, err := service.CreateAPIKey(context.Background(), aggregate.APIKey{Name: "partner", RateLimit: 0.001, Burst: 3, DailyQuota: 2})
+		assert.NoError(tt, err)
+
+		_, err = service.Authorize(context.Background(), key)
+		assert.NoError(tt, err)
+		_, err = service.Authorize(context.Background(), key)
+		assert.NoError(tt, err)
+
+		// the quota is used up before the burst
+		_, err = service.Authorize(context.Background(), key)
+		assert.Equal(tt, apperror.KindRateLimited, apperror.KindOf(err))
+		assert.Equal(tt, "daily quota of 2 requests exceeded", apperror.MessageOf(err))
+		assert.LessOrEqual(tt, apperror.RetryAfterOf(err), 24*time.Hour)
+
+		_, err = service.Authorize(context.Background(), key)
+		assert.Equal(tt, apperror.KindRateLimited, apperror.KindOf(err))
+		assert.Equal(tt, "rate limit of 0.001 requests per second exceeded", apperror.MessageOf(err))
+		assert.Greater(tt, apperror.RetryAfterOf(err), 15*time.Minute)
+
+		usages, err := service.GetUsages(context.Background(), time.Now())
+		assert.NoError(tt, err)
+		assert.Len(tt, usages, 1)
+		assert.Equal(tt, "partner", usages[0].Name)
+		assert.Equal(tt, int64(3), usages[0].Requests)
+		assert.Equal(tt, int64(1), usages[0].QuotaExceeded)
+		assert.Equal(tt, int64(1), usages[0].RateLimited)
+
+		usages, err = service.GetUsages(context.Background(), time.Now().Add(-24*time.Hour))
+		assert.NoError(tt, err)
+		assert.Zero(tt, usages[0].Requests)
+	})
+
+	t.Run("bad case: unknown or deleted key", func(tt *testing.T) {
+		service := mockAPIKeyService(tt)
+		apiKey, key, err := service.CreateAPIKey(context.Background(), aggregate.APIKey{Name: "partner"})
+		assert.NoError(tt, err)
+		assert.NoError(tt, service.DeleteAPIKey(context.Background(), apiKey.ID))
+
+		_, err = service.Authorize(context.Background(), key)
+		assert.Equal(tt, apperror.Unauthorized("invalid api key"), err)
+		_, err = service.Authorize(context.Background(), "bt_unknown")
+		assert.Equal(tt, apperror.Unauthorized("invalid api key"), err)
+	})
+}
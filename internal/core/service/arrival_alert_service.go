@@ -0,0 +1,276 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"bus-timing/internal/aggregate"
+	"bus-timing/pkg/apperror"
+	"bus-timing/pkg/logging"
+)
+
+const (
+	defaultAlertInterval    = 15 * time.Second
+	defaultAlertDedupWindow = 30 * time.Minute
+	maxAlertThreshold       = 2 * time.Hour
+)
+
+type ArrivalAlertRepository interface {
+	Create(ctx context.Context, alert aggregate.ArrivalAlert) (aggregate.ArrivalAlert, error)
+	Get(ctx context.Context, alertID string) (aggregate.ArrivalAlert, error)
+	List(ctx context.Context) ([]aggregate.ArrivalAlert, error)
+	Update(ctx context.Context, alert aggregate.ArrivalAlert) (aggregate.ArrivalAlert, error)
+	Delete(ctx context.Context, alertID string) error
+}
+
+// ArrivalAlertService manages arrival alerts and evaluates them on a fixed interval,
+// calling the callback of an alert once for every bus coming within its threshold.
+type ArrivalAlertService struct {
+	Interval               time.Duration
+	ArrivalAlertRepository ArrivalAlertRepository
+	BusLineCatalogue       interface {
+		GetBusStop(ctx context.Context, busStopID string) (aggregate.BusStopBusLines, error)
+	}
+	RunningBusService interface {
+		EstimatedArrivalTime(ctx context.Context, busStopID string) ([]aggregate.IncomingBus, error)
+	}
+	Webhook interface {
+		// CheckURL tells why the callback URL of an alert cannot be called, e.g. it is not a public address
+		CheckURL(ctx context.Context, url string) error
+		Send(ctx context.Context, url string, payload any) error
+	}
+	// DedupWindow is how long a bus must be gone before it can trigger the same alert again
+	DedupWindow time.Duration
+	Logger      *slog.Logger
+
+	mu sync.Mutex
+	// triggered keeps when each bus, keyed by alert ID and vehicle plate, was last seen within threshold
+	triggered  map[string]time.Time
+	deliveries sync.WaitGroup
+}
+
+type arrivalAlertWebhookPayload struct {
+	AlertID      string    `json:"alertID"`
+	BusLineID    string    `json:"busLineID"`
+	BusStopID    string    `json:"busStopID"`
+	VehiclePlate string    `json:"vehiclePlate"`
+	ETA          int64     `json:"eta"`
+	Distance     float64   `json:"distance"`
+	TriggeredAt  time.Time `json:"triggeredAt"`
+}
+
+func (service *ArrivalAlertService) CreateAlert(ctx context.Context, alert aggregate.ArrivalAlert) (aggregate.ArrivalAlert, error) {
+	if err := service.validate(ctx, alert); err != nil {
+		return aggregate.ArrivalAlert{}, err
+	}
+	return service.ArrivalAlertRepository.Create(ctx, alert)
+}
+
+// GetAlert returns the alert of owner, alerts of other owners are not found.
+func (service *ArrivalAlertService) GetAlert(ctx context.Context, owner, alertID string) (aggregate.ArrivalAlert, error) {
+	alert, err := service.ArrivalAlertRepository.Get(ctx, alertID)
+	if err != nil {
+		return aggregate.ArrivalAlert{}, err
+	}
+	if alert.Owner != owner {
+		return aggregate.ArrivalAlert{}, apperror.NotFound("arrival alert not found: %s", alertID)
+	}
+	return alert, nil
+}
+
+// GetAlerts returns the alerts of owner, oldest first.
+func (service *ArrivalAlertService) GetAlerts(ctx context.Context, owner string) ([]aggregate.ArrivalAlert, error) {
+	alerts, err := service.ArrivalAlertRepository.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	owned := make([]aggregate.ArrivalAlert, 0, len(alerts))
+	for _, alert := range alerts {
+		if alert.Owner == owner {
+			owned = append(owned, alert)
+		}
+	}
+	return owned, nil
+}
+
+// UpdateAlert replaces the alert of alert.Owner, buses that already triggered it are forgotten.
+func (service *ArrivalAlertService) UpdateAlert(ctx context.Context, alert aggregate.ArrivalAlert) (aggregate.ArrivalAlert, error) {
+	if _, err := service.GetAlert(ctx, alert.Owner, alert.ID); err != nil {
+		return aggregate.ArrivalAlert{}, err
+	}
+	if err := service.validate(ctx, alert); err != nil {
+		return aggregate.ArrivalAlert{}, err
+	}
+	updated, err := service.ArrivalAlertRepository.Update(ctx, alert)
+	if err != nil {
+		return aggregate.ArrivalAlert{}, err
+	}
+	service.forget(alert.ID)
+	return updated, nil
+}
+
+// DeleteAlert deletes the alert of owner.
+func (service *ArrivalAlertService) DeleteAlert(ctx context.Context, owner, alertID string) error {
+	if _, err := service.GetAlert(ctx, owner, alertID); err != nil {
+		return err
+	}
+	if err := service.ArrivalAlertRepository.Delete(ctx, alertID); err != nil {
+		return err
+	}
+	service.forget(alertID)
+	return nil
+}
+
+func (service *ArrivalAlertService) validate(ctx context.Context, alert aggregate.ArrivalAlert) error {
+	if alert.Threshold <= 0 || alert.Threshold > maxAlertThreshold {
+		return apperror.InvalidInput("invalid threshold: %s", alert.Threshold)
+	}
+	if err := service.Webhook.CheckURL(ctx, alert.CallbackURL); err != nil {
+		return err
+	}
+
+	busStop, err := service.BusLineCatalogue.GetBusStop(ctx, alert.BusStopID)
+	if err != nil {
+		return err
+	}
+	for _, val := range busStop.BusLines {
+		if val.BusLine.ID == alert.BusLineID {
+			return nil
+		}
+	}
+	return apperror.InvalidInput("bus line %s does not pass bus stop %s", alert.BusLineID, alert.BusStopID)
+}
+
+// Run evaluates alerts until ctx is done, then waits for deliveries in progress.
+func (service *ArrivalAlertService) Run(ctx context.Context) {
+	defer service.deliveries.Wait()
+
+	interval := service.Interval
+	if interval <= 0 {
+		interval = defaultAlertInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		service.evaluate(ctx, time.Now())
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (service *ArrivalAlertService) evaluate(ctx context.Context, now time.Time) {
+	alerts, err := service.ArrivalAlertRepository.List(ctx)
+	if err != nil {
+		logging.OrDefault(service.Logger).ErrorContext(ctx, "list arrival alerts", "error", err)
+		return
+	}
+	service.prune(now)
+
+	// alerts of the same bus stop share one estimation
+	alertsByBusStop := make(map[string][]aggregate.ArrivalAlert)
+	for _, alert := range alerts {
+		alertsByBusStop[alert.BusStopID] = append(alertsByBusStop[alert.BusStopID], alert)
+	}
+
+	for busStopID, busStopAlerts := range alertsByBusStop {
+		if ctx.Err() != nil {
+			return
+		}
+		incomingBuses, err := service.RunningBusService.EstimatedArrivalTime(ctx, busStopID)
+		if err != nil {
+			logging.OrDefault(service.Logger).WarnContext(ctx, "evaluate arrival alerts", "bus_stop_id", busStopID, "error", err)
+			continue
+		}
+
+		for _, alert := range busStopAlerts {
+			for _, incomingBus := range incomingBuses {
+				if incomingBus.BusLine.ID != alert.BusLineID {
+					continue
+				}
+				if incomingBus.ArrivalTime > alert.Threshold || !service.trigger(alert.ID, incomingBus.Bus.VehiclePlate, now) {
+					continue
+				}
+				service.deliver(ctx, alert, aggregate.ArrivalAlertNotification{
+					AlertID:      alert.ID,
+					BusLineID:    alert.BusLineID,
+					BusStopID:    alert.BusStopID,
+					VehiclePlate: incomingBus.Bus.VehiclePlate,
+					ETA:          incomingBus.ArrivalTime,
+					Distance:     incomingBus.Distance,
+					TriggeredAt:  now,
+				})
+			}
+		}
+	}
+}
+
+// trigger reports whether the bus has not triggered the alert yet and remembers it did.
+func (service *ArrivalAlertService) trigger(alertID, vehiclePlate string, now time.Time) bool {
+	service.mu.Lock()
+	defer service.mu.Unlock()
+
+	if service.triggered == nil {
+		service.triggered = make(map[string]time.Time)
+	}
+	key := alertID + "/" + vehiclePlate
+	_, ok := service.triggered[key]
+	service.triggered[key] = now
+	return !ok
+}
+
+// prune forgets buses gone for longer than DedupWindow, they passed the bus stop.
+func (service *ArrivalAlertService) prune(now time.Time) {
+	dedupWindow := service.DedupWindow
+	if dedupWindow <= 0 {
+		dedupWindow = defaultAlertDedupWindow
+	}
+
+	service.mu.Lock()
+	defer service.mu.Unlock()
+	for key, seenAt := range service.triggered {
+		if now.Sub(seenAt) > dedupWindow {
+			delete(service.triggered, key)
+		}
+	}
+}
+
+func (service *ArrivalAlertService) forget(alertID string) {
+	service.mu.Lock()
+	defer service.mu.Unlock()
+
+	prefix := alertID + "/"
+	for key := range service.triggered {
+		if strings.HasPrefix(key, prefix) {
+			delete(service.triggered, key)
+		}
+	}
+}
+
+// deliver calls the callback in the background, a failed delivery is not retried once the webhook gave up.
+func (service *ArrivalAlertService) deliver(ctx context.Context, alert aggregate.ArrivalAlert, notification aggregate.ArrivalAlertNotification) {
+	payload := arrivalAlertWebhookPayload{
+		AlertID:      notification.AlertID,
+		BusLineID:    notification.BusLineID,
+		BusStopID:    notification.BusStopID,
+		VehiclePlate: notification.VehiclePlate,
+		ETA:          int64(notification.ETA / time.Second),
+		Distance:     notification.Distance,
+		TriggeredAt:  notification.TriggeredAt,
+	}
+
+	service.deliveries.Add(1)
+	go func() {
+		defer service.deliveries.Done()
+		if err := service.Webhook.Send(ctx, alert.CallbackURL, payload); err != nil {
+			logging.OrDefault(service.Logger).WarnContext(ctx, "deliver arrival alert", "alert_id", alert.ID, "error", err)
+		}
+	}()
+}
@@ -0,0 +1,220 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"bus-timing/internal/aggregate"
+	"bus-timing/internal/entity"
+	"bus-timing/internal/repository"
+	"bus-timing/pkg/apperror"
+	"bus-timing/pkg/common"
+	"bus-timing/pkg/webhook"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type mockWebhook struct {
+	mu       sync.Mutex
+	payloads []arrivalAlertWebhookPayload
+}
+
+// CheckURL resolves example.com to a public address and internal.example to a private one
+func (m *mockWebhook) CheckURL(ctx context.Context, url string) error {
+	return webhook.CheckURL(ctx, mockResolver{}, url)
+}
+
+type mockResolver struct{}
+
+func (mockResolver) LookupIPAddr(ctx context.Context, host string) ([]net.IPAddr, error) {
+	switch host {
+	case "example.com":
+		return []net.IPAddr{{IP: net.ParseIP("93.184.216.34")}}, nil
+	case "internal.example":
+		return []net.IPAddr{{IP: net.ParseIP("10.0.0.5")}}, nil
+	}
+	return nil, errors.New("no such host")
+}
+
+func (m *mockWebhook) Send(ctx context.Context, url string, payload any) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.payloads = append(m.payloads, payload.(arrivalAlertWebhookPayload))
+	return nil
+}
+
+func mockArrivalAlertService(tt *testing.T, incomingBuses *[]aggregate.IncomingBus) (*ArrivalAlertService, *mockWebhook) {
+	repo, err := repository.NewArrivalAlertRepository("")
+	assert.NoError(tt, err)
+	mock := &mockWebhook{}
+	return &ArrivalAlertService{
+		ArrivalAlertRepository: repo,
+		BusLineCatalogue:       mockBusLineCatalogue(tt),
+		RunningBusService: mockRunningBusService{
+			estimatedArrivalTime: func(ctx context.Context, busStopID string) ([]aggregate.IncomingBus, error) {
+				return *incomingBuses, nil
+			},
+		},
+		Webhook:     mock,
+		DedupWindow: 10 * time.Minute,
+	}, mock
+}
+
+func TestArrivalAlertService_CreateAlert(t *testing.T) {
+	t.Parallel()
+
+	incomingBuses := []aggregate.IncomingBus{}
+	service, _ := mockArrivalAlertService(t, &incomingBuses)
+
+	t.Run("happy case", func(tt *testing.T) {
+		alert, err := service.CreateAlert(context.Background(), aggregate.ArrivalAlert{
+			BusLineID:   "44480",
+			BusStopID:   "377906",
+			Threshold:   5 * time.Minute,
+			CallbackURL: "https://example.com/hook",
+		})
+		assert.NoError(tt, err)
+		assert.NotEmpty(tt, alert.ID)
+	})
+
+	t.Run("bad case: bus line does not pass the bus stop", func(tt *testing.T) {
+		_, err := service.CreateAlert(context.Background(), aggregate.ArrivalAlert{
+			BusLineID:   "0",
+			BusStopID:   "377906",
+			Threshold:   5 * time.Minute,
+			CallbackURL: "https://example.com/hook",
+		})
+		assert.Error(tt, err)
+	})
+
+	t.Run("bad case: callback URL of a private address", func(tt *testing.T) {
+		for _, callbackURL := range []string{"http://127.0.0.1:8080/hook", "http://169.254.169.254/latest/meta-data", "https://internal.example/hook"} {
+			_, err := service.CreateAlert(context.Background(), aggregate.ArrivalAlert{
+				BusLineID:   "44480",
+				BusStopID:   "377906",
+				Threshold:   5 * time.Minute,
+				CallbackURL: callbackURL,
+			})
+			assert.Error(tt, err, callbackURL)
+			assert.Equal(tt, apperror.KindInvalidInput, apperror.KindOf(err), callbackURL)
+		}
+	})
+
+	t.Run("bad case: invalid callback URL", func(tt *testing.T) {
+		_, err := service.CreateAlert(context.Background(), aggregate.ArrivalAlert{
+			BusLineID:   "44480",
+			BusStopID:   "377906",
+			Threshold:   5 * time.Minute,
+			CallbackURL: "ftp://example.com/hook",
+		})
+		assert.Error(tt, err)
+	})
+}
+
+func TestArrivalAlertService_owner(t *testing.T) {
+	t.Parallel()
+
+	incomingBuses := []aggregate.IncomingBus{}
+	service, _ := mockArrivalAlertService(t, &incomingBuses)
+	alert, err := service.CreateAlert(context.Background(), aggregate.ArrivalAlert{
+		Owner:       "partner-a",
+		BusLineID:   "44480",
+		BusStopID:   "377906",
+		Threshold:   5 * time.Minute,
+		CallbackURL: "https://example.com/hook",
+	})
+	assert.NoError(t, err)
+
+	t.Run("happy case: the owner sees and changes the alert", func(tt *testing.T) {
+		alerts, err := service.GetAlerts(context.Background(), "partner-a")
+		assert.NoError(tt, err)
+		assert.Equal(tt, []aggregate.ArrivalAlert{alert}, alerts)
+
+		got, err := service.GetAlert(context.Background(), "partner-a", alert.ID)
+		assert.NoError(tt, err)
+		assert.Equal(tt, alert, got)
+
+		changed := alert
+		changed.Threshold = 3 * time.Minute
+		updated, err := service.UpdateAlert(context.Background(), changed)
+		assert.NoError(tt, err)
+		assert.Equal(tt, "partner-a", updated.Owner)
+		assert.Equal(tt, 3*time.Minute, updated.Threshold)
+	})
+
+	t.Run("bad case: alerts of another partner are not found", func(tt *testing.T) {
+		alerts, err := service.GetAlerts(context.Background(), "partner-b")
+		assert.NoError(tt, err)
+		assert.Empty(tt, alerts)
+
+		_, err = service.GetAlert(context.Background(), "partner-b", alert.ID)
+		assert.Equal(tt, apperror.KindNotFound, apperror.KindOf(err))
+
+		changed := alert
+		changed.Owner = "partner-b"
+		_, err = service.UpdateAlert(context.Background(), changed)
+		assert.Equal(tt, apperror.KindNotFound, apperror.KindOf(err))
+
+		err = service.DeleteAlert(context.Background(), "partner-b", alert.ID)
+		assert.Equal(tt, apperror.KindNotFound, apperror.KindOf(err))
+		_, err = service.GetAlert(context.Background(), "partner-a", alert.ID)
+		assert.NoError(tt, err)
+	})
+}
+
+func TestArrivalAlertService_evaluate(t *testing.T) {
+	t.Parallel()
+
+	incomingBus := func(vehiclePlate string, distance float64) aggregate.IncomingBus {
+		return aggregate.IncomingBus{
+			Bus:         entity.Bus{VehiclePlate: vehiclePlate},
+			BusLine:     entity.BusLine{ID: "44480"},
+			BusPosition: entity.RunningBusPosition{CrowdLevel: common.LowCrowd},
+			Distance:    distance,
+			ArrivalTime: common.TravelDuration(distance, common.LowCrowd),
+		}
+	}
+	// 60 km/h, 1000 meters per minute
+	incomingBuses := []aggregate.IncomingBus{incomingBus("PC1", 8000)}
+	service, webhook := mockArrivalAlertService(t, &incomingBuses)
+	alert, err := service.CreateAlert(context.Background(), aggregate.ArrivalAlert{
+		BusLineID:   "44480",
+		BusStopID:   "377906",
+		Threshold:   5 * time.Minute,
+		CallbackURL: "https://example.com/hook",
+	})
+	assert.NoError(t, err)
+
+	now := time.Now()
+	evaluate := func(at time.Time) {
+		service.evaluate(context.Background(), at)
+		service.deliveries.Wait()
+	}
+
+	// too far
+	evaluate(now)
+	assert.Empty(t, webhook.payloads)
+
+	// within threshold, triggered once
+	incomingBuses = []aggregate.IncomingBus{incomingBus("PC1", 4000)}
+	evaluate(now.Add(time.Minute))
+	evaluate(now.Add(2 * time.Minute))
+	assert.Len(t, webhook.payloads, 1)
+	assert.Equal(t, alert.ID, webhook.payloads[0].AlertID)
+	assert.Equal(t, "PC1", webhook.payloads[0].VehiclePlate)
+	assert.Equal(t, int64(240), webhook.payloads[0].ETA)
+
+	// another bus triggers it again
+	incomingBuses = []aggregate.IncomingBus{incomingBus("PC2", 2000)}
+	evaluate(now.Add(3 * time.Minute))
+	assert.Len(t, webhook.payloads, 2)
+
+	// the first bus is forgotten after the dedup window
+	incomingBuses = []aggregate.IncomingBus{incomingBus("PC1", 2000)}
+	evaluate(now.Add(30 * time.Minute))
+	assert.Len(t, webhook.payloads, 3)
+}
@@ -0,0 +1,310 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"bus-timing/internal/aggregate"
+	"bus-timing/pkg/logging"
+)
+
+const (
+	defaultArrivalWatchInterval = 10 * time.Second
+	defaultArrivalChangeMinimum = 30 * time.Second
+)
+
+// ArrivalWatcher estimates arrivals of the bus stops someone is subscribed to on a fixed interval
+// and pushes them to the subscribers when they change materially.
+type ArrivalWatcher struct {
+	Interval          time.Duration
+	RunningBusService interface {
+		EstimatedArrivalTime(ctx context.Context, busStopID string) ([]aggregate.IncomingBus, error)
+	}
+	// ChangeMinimum is how much an ETA must move before subscribers are told about it
+	ChangeMinimum time.Duration
+	Logger        *slog.Logger
+
+	mu       sync.Mutex
+	busStops map[string]*arrivalFeed
+}
+
+type arrivalFeed struct {
+	last          *aggregate.ArrivalEvent
+	subscriptions map[*ArrivalSubscription]struct{}
+}
+
+// ArrivalSubscription receives the arrivals of the bus stops it is subscribed to.
+// Updates are coalesced per bus stop, a slow reader gets the latest arrivals of each bus stop
+// instead of every intermediate one.
+type ArrivalSubscription struct {
+	watcher *ArrivalWatcher
+
+	mu       sync.Mutex
+	busStops map[string]struct{}
+	pending  map[string]aggregate.ArrivalEvent
+	// order keeps pending bus stops in the order they were updated
+	order  []string
+	notify chan struct{}
+	closed bool
+}
+
+// NewSubscription returns a subscription without any bus stop, Close must be called once it is not used anymore.
+func (watcher *ArrivalWatcher) NewSubscription() *ArrivalSubscription {
+	return &ArrivalSubscription{
+		watcher:  watcher,
+		busStops: make(map[string]struct{}),
+		pending:  make(map[string]aggregate.ArrivalEvent),
+		notify:   make(chan struct{}, 1),
+	}
+}
+
+func (watcher *ArrivalWatcher) Run(ctx context.Context) {
+	interval := watcher.Interval
+	if interval <= 0 {
+		interval = defaultArrivalWatchInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		for _, busStopID := range watcher.watchedBusStops() {
+			if ctx.Err() != nil {
+				return
+			}
+			incomingBuses, err := watcher.RunningBusService.EstimatedArrivalTime(ctx, busStopID)
+			if err != nil {
+				logging.OrDefault(watcher.Logger).WarnContext(ctx, "watch arrivals", "bus_stop_id", busStopID, "error", err)
+				continue
+			}
+			watcher.publish(busStopID, incomingBuses)
+		}
+	}
+}
+
+func (watcher *ArrivalWatcher) watchedBusStops() []string {
+	watcher.mu.Lock()
+	defer watcher.mu.Unlock()
+
+	busStopIDs := make([]string, 0, len(watcher.busStops))
+	for busStopID := range watcher.busStops {
+		busStopIDs = append(busStopIDs, busStopID)
+	}
+	return busStopIDs
+}
+
+// publish sends the incoming buses to the subscribers of the bus stop when they changed materially.
+func (watcher *ArrivalWatcher) publish(busStopID string, incomingBuses []aggregate.IncomingBus) {
+	watcher.mu.Lock()
+	defer watcher.mu.Unlock()
+
+	feed, ok := watcher.busStops[busStopID]
+	if !ok {
+		// every subscriber left while arrivals were estimated
+		return
+	}
+	if feed.last != nil && !watcher.changed(feed.last.IncomingBuses, incomingBuses) {
+		return
+	}
+
+	event := aggregate.ArrivalEvent{
+		BusStopID:     busStopID,
+		IncomingBuses: incomingBuses,
+		PublishedAt:   time.Now(),
+	}
+	feed.last = &event
+	for subscription := range feed.subscriptions {
+		subscription.push(event)
+	}
+}
+
+// changed reports whether a bus arrives, leaves or has its ETA moved by at least ChangeMinimum.
+func (watcher *ArrivalWatcher) changed(previous, current []aggregate.IncomingBus) bool {
+	if len(previous) != len(current) {
+		return true
+	}
+	changeMinimum := watcher.ChangeMinimum
+	if changeMinimum <= 0 {
+		changeMinimum = defaultArrivalChangeMinimum
+	}
+
+	previousByBusLine := make(map[string]aggregate.IncomingBus, len(previous))
+	for _, val := range previous {
+		previousByBusLine[val.BusLine.ID] = val
+	}
+	for _, val := range current {
+		before, ok := previousByBusLine[val.BusLine.ID]
+		if !ok || before.Bus.VehiclePlate != val.Bus.VehiclePlate {
+			return true
+		}
+		diff := val.ArrivalTime - before.ArrivalTime
+		if diff < 0 {
+			diff = -diff
+		}
+		if diff >= changeMinimum {
+			return true
+		}
+	}
+	return false
+}
+
+// Subscribe adds the bus stop to the subscription, its current arrivals are pushed right away.
+func (subscription *ArrivalSubscription) Subscribe(ctx context.Context, busStopID string) error {
+	watcher := subscription.watcher
+
+	watcher.mu.Lock()
+	feed, ok := watcher.busStops[busStopID]
+	var last *aggregate.ArrivalEvent
+	if ok {
+		last = feed.last
+	}
+	watcher.mu.Unlock()
+
+	if last == nil {
+		// nobody is watching the bus stop yet, an unknown bus stop fails here
+		incomingBuses, err := watcher.RunningBusService.EstimatedArrivalTime(ctx, busStopID)
+		if err != nil {
+			return err
+		}
+		last = &aggregate.ArrivalEvent{
+			BusStopID:     busStopID,
+			IncomingBuses: incomingBuses,
+			PublishedAt:   time.Now(),
+		}
+	}
+
+	// closed is checked with the watcher locked, so a Close racing the insert still finds the subscription in the feed
+	watcher.mu.Lock()
+	defer watcher.mu.Unlock()
+	subscription.mu.Lock()
+	if subscription.closed {
+		subscription.mu.Unlock()
+		return nil
+	}
+	subscription.busStops[busStopID] = struct{}{}
+	subscription.mu.Unlock()
+
+	if watcher.busStops == nil {
+		watcher.busStops = make(map[string]*arrivalFeed)
+	}
+	feed, ok = watcher.busStops[busStopID]
+	if !ok {
+		feed = &arrivalFeed{subscriptions: make(map[*ArrivalSubscription]struct{})}
+		watcher.busStops[busStopID] = feed
+	}
+	if feed.last == nil {
+		feed.last = last
+	}
+	feed.subscriptions[subscription] = struct{}{}
+	subscription.push(*feed.last)
+
+	return nil
+}
+
+// Unsubscribe removes the bus stop from the subscription, pending arrivals of the bus stop are dropped.
+func (subscription *ArrivalSubscription) Unsubscribe(busStopID string) {
+	subscription.mu.Lock()
+	delete(subscription.busStops, busStopID)
+	if _, ok := subscription.pending[busStopID]; ok {
+		delete(subscription.pending, busStopID)
+		for i, val := range subscription.order {
+			if val == busStopID {
+				subscription.order = append(subscription.order[:i], subscription.order[i+1:]...)
+				break
+			}
+		}
+	}
+	subscription.mu.Unlock()
+
+	subscription.watcher.leave(subscription, busStopID)
+}
+
+// BusStops returns the bus stops the subscription is subscribed to.
+func (subscription *ArrivalSubscription) BusStops() []string {
+	subscription.mu.Lock()
+	defer subscription.mu.Unlock()
+
+	busStopIDs := make([]string, 0, len(subscription.busStops))
+	for busStopID := range subscription.busStops {
+		busStopIDs = append(busStopIDs, busStopID)
+	}
+	return busStopIDs
+}
+
+// Updates is signalled when arrivals are waiting to be taken with Next.
+func (subscription *ArrivalSubscription) Updates() <-chan struct{} {
+	return subscription.notify
+}
+
+// Next takes the waiting arrivals in the order their bus stops were updated.
+func (subscription *ArrivalSubscription) Next() []aggregate.ArrivalEvent {
+	subscription.mu.Lock()
+	defer subscription.mu.Unlock()
+
+	events := make([]aggregate.ArrivalEvent, 0, len(subscription.order))
+	for _, busStopID := range subscription.order {
+		events = append(events, subscription.pending[busStopID])
+		delete(subscription.pending, busStopID)
+	}
+	subscription.order = subscription.order[:0]
+	return events
+}
+
+// Close unsubscribes from every bus stop.
+func (subscription *ArrivalSubscription) Close() {
+	subscription.mu.Lock()
+	subscription.closed = true
+	busStopIDs := make([]string, 0, len(subscription.busStops))
+	for busStopID := range subscription.busStops {
+		busStopIDs = append(busStopIDs, busStopID)
+	}
+	subscription.busStops = map[string]struct{}{}
+	subscription.pending = map[string]aggregate.ArrivalEvent{}
+	subscription.order = nil
+	subscription.mu.Unlock()
+
+	for _, busStopID := range busStopIDs {
+		subscription.watcher.leave(subscription, busStopID)
+	}
+}
+
+// push is called with the watcher locked.
+func (subscription *ArrivalSubscription) push(event aggregate.ArrivalEvent) {
+	subscription.mu.Lock()
+	defer subscription.mu.Unlock()
+
+	if _, ok := subscription.busStops[event.BusStopID]; !ok {
+		return
+	}
+	if _, ok := subscription.pending[event.BusStopID]; !ok {
+		subscription.order = append(subscription.order, event.BusStopID)
+	}
+	subscription.pending[event.BusStopID] = event
+
+	select {
+	case subscription.notify <- struct{}{}:
+	default:
+	}
+}
+
+// leave stops watching the bus stop once its last subscriber is gone.
+func (watcher *ArrivalWatcher) leave(subscription *ArrivalSubscription, busStopID string) {
+	watcher.mu.Lock()
+	defer watcher.mu.Unlock()
+
+	feed, ok := watcher.busStops[busStopID]
+	if !ok {
+		return
+	}
+	delete(feed.subscriptions, subscription)
+	if len(feed.subscriptions) == 0 {
+		delete(watcher.busStops, busStopID)
+	}
+}
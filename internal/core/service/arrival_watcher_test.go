@@ -0,0 +1,137 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"bus-timing/internal/aggregate"
+	"bus-timing/internal/entity"
+	"bus-timing/pkg/common"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// mockIncomingBus is a bus distance meters away in medium crowd, about 4 minutes for 3333 meters,
+// built like RunningBusService.EstimatedArrivalTime does
+func mockIncomingBus(busLineID, vehiclePlate string, distance float64) aggregate.IncomingBus {
+	return aggregate.IncomingBus{
+		Bus:         entity.Bus{VehiclePlate: vehiclePlate},
+		BusLine:     entity.BusLine{ID: busLineID},
+		BusPosition: entity.RunningBusPosition{CrowdLevel: common.MediumCrowd},
+		Distance:    distance,
+		ArrivalTime: common.TravelDuration(distance, common.MediumCrowd),
+	}
+}
+
+func TestArrivalWatcher_Subscribe(t *testing.T) {
+	t.Parallel()
+
+	t.Run("happy case: current arrivals are pushed right away", func(tt *testing.T) {
+		watcher := &ArrivalWatcher{
+			RunningBusService: mockRunningBusService{
+				estimatedArrivalTime: func(ctx context.Context, busStopID string) ([]aggregate.IncomingBus, error) {
+					return []aggregate.IncomingBus{mockIncomingBus("44480", "PC1", 833)}, nil
+				},
+			},
+		}
+		subscription := watcher.NewSubscription()
+		defer subscription.Close()
+
+		assert.NoError(tt, subscription.Subscribe(context.Background(), "377906"))
+		assert.NoError(tt, subscription.Subscribe(context.Background(), "378204"))
+		<-subscription.Updates()
+		events := subscription.Next()
+		assert.Len(tt, events, 2)
+		assert.Equal(tt, "377906", events[0].BusStopID)
+		assert.Equal(tt, "378204", events[1].BusStopID)
+		assert.ElementsMatch(tt, []string{"377906", "378204"}, watcher.watchedBusStops())
+	})
+
+	t.Run("bad case: unknown bus stop", func(tt *testing.T) {
+		watcher := &ArrivalWatcher{
+			RunningBusService: mockRunningBusService{
+				estimatedArrivalTime: func(ctx context.Context, busStopID string) ([]aggregate.IncomingBus, error) {
+					return nil, errors.New("bus stop not found")
+				},
+			},
+		}
+		subscription := watcher.NewSubscription()
+		defer subscription.Close()
+
+		assert.Error(tt, subscription.Subscribe(context.Background(), "0"))
+		assert.Empty(tt, subscription.BusStops())
+		assert.Empty(tt, watcher.watchedBusStops())
+	})
+
+	t.Run("happy case: closing while subscribing leaves no bus stop watched", func(tt *testing.T) {
+		watcher := &ArrivalWatcher{
+			RunningBusService: mockRunningBusService{
+				estimatedArrivalTime: func(ctx context.Context, busStopID string) ([]aggregate.IncomingBus, error) {
+					return nil, nil
+				},
+			},
+		}
+		for i := 0; i < 500; i++ {
+			subscription := watcher.NewSubscription()
+			var wg sync.WaitGroup
+			wg.Add(2)
+			go func() {
+				defer wg.Done()
+				assert.NoError(tt, subscription.Subscribe(context.Background(), "377906"))
+			}()
+			go func() {
+				defer wg.Done()
+				subscription.Close()
+			}()
+			wg.Wait()
+			// a Close that ran before Subscribe saw closed, or after it was inserted, both leave nothing behind
+			if !assert.Empty(tt, watcher.watchedBusStops()) {
+				return
+			}
+		}
+	})
+}
+
+func TestArrivalWatcher_publish(t *testing.T) {
+	t.Parallel()
+
+	watcher := &ArrivalWatcher{
+		RunningBusService: mockRunningBusService{
+			estimatedArrivalTime: func(ctx context.Context, busStopID string) ([]aggregate.IncomingBus, error) {
+				return []aggregate.IncomingBus{mockIncomingBus("44480", "PC1", 4167)}, nil
+			},
+		},
+		ChangeMinimum: 30 * time.Second,
+	}
+	subscription := watcher.NewSubscription()
+	defer subscription.Close()
+	assert.NoError(t, subscription.Subscribe(context.Background(), "377906"))
+	<-subscription.Updates()
+	subscription.Next()
+
+	// small ETA moves are not pushed, 5 minutes to 4 minutes 50 seconds
+	watcher.publish("377906", []aggregate.IncomingBus{mockIncomingBus("44480", "PC1", 4028)})
+	assert.Empty(t, subscription.Next())
+
+	// ETA moves of at least ChangeMinimum are pushed, 5 minutes to 4 minutes
+	watcher.publish("377906", []aggregate.IncomingBus{mockIncomingBus("44480", "PC1", 3333)})
+	events := subscription.Next()
+	assert.Len(t, events, 1)
+	assert.Equal(t, 3333.0, events[0].IncomingBuses[0].Distance)
+
+	// updates of a slow reader are coalesced per bus stop
+	watcher.publish("377906", []aggregate.IncomingBus{mockIncomingBus("44480", "PC1", 2500)})
+	watcher.publish("377906", []aggregate.IncomingBus{mockIncomingBus("44480", "PC2", 7500)})
+	events = subscription.Next()
+	assert.Len(t, events, 1)
+	assert.Equal(t, "PC2", events[0].IncomingBuses[0].Bus.VehiclePlate)
+
+	// nothing is pushed once unsubscribed
+	subscription.Unsubscribe("377906")
+	watcher.publish("377906", []aggregate.IncomingBus{})
+	assert.Empty(t, subscription.Next())
+	assert.Empty(t, watcher.watchedBusStops())
+}
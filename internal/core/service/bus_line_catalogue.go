@@ -0,0 +1,176 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"bus-timing/internal/aggregate"
+	"bus-timing/pkg/apperror"
+	"bus-timing/pkg/location"
+	"bus-timing/pkg/logging"
+)
+
+// BusLineCatalogue keeps the bus lines and their indexes in memory,
+// they are rebuilt when the catalogue is older than RefreshInterval.
+type BusLineCatalogue struct {
+	BusLineService interface {
+		GetBusLines(ctx context.Context) ([]aggregate.BusLineBusStop, error)
+	}
+	RefreshInterval time.Duration
+	Logger          *slog.Logger
+
+	// refreshMu lets a single caller rebuild a stale catalogue at a time
+	refreshMu    sync.Mutex
+	mu           sync.RWMutex
+	busLines     []aggregate.BusLineBusStop
+	busStopIndex *busStopIndex
+	geoIndex     *busStopGeoIndex
+	refreshedAt  time.Time
+	listeners    []func(busLines []aggregate.BusLineBusStop)
+}
+
+// OnRefresh registers fn to be called with the new bus lines every time the catalogue is rebuilt.
+// The catalogue is loaded lazily, fn is called right away only when it is already loaded.
+func (catalogue *BusLineCatalogue) OnRefresh(fn func(busLines []aggregate.BusLineBusStop)) {
+	catalogue.mu.Lock()
+	catalogue.listeners = append(catalogue.listeners, fn)
+	loaded := catalogue.busStopIndex != nil
+	busLines := catalogue.busLines
+	catalogue.mu.Unlock()
+
+	if loaded {
+		fn(busLines)
+	}
+}
+
+func (catalogue *BusLineCatalogue) Refresh(ctx context.Context) error {
+	busLines, err := catalogue.BusLineService.GetBusLines(ctx)
+	if err != nil {
+		return err
+	}
+
+	index := newBusStopIndex(busLines)
+	geoIndex := newBusStopGeoIndex(index.busStops)
+
+	catalogue.mu.Lock()
+	catalogue.busLines = busLines
+	catalogue.busStopIndex = index
+	catalogue.geoIndex = geoIndex
+	catalogue.refreshedAt = time.Now()
+	listeners := catalogue.listeners
+	catalogue.mu.Unlock()
+
+	for _, fn := range listeners {
+		fn(busLines)
+	}
+	return nil
+}
+
+func (catalogue *BusLineCatalogue) GetBusLines(ctx context.Context) ([]aggregate.BusLineBusStop, error) {
+	if err := catalogue.ensureFresh(ctx); err != nil {
+		return nil, err
+	}
+
+	catalogue.mu.RLock()
+	defer catalogue.mu.RUnlock()
+	return catalogue.busLines, nil
+}
+
+// GetBusLine returns the bus line of the catalogue with busLineID.
+func (catalogue *BusLineCatalogue) GetBusLine(ctx context.Context, busLineID string) (aggregate.BusLineBusStop, error) {
+	if err := catalogue.ensureFresh(ctx); err != nil {
+		return aggregate.BusLineBusStop{}, err
+	}
+
+	catalogue.mu.RLock()
+	defer catalogue.mu.RUnlock()
+
+	for _, val := range catalogue.busLines {
+		if val.BusLine.ID == busLineID {
+			return val, nil
+		}
+	}
+	return aggregate.BusLineBusStop{}, apperror.NotFound("cannot find bus line with ID: %s", busLineID)
+}
+
+// GetBusStops returns a page of bus stops and the total number of bus stops.
+func (catalogue *BusLineCatalogue) GetBusStops(ctx context.Context, offset, limit int) ([]aggregate.BusStopBusLines, int, error) {
+	if err := catalogue.ensureFresh(ctx); err != nil {
+		return nil, 0, err
+	}
+
+	catalogue.mu.RLock()
+	defer catalogue.mu.RUnlock()
+
+	busStops := catalogue.busStopIndex.busStops
+	total := len(busStops)
+	if offset >= total {
+		return nil, total, nil
+	}
+	end := offset + limit
+	if end > total {
+		end = total
+	}
+	return busStops[offset:end], total, nil
+}
+
+func (catalogue *BusLineCatalogue) GetBusStop(ctx context.Context, busStopID string) (aggregate.BusStopBusLines, error) {
+	if err := catalogue.ensureFresh(ctx); err != nil {
+		return aggregate.BusStopBusLines{}, err
+	}
+
+	catalogue.mu.RLock()
+	defer catalogue.mu.RUnlock()
+
+	busStop, ok := catalogue.busStopIndex.get(busStopID)
+	if !ok {
+		return aggregate.BusStopBusLines{}, apperror.NotFound("cannot find bus stop with ID: %s", busStopID)
+	}
+	return busStop, nil
+}
+
+// GetNearbyBusStops returns at most limit bus stops within radius meters of center, nearest first.
+func (catalogue *BusLineCatalogue) GetNearbyBusStops(ctx context.Context, center location.Location, radius float64, limit int) ([]aggregate.NearbyBusStop, error) {
+	if err := catalogue.ensureFresh(ctx); err != nil {
+		return nil, err
+	}
+
+	catalogue.mu.RLock()
+	defer catalogue.mu.RUnlock()
+	return catalogue.geoIndex.nearby(center, radius, limit), nil
+}
+
+func (catalogue *BusLineCatalogue) ensureFresh(ctx context.Context) error {
+	loaded, fresh := catalogue.state()
+	if fresh {
+		return nil
+	}
+
+	catalogue.refreshMu.Lock()
+	defer catalogue.refreshMu.Unlock()
+
+	// another caller may have refreshed while waiting
+	loaded, fresh = catalogue.state()
+	if fresh {
+		return nil
+	}
+
+	err := catalogue.Refresh(ctx)
+	if err != nil && loaded {
+		// keep serving the previous catalogue until uWave is back
+		logging.OrDefault(catalogue.Logger).WarnContext(ctx, "refresh bus line catalogue, serving the previous one", "error", err)
+		return nil
+	}
+	return err
+}
+
+func (catalogue *BusLineCatalogue) state() (loaded, fresh bool) {
+	catalogue.mu.RLock()
+	defer catalogue.mu.RUnlock()
+
+	loaded = catalogue.busStopIndex != nil
+	fresh = loaded && (catalogue.RefreshInterval <= 0 || time.Since(catalogue.refreshedAt) < catalogue.RefreshInterval)
+	return loaded, fresh
+}
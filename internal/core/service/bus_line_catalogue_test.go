@@ -0,0 +1,125 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"sort"
+	"testing"
+
+	"bus-timing/internal/aggregate"
+	"bus-timing/pkg/apperror"
+	"bus-timing/pkg/location"
+	"bus-timing/pkg/uwave"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func mockBusLineCatalogue(tt *testing.T) *BusLineCatalogue {
+	busLineData, _ := os.ReadFile("./../../../test_data/bus_line.json")
+	busLine := uwave.GetBusLineResponse{}
+	err := json.Unmarshal(busLineData, &busLine)
+	assert.NoError(tt, err)
+
+	uwaveClient := mockUWaveClient{
+		getBusLines: func(ctx context.Context) (uwave.GetBusLineResponse, error) {
+			return busLine, nil
+		},
+	}
+	return &BusLineCatalogue{
+		BusLineService: &BusLiveService{UWaveClient: uwaveClient},
+	}
+}
+
+func TestBusLineCatalogue_GetBusLine(t *testing.T) {
+	t.Parallel()
+
+	t.Run("happy case", func(tt *testing.T) {
+		catalogue := mockBusLineCatalogue(tt)
+
+		resp, err := catalogue.GetBusLine(context.Background(), "44480")
+		assert.NoError(tt, err)
+		assert.Equal(tt, "Campus Rider Green", resp.BusLine.FullName)
+		assert.Len(tt, resp.BusStops, 6)
+	})
+
+	t.Run("bad case: unknown bus line", func(tt *testing.T) {
+		catalogue := mockBusLineCatalogue(tt)
+
+		_, err := catalogue.GetBusLine(context.Background(), "0")
+		assert.Equal(tt, apperror.KindNotFound, apperror.KindOf(err))
+	})
+}
+
+func TestBusLineCatalogue_GetBusStops(t *testing.T) {
+	t.Parallel()
+
+	t.Run("happy case: second page", func(tt *testing.T) {
+		catalogue := mockBusLineCatalogue(tt)
+
+		resp, total, err := catalogue.GetBusStops(context.Background(), 30, 5)
+		assert.NoError(tt, err)
+		assert.Equal(tt, 32, total)
+		assert.Len(tt, resp, 2)
+	})
+
+	t.Run("offset out of range", func(tt *testing.T) {
+		catalogue := mockBusLineCatalogue(tt)
+
+		resp, total, err := catalogue.GetBusStops(context.Background(), 40, 5)
+		assert.NoError(tt, err)
+		assert.Equal(tt, 32, total)
+		assert.Empty(tt, resp)
+	})
+
+	t.Run("bad case: get data from uwave failed", func(tt *testing.T) {
+		uwaveClient := mockUWaveClient{
+			getBusLines: func(ctx context.Context) (uwave.GetBusLineResponse, error) {
+				return uwave.GetBusLineResponse{}, http.ErrServerClosed
+			},
+		}
+		catalogue := &BusLineCatalogue{
+			BusLineService: &BusLiveService{UWaveClient: uwaveClient},
+		}
+
+		_, _, err := catalogue.GetBusStops(context.Background(), 0, 5)
+		assert.Equal(tt, http.ErrServerClosed, err)
+	})
+}
+
+func TestBusLineCatalogue_GetNearbyBusStops(t *testing.T) {
+	t.Parallel()
+
+	t.Run("happy case: same result as a linear scan", func(tt *testing.T) {
+		catalogue := mockBusLineCatalogue(tt)
+		center := location.Location{Lat: 1.34564, Lng: 103.6878}
+		radius := 800.0
+
+		allBusStops, _, err := catalogue.GetBusStops(context.Background(), 0, 100)
+		assert.NoError(tt, err)
+		expected := make([]aggregate.NearbyBusStop, 0)
+		for _, busStop := range allBusStops {
+			distance := location.CalculateStraightLine(center, location.Location{Lat: busStop.BusStop.Lat, Lng: busStop.BusStop.Lng})
+			if distance <= radius {
+				expected = append(expected, aggregate.NearbyBusStop{BusStop: busStop, Distance: distance})
+			}
+		}
+		sort.SliceStable(expected, func(i, j int) bool {
+			if expected[i].Distance == expected[j].Distance {
+				return expected[i].BusStop.BusStop.ID < expected[j].BusStop.BusStop.ID
+			}
+			return expected[i].Distance < expected[j].Distance
+		})
+
+		resp, err := catalogue.GetNearbyBusStops(context.Background(), center, radius, 100)
+		assert.NoError(tt, err)
+		assert.NotEmpty(tt, resp)
+		assert.Equal(tt, "378233", resp[0].BusStop.BusStop.ID)
+		assert.Equal(tt, expected, resp)
+
+		resp, err = catalogue.GetNearbyBusStops(context.Background(), center, radius, 2)
+		assert.NoError(tt, err)
+		assert.Len(tt, resp, 2)
+	})
+}
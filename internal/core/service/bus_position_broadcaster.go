@@ -0,0 +1,136 @@
+package service
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"bus-timing/internal/aggregate"
+)
+
+// subscriberBufferSize is how many events a slow subscriber may lag behind before events are dropped,
+// events are full snapshots so a dropped one is replaced by the next.
+const subscriberBufferSize = 16
+
+// BusPositionBroadcaster fans bus position changes out to subscribers of each bus line,
+// keeping the last events so a subscriber can resume after reconnecting.
+type BusPositionBroadcaster struct {
+	bufferSize int
+
+	mu     sync.Mutex
+	lastID uint64
+	feeds  map[string]*busPositionFeed
+}
+
+type busPositionFeed struct {
+	// events are ordered by ID, at most bufferSize
+	events      []aggregate.BusPositionEvent
+	subscribers map[chan aggregate.BusPositionEvent]struct{}
+}
+
+// NewBusPositionBroadcaster keeps bufferSize events per bus line. Event IDs start from the current time
+// in milliseconds, so IDs seen before a restart are older than the new ones.
+func NewBusPositionBroadcaster(bufferSize int) *BusPositionBroadcaster {
+	if bufferSize <= 0 {
+		bufferSize = 1
+	}
+	return &BusPositionBroadcaster{
+		bufferSize: bufferSize,
+		lastID:     uint64(time.Now().UnixMilli()),
+		feeds:      make(map[string]*busPositionFeed),
+	}
+}
+
+// Publish sends positions of the bus line to its subscribers when they differ from the last event.
+func (broadcaster *BusPositionBroadcaster) Publish(busLineID string, busPositions []aggregate.BusPosition) {
+	broadcaster.mu.Lock()
+	defer broadcaster.mu.Unlock()
+
+	feed := broadcaster.feed(busLineID)
+	if len(feed.events) > 0 && sameBusPositions(feed.events[len(feed.events)-1].BusPositions, busPositions) {
+		return
+	}
+
+	broadcaster.lastID++
+	event := aggregate.BusPositionEvent{
+		ID:           broadcaster.lastID,
+		BusLineID:    busLineID,
+		BusPositions: busPositions,
+		PublishedAt:  time.Now(),
+	}
+	feed.events = append(feed.events, event)
+	if len(feed.events) > broadcaster.bufferSize {
+		feed.events = append([]aggregate.BusPositionEvent(nil), feed.events[len(feed.events)-broadcaster.bufferSize:]...)
+	}
+
+	for ch := range feed.subscribers {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
+
+// Subscribe returns the events to replay and a channel of the next events of the bus line.
+// Events after lastEventID are replayed when still kept, the latest event otherwise.
+// cancel must be called once the subscriber is gone, it drops the feed of a bus line nothing was published for.
+// Feeds are created for any ID, callers check the bus line beforehand.
+func (broadcaster *BusPositionBroadcaster) Subscribe(busLineID string, lastEventID uint64) (replay []aggregate.BusPositionEvent, events <-chan aggregate.BusPositionEvent, cancel func()) {
+	broadcaster.mu.Lock()
+	defer broadcaster.mu.Unlock()
+
+	feed := broadcaster.feed(busLineID)
+	if len(feed.events) > 0 {
+		first, last := feed.events[0].ID, feed.events[len(feed.events)-1].ID
+		switch {
+		case lastEventID == last:
+			// up to date
+		case lastEventID >= first && lastEventID < last:
+			idx := sort.Search(len(feed.events), func(i int) bool {
+				return feed.events[i].ID > lastEventID
+			})
+			replay = append(replay, feed.events[idx:]...)
+		default:
+			// unknown or too old to resume, events are snapshots so the latest one is enough
+			replay = append(replay, feed.events[len(feed.events)-1])
+		}
+	}
+
+	ch := make(chan aggregate.BusPositionEvent, subscriberBufferSize)
+	feed.subscribers[ch] = struct{}{}
+
+	cancel = func() {
+		broadcaster.mu.Lock()
+		defer broadcaster.mu.Unlock()
+		delete(feed.subscribers, ch)
+		// nothing to replay nor to send to, a bus line nobody watches is not kept
+		if len(feed.subscribers) == 0 && len(feed.events) == 0 && broadcaster.feeds[busLineID] == feed {
+			delete(broadcaster.feeds, busLineID)
+		}
+	}
+	return replay, ch, cancel
+}
+
+func (broadcaster *BusPositionBroadcaster) feed(busLineID string) *busPositionFeed {
+	feed, ok := broadcaster.feeds[busLineID]
+	if !ok {
+		feed = &busPositionFeed{
+			subscribers: make(map[chan aggregate.BusPositionEvent]struct{}),
+		}
+		broadcaster.feeds[busLineID] = feed
+	}
+	return feed
+}
+
+func sameBusPositions(a, b []aggregate.BusPosition) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	// positions fetched again without moving are the same
+	for i := range a {
+		if a[i].Bus != b[i].Bus || a[i].RunningBus != b[i].RunningBus || a[i].RunningBusPosition != b[i].RunningBusPosition {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,127 @@
+package service
+
+import (
+	"testing"
+
+	"bus-timing/internal/aggregate"
+	"bus-timing/internal/entity"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func mockBusPositions(vehiclePlates ...string) []aggregate.BusPosition {
+	busPositions := make([]aggregate.BusPosition, 0, len(vehiclePlates))
+	for _, vehiclePlate := range vehiclePlates {
+		busPositions = append(busPositions, aggregate.BusPosition{
+			Bus: entity.Bus{VehiclePlate: vehiclePlate},
+		})
+	}
+	return busPositions
+}
+
+func TestBusPositionBroadcaster_Publish(t *testing.T) {
+	t.Parallel()
+
+	t.Run("happy case: subscribers receive changed positions only", func(tt *testing.T) {
+		broadcaster := NewBusPositionBroadcaster(10)
+		replay, events, cancel := broadcaster.Subscribe("44480", 0)
+		defer cancel()
+		assert.Empty(tt, replay)
+
+		broadcaster.Publish("44480", mockBusPositions("PC1"))
+		broadcaster.Publish("44480", mockBusPositions("PC1"))
+		broadcaster.Publish("44481", mockBusPositions("PC2"))
+		broadcaster.Publish("44480", mockBusPositions("PC1", "PC3"))
+
+		first := <-events
+		second := <-events
+		assert.Equal(tt, mockBusPositions("PC1"), first.BusPositions)
+		assert.Equal(tt, mockBusPositions("PC1", "PC3"), second.BusPositions)
+		assert.Greater(tt, second.ID, first.ID)
+		assert.Len(tt, events, 0)
+	})
+
+	t.Run("happy case: cancelled subscriber receives nothing", func(tt *testing.T) {
+		broadcaster := NewBusPositionBroadcaster(10)
+		_, events, cancel := broadcaster.Subscribe("44480", 0)
+		cancel()
+
+		broadcaster.Publish("44480", mockBusPositions("PC1"))
+		assert.Len(tt, events, 0)
+	})
+}
+
+func TestBusPositionBroadcaster_Cancel(t *testing.T) {
+	t.Parallel()
+
+	t.Run("happy case: feeds nothing was published for are dropped with their last subscriber", func(tt *testing.T) {
+		broadcaster := NewBusPositionBroadcaster(10)
+		_, _, cancelFirst := broadcaster.Subscribe("44480", 0)
+		_, _, cancelSecond := broadcaster.Subscribe("44480", 0)
+
+		cancelFirst()
+		assert.Contains(tt, broadcaster.feeds, "44480")
+		cancelSecond()
+		assert.NotContains(tt, broadcaster.feeds, "44480")
+		// cancelled twice
+		cancelSecond()
+		assert.Empty(tt, broadcaster.feeds)
+	})
+
+	t.Run("happy case: published events are kept for resuming subscribers", func(tt *testing.T) {
+		broadcaster := NewBusPositionBroadcaster(10)
+		_, _, cancel := broadcaster.Subscribe("44480", 0)
+		broadcaster.Publish("44480", mockBusPositions("PC1"))
+		cancel()
+
+		replay, _, cancel := broadcaster.Subscribe("44480", 0)
+		defer cancel()
+		assert.Len(tt, replay, 1)
+	})
+
+	t.Run("happy case: a feed created again is kept for its subscribers", func(tt *testing.T) {
+		broadcaster := NewBusPositionBroadcaster(10)
+		_, _, cancelFirst := broadcaster.Subscribe("44480", 0)
+		cancelFirst()
+		_, events, cancelSecond := broadcaster.Subscribe("44480", 0)
+		defer cancelSecond()
+		// the first subscriber's feed is gone already, cancelling it again leaves the new one
+		cancelFirst()
+
+		broadcaster.Publish("44480", mockBusPositions("PC1"))
+		assert.Len(tt, events, 1)
+	})
+}
+
+func TestBusPositionBroadcaster_Subscribe(t *testing.T) {
+	t.Parallel()
+
+	broadcaster := NewBusPositionBroadcaster(2)
+	broadcaster.Publish("44480", mockBusPositions("PC1"))
+	broadcaster.Publish("44480", mockBusPositions("PC2"))
+	broadcaster.Publish("44480", mockBusPositions("PC3"))
+	latest, _, cancel := broadcaster.Subscribe("44480", 0)
+	cancel()
+	assert.Len(t, latest, 1)
+	lastID := latest[0].ID
+
+	t.Run("happy case: resume after a kept event", func(tt *testing.T) {
+		replay, _, cancel := broadcaster.Subscribe("44480", lastID-1)
+		defer cancel()
+		assert.Len(tt, replay, 1)
+		assert.Equal(tt, mockBusPositions("PC3"), replay[0].BusPositions)
+	})
+
+	t.Run("happy case: up to date", func(tt *testing.T) {
+		replay, _, cancel := broadcaster.Subscribe("44480", lastID)
+		defer cancel()
+		assert.Empty(tt, replay)
+	})
+
+	t.Run("happy case: too old to resume gets the latest event", func(tt *testing.T) {
+		replay, _, cancel := broadcaster.Subscribe("44480", lastID-2)
+		defer cancel()
+		assert.Len(tt, replay, 1)
+		assert.Equal(tt, lastID, replay[0].ID)
+	})
+}
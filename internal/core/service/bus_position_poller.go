@@ -0,0 +1,73 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"bus-timing/internal/aggregate"
+	"bus-timing/pkg/logging"
+	"bus-timing/pkg/metrics"
+)
+
+// BusPositionPoller fetches positions of every bus line on a fixed interval,
+// so positions are observed (and recorded) even when no client is asking for them.
+type BusPositionPoller struct {
+	Interval       time.Duration
+	BusLineService interface {
+		GetBusLines(ctx context.Context) ([]aggregate.BusLineBusStop, error)
+	}
+	BusPositionService interface {
+		GetBusPosition(ctx context.Context, busLineID string) ([]aggregate.BusPosition, error)
+	}
+	// BusPositionBroadcaster is optional, it is told about the positions of every bus line
+	BusPositionBroadcaster interface {
+		Publish(busLineID string, busPositions []aggregate.BusPosition)
+	}
+	Logger *slog.Logger
+}
+
+const defaultPollInterval = 10 * time.Second
+
+func (poller *BusPositionPoller) Run(ctx context.Context) {
+	interval := poller.Interval
+	if interval <= 0 {
+		interval = defaultPollInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		poller.poll(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (poller *BusPositionPoller) poll(ctx context.Context) {
+	busLines, err := poller.BusLineService.GetBusLines(ctx)
+	if err != nil {
+		logging.OrDefault(poller.Logger).ErrorContext(ctx, "poll bus lines", "error", err)
+		return
+	}
+
+	for _, busLine := range busLines {
+		if ctx.Err() != nil {
+			return
+		}
+		busPositions, err := poller.BusPositionService.GetBusPosition(ctx, busLine.BusLine.ID)
+		if err != nil {
+			logging.OrDefault(poller.Logger).WarnContext(ctx, "poll bus positions", "bus_line_id", busLine.BusLine.ID, "error", err)
+			continue
+		}
+		// only lines of the catalogue get a series, unknown IDs of requests would each add one
+		metrics.LiveBuses.WithLabelValues(busLine.BusLine.ID).Set(float64(len(busPositions)))
+		if poller.BusPositionBroadcaster != nil {
+			poller.BusPositionBroadcaster.Publish(busLine.BusLine.ID, busPositions)
+		}
+	}
+}
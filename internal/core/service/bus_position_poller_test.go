@@ -0,0 +1,59 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"bus-timing/pkg/metrics"
+	"bus-timing/pkg/uwave"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBusPositionPoller_poll(t *testing.T) {
+	t.Parallel()
+
+	t.Run("happy case: live buses of the catalogue lines", func(tt *testing.T) {
+		uwaveClient := mockUWaveClient{
+			getBusLines: func(ctx context.Context) (uwave.GetBusLineResponse, error) {
+				return uwave.GetBusLineResponse{Payload: []uwave.BusLinePayload{{ID: "poller-1"}, {ID: "poller-2"}}}, nil
+			},
+			getRunningBusByBusLineID: func(ctx context.Context, busLineID string) (uwave.GetRunningBusResponse, error) {
+				if busLineID == "poller-2" {
+					return uwave.GetRunningBusResponse{}, nil
+				}
+				return uwave.GetRunningBusResponse{Payload: []uwave.RunningBusPayload{{VehiclePlate: "PD1064Z"}, {VehiclePlate: "PD1065Z"}}}, nil
+			},
+		}
+		poller := BusPositionPoller{
+			BusLineService:     &BusLiveService{UWaveClient: uwaveClient},
+			BusPositionService: &BusPositionService{UWaveClient: uwaveClient},
+		}
+
+		poller.poll(context.Background())
+		assert.Equal(tt, float64(2), testutil.ToFloat64(metrics.LiveBuses.WithLabelValues("poller-1")))
+		assert.Equal(tt, float64(0), testutil.ToFloat64(metrics.LiveBuses.WithLabelValues("poller-2")))
+	})
+
+	t.Run("happy case: requests for other lines add no series", func(tt *testing.T) {
+		uwaveClient := mockUWaveClient{
+			getRunningBusByBusLineID: func(ctx context.Context, busLineID string) (uwave.GetRunningBusResponse, error) {
+				return uwave.GetRunningBusResponse{}, nil
+			},
+		}
+		_, err := (&BusPositionService{UWaveClient: uwaveClient}).GetBusPosition(context.Background(), "not-a-line")
+		assert.NoError(tt, err)
+
+		series, err := metrics.Registry.Gather()
+		assert.NoError(tt, err)
+		for _, family := range series {
+			if family.GetName() != "bus_timing_live_buses" {
+				continue
+			}
+			for _, metric := range family.GetMetric() {
+				assert.NotEqual(tt, "not-a-line", metric.GetLabel()[0].GetValue())
+			}
+		}
+	})
+}
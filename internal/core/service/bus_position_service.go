@@ -2,17 +2,27 @@ package service
 
 import (
 	"context"
+	"log/slog"
+	"time"
 
 	"bus-timing/internal/aggregate"
 	"bus-timing/internal/entity"
 	"bus-timing/pkg/common"
+	"bus-timing/pkg/logging"
 	"bus-timing/pkg/uwave"
 )
 
+type PositionHistoryRepository interface {
+	Save(ctx context.Context, records []aggregate.BusPositionRecord) error
+	FindByBusLineID(ctx context.Context, busLineID string, from, to time.Time) ([]aggregate.BusPositionRecord, error)
+}
+
 type BusPositionService struct {
 	UWaveClient interface {
 		GetRunningBusByBusLineID(ctx context.Context, busLineID string) (uwave.GetRunningBusResponse, error)
 	}
+	PositionHistoryRepository PositionHistoryRepository
+	Logger                    *slog.Logger
 }
 
 func (service *BusPositionService) GetBusPosition(ctx context.Context, busLineID string) ([]aggregate.BusPosition, error) {
@@ -22,9 +32,73 @@ func (service *BusPositionService) GetBusPosition(ctx context.Context, busLineID
 	}
 
 	runningBusPositions := toRunningBusPositionEntity(resp)
+	recordBusPositions(ctx, logging.OrDefault(service.Logger), service.PositionHistoryRepository, busLineID, runningBusPositions)
 	return runningBusPositions, nil
 }
 
+func (service *BusPositionService) GetBusPositionHistory(ctx context.Context, busLineID string, from, to time.Time) ([]aggregate.BusTrack, error) {
+	if service.PositionHistoryRepository == nil {
+		return nil, nil
+	}
+
+	records, err := service.PositionHistoryRepository.FindByBusLineID(ctx, busLineID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	return toBusTracks(records), nil
+}
+
+// recordBusPositions stores observed positions in the history at the time upstream answered them,
+// a failure must not break the caller
+func recordBusPositions(ctx context.Context, logger *slog.Logger, repo PositionHistoryRepository, busLineID string, busPositions []aggregate.BusPosition) {
+	if repo == nil || len(busPositions) == 0 {
+		return
+	}
+
+	now := time.Now()
+	records := make([]aggregate.BusPositionRecord, 0, len(busPositions))
+	for _, val := range busPositions {
+		recordedAt := val.UpdatedAt
+		if recordedAt.IsZero() {
+			recordedAt = now
+		}
+		records = append(records, aggregate.BusPositionRecord{
+			BusLineID:          busLineID,
+			Bus:                val.Bus,
+			RunningBusPosition: val.RunningBusPosition,
+			RecordedAt:         recordedAt,
+		})
+	}
+
+	if err := repo.Save(ctx, records); err != nil {
+		logger.ErrorContext(ctx, "record bus positions", "bus_line_id", busLineID, "error", err)
+	}
+}
+
+// toBusTracks groups records by vehicle, keeping the order vehicles were first seen
+func toBusTracks(records []aggregate.BusPositionRecord) []aggregate.BusTrack {
+	if len(records) == 0 {
+		return nil
+	}
+
+	tracks := make([]aggregate.BusTrack, 0)
+	trackIdx := make(map[string]int)
+	for _, record := range records {
+		idx, ok := trackIdx[record.Bus.VehiclePlate]
+		if !ok {
+			idx = len(tracks)
+			trackIdx[record.Bus.VehiclePlate] = idx
+			tracks = append(tracks, aggregate.BusTrack{
+				VehiclePlate: record.Bus.VehiclePlate,
+			})
+		}
+		tracks[idx].Positions = append(tracks[idx].Positions, record)
+	}
+
+	return tracks
+}
+
 func toRunningBusPositionEntity(object uwave.GetRunningBusResponse) []aggregate.BusPosition {
 	if len(object.Payload) == 0 {
 		return nil
@@ -49,6 +123,7 @@ func toRunningBusPositionEntity(object uwave.GetRunningBusResponse) []aggregate.
 			Bus:                bus,
 			RunningBus:         runningBus,
 			RunningBusPosition: runningBusPosition,
+			UpdatedAt:          object.FetchedAt,
 		})
 	}
 
@@ -0,0 +1,68 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"bus-timing/internal/aggregate"
+	"bus-timing/pkg/uwave"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type mockPositionHistoryRepository struct {
+	records []aggregate.BusPositionRecord
+}
+
+func (m *mockPositionHistoryRepository) Save(ctx context.Context, records []aggregate.BusPositionRecord) error {
+	m.records = append(m.records, records...)
+	return nil
+}
+
+func (m *mockPositionHistoryRepository) FindByBusLineID(ctx context.Context, busLineID string, from, to time.Time) ([]aggregate.BusPositionRecord, error) {
+	return m.records, nil
+}
+
+func TestBusPositionService_GetBusPosition(t *testing.T) {
+	t.Parallel()
+
+	t.Run("happy case: positions are recorded when upstream fetched them", func(tt *testing.T) {
+		fetchedAt := time.Date(2024, 3, 1, 8, 0, 0, 0, time.UTC)
+		repo := &mockPositionHistoryRepository{}
+		service := &BusPositionService{
+			UWaveClient: mockUWaveClient{
+				getRunningBusByBusLineID: func(ctx context.Context, busLineID string) (uwave.GetRunningBusResponse, error) {
+					return uwave.GetRunningBusResponse{Payload: []uwave.RunningBusPayload{{VehiclePlate: "PD1064Z"}}, FetchedAt: fetchedAt}, nil
+				},
+			},
+			PositionHistoryRepository: repo,
+		}
+
+		_, err := service.GetBusPosition(context.Background(), "44480")
+		assert.NoError(tt, err)
+		if assert.Len(tt, repo.records, 1) {
+			assert.Equal(tt, fetchedAt, repo.records[0].RecordedAt)
+			assert.Equal(tt, "44480", repo.records[0].BusLineID)
+		}
+	})
+
+	t.Run("happy case: positions without a fetch time are recorded now", func(tt *testing.T) {
+		repo := &mockPositionHistoryRepository{}
+		service := &BusPositionService{
+			UWaveClient: mockUWaveClient{
+				getRunningBusByBusLineID: func(ctx context.Context, busLineID string) (uwave.GetRunningBusResponse, error) {
+					return uwave.GetRunningBusResponse{Payload: []uwave.RunningBusPayload{{VehiclePlate: "PD1064Z"}}}, nil
+				},
+			},
+			PositionHistoryRepository: repo,
+		}
+
+		before := time.Now()
+		_, err := service.GetBusPosition(context.Background(), "44480")
+		assert.NoError(tt, err)
+		if assert.Len(tt, repo.records, 1) {
+			assert.False(tt, repo.records[0].RecordedAt.Before(before))
+		}
+	})
+}
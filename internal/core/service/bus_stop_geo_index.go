@@ -0,0 +1,55 @@
+package service
+
+import (
+	"sort"
+
+	"bus-timing/internal/aggregate"
+	"bus-timing/pkg/location"
+)
+
+// geohash cells of precision 6 are about 1.2km x 0.6km, small enough to keep candidates few
+const busStopGeohashPrecision = 6
+
+// busStopGeoIndex buckets bus stops by geohash cell, so a radius search only checks stops in covering cells.
+type busStopGeoIndex struct {
+	cells map[string][]aggregate.BusStopBusLines
+}
+
+func newBusStopGeoIndex(busStops []aggregate.BusStopBusLines) *busStopGeoIndex {
+	index := &busStopGeoIndex{
+		cells: make(map[string][]aggregate.BusStopBusLines),
+	}
+	for _, busStop := range busStops {
+		hash := location.EncodeGeohash(location.Location{Lat: busStop.BusStop.Lat, Lng: busStop.BusStop.Lng}, busStopGeohashPrecision)
+		index.cells[hash] = append(index.cells[hash], busStop)
+	}
+	return index
+}
+
+// nearby returns at most limit bus stops within radius meters of center, nearest first.
+func (index *busStopGeoIndex) nearby(center location.Location, radius float64, limit int) []aggregate.NearbyBusStop {
+	result := make([]aggregate.NearbyBusStop, 0)
+	for _, hash := range location.GeohashesInRadius(center, radius, busStopGeohashPrecision) {
+		for _, busStop := range index.cells[hash] {
+			distance := location.CalculateStraightLine(center, location.Location{Lat: busStop.BusStop.Lat, Lng: busStop.BusStop.Lng})
+			if distance > radius {
+				continue
+			}
+			result = append(result, aggregate.NearbyBusStop{
+				BusStop:  busStop,
+				Distance: distance,
+			})
+		}
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].Distance == result[j].Distance {
+			return result[i].BusStop.BusStop.ID < result[j].BusStop.BusStop.ID
+		}
+		return result[i].Distance < result[j].Distance
+	})
+	if limit > 0 && len(result) > limit {
+		result = result[:limit]
+	}
+	return result
+}
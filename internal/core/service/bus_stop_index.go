@@ -0,0 +1,52 @@
+package service
+
+import (
+	"bus-timing/internal/aggregate"
+)
+
+// busStopIndex looks bus stops and the bus lines serving them up by ID,
+// bus stops keep the order they first appear in the bus lines.
+type busStopIndex struct {
+	busStops []aggregate.BusStopBusLines
+	byID     map[string]int
+}
+
+func newBusStopIndex(busLinesBusStops []aggregate.BusLineBusStop) *busStopIndex {
+	index := &busStopIndex{
+		busStops: make([]aggregate.BusStopBusLines, 0),
+		byID:     make(map[string]int),
+	}
+
+	for _, val := range busLinesBusStops {
+		for order, busStop := range val.BusStops {
+			idx, ok := index.byID[busStop.ID]
+			if !ok {
+				idx = len(index.busStops)
+				index.byID[busStop.ID] = idx
+				index.busStops = append(index.busStops, aggregate.BusStopBusLines{
+					BusStop: busStop,
+				})
+			}
+
+			// a bus line passing the same stop twice is listed once, at its first stop order
+			busLines := index.busStops[idx].BusLines
+			if len(busLines) > 0 && busLines[len(busLines)-1].BusLine.ID == val.BusLine.ID {
+				continue
+			}
+			index.busStops[idx].BusLines = append(busLines, aggregate.ServedBusLine{
+				BusLine:   val.BusLine,
+				StopOrder: order + 1,
+			})
+		}
+	}
+
+	return index
+}
+
+func (index *busStopIndex) get(busStopID string) (aggregate.BusStopBusLines, bool) {
+	idx, ok := index.byID[busStopID]
+	if !ok {
+		return aggregate.BusStopBusLines{}, false
+	}
+	return index.busStops[idx], true
+}
@@ -0,0 +1,114 @@
+package service
+
+import (
+	"bus-timing/internal/aggregate"
+	"bus-timing/internal/entity"
+	"bus-timing/pkg/location"
+)
+
+// journeyGraph is the bus network seen by the journey planner: the stop sequence of every bus line
+// and walking links between bus stops close to each other.
+type journeyGraph struct {
+	busStops map[string]entity.BusStop
+	busLines map[string]entity.BusLine
+	// lineStops holds the bus stops of each bus line in order
+	lineStops map[string][]lineStop
+	// servedBy lists where each bus stop appears on bus lines
+	servedBy map[string][]lineStopRef
+	walks    map[string][]walkLink
+	geoIndex *busStopGeoIndex
+}
+
+type lineStop struct {
+	busStopID string
+	// distance in meters along the bus line path from the previous stop
+	distance float64
+}
+
+type lineStopRef struct {
+	busLineID string
+	idx       int
+}
+
+type walkLink struct {
+	busStopID string
+	distance  float64
+}
+
+func newJourneyGraph(busLinesBusStops []aggregate.BusLineBusStop, maxWalkingDistance float64) *journeyGraph {
+	graph := &journeyGraph{
+		busStops:  make(map[string]entity.BusStop),
+		busLines:  make(map[string]entity.BusLine),
+		lineStops: make(map[string][]lineStop),
+		servedBy:  make(map[string][]lineStopRef),
+		walks:     make(map[string][]walkLink),
+	}
+
+	for _, val := range busLinesBusStops {
+		busLine := val.BusLine
+		graph.busLines[busLine.ID] = busLine
+
+		stops := make([]lineStop, 0, len(val.BusStops))
+		prevPathIdx := -1
+		for idx, busStop := range val.BusStops {
+			graph.busStops[busStop.ID] = busStop
+			graph.servedBy[busStop.ID] = append(graph.servedBy[busStop.ID], lineStopRef{
+				busLineID: busLine.ID,
+				idx:       idx,
+			})
+
+			busStopLocation := location.Location{Lat: busStop.Lat, Lng: busStop.Lng}
+			pathIdx := findNearestPathPositionIndex(busLine, busStopLocation)
+			distance := 0.0
+			if idx > 0 {
+				prev := val.BusStops[idx-1]
+				distance = distanceAlongPath(busLine, location.Location{Lat: prev.Lat, Lng: prev.Lng}, prevPathIdx, busStopLocation, pathIdx)
+			}
+			prevPathIdx = pathIdx
+
+			stops = append(stops, lineStop{
+				busStopID: busStop.ID,
+				distance:  distance,
+			})
+		}
+		graph.lineStops[busLine.ID] = stops
+	}
+
+	graph.geoIndex = newBusStopGeoIndex(newBusStopIndex(busLinesBusStops).busStops)
+	if maxWalkingDistance <= 0 {
+		return graph
+	}
+	for busStopID, busStop := range graph.busStops {
+		nearbyBusStops := graph.geoIndex.nearby(location.Location{Lat: busStop.Lat, Lng: busStop.Lng}, maxWalkingDistance, 0)
+		for _, nearby := range nearbyBusStops {
+			if nearby.BusStop.BusStop.ID == busStopID {
+				continue
+			}
+			graph.walks[busStopID] = append(graph.walks[busStopID], walkLink{
+				busStopID: nearby.BusStop.BusStop.ID,
+				distance:  nearby.Distance,
+			})
+		}
+	}
+
+	return graph
+}
+
+// distanceAlongPath follows the bus line path between the path positions nearest to two consecutive stops,
+// falling back to a straight line when the path is missing or runs backward.
+func distanceAlongPath(busLine entity.BusLine, from location.Location, fromPathIdx int, to location.Location, toPathIdx int) float64 {
+	if fromPathIdx < 0 || toPathIdx < 0 || toPathIdx <= fromPathIdx {
+		return location.CalculateStraightLine(from, to)
+	}
+
+	paths := busLine.BusLinePaths
+	distance := location.CalculateStraightLine(from, location.Location{Lat: paths[fromPathIdx].Lat, Lng: paths[fromPathIdx].Lng})
+	for i := fromPathIdx; i < toPathIdx; i++ {
+		distance += location.CalculateStraightLine(
+			location.Location{Lat: paths[i].Lat, Lng: paths[i].Lng},
+			location.Location{Lat: paths[i+1].Lat, Lng: paths[i+1].Lng},
+		)
+	}
+	distance += location.CalculateStraightLine(location.Location{Lat: paths[toPathIdx].Lat, Lng: paths[toPathIdx].Lng}, to)
+	return distance
+}
@@ -0,0 +1,401 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+
+	"bus-timing/internal/aggregate"
+	"bus-timing/internal/entity"
+	"bus-timing/pkg/apperror"
+	"bus-timing/pkg/common"
+	"bus-timing/pkg/location"
+	"bus-timing/pkg/logging"
+)
+
+// maxAccessBusStops limits the bus stops around a coordinate considered to board or alight,
+// live arrivals are fetched for each of them.
+const maxAccessBusStops = 5
+
+// JourneyPlanner finds journeys between bus stops or coordinates over the bus line network,
+// its graph is rebuilt every time the bus line catalogue refreshes (see Rebuild).
+//
+// Journeys are searched in rounds, round n finds the fastest arrival at every bus stop riding n buses,
+// so each round adds a transfer only when it arrives earlier than with fewer transfers.
+type JourneyPlanner struct {
+	BusLineCatalogue interface {
+		GetBusLines(ctx context.Context) ([]aggregate.BusLineBusStop, error)
+	}
+	RunningBusService interface {
+		EstimatedArrivalTime(ctx context.Context, busStopID string) ([]aggregate.IncomingBus, error)
+	}
+	// MaxWalkingDistance in meters between two bus stops to walk for a transfer
+	MaxWalkingDistance float64
+	// MaxAccessDistance in meters to walk from an origin coordinate to a bus stop, or from a bus stop to a destination coordinate
+	MaxAccessDistance float64
+	// DefaultWaitTime is the expected wait for a bus when no live position is known
+	DefaultWaitTime time.Duration
+	Logger          *slog.Logger
+
+	mu    sync.RWMutex
+	graph *journeyGraph
+}
+
+// journeyLabel is how a bus stop was reached in a round
+type journeyLabel struct {
+	arrival time.Duration
+	// mode is empty for a bus stop the journey starts from
+	mode       common.JourneyLegMode
+	fromStopID string
+	// ride only
+	busLineID string
+	wait      time.Duration
+	live      bool
+	// distance of a ride or a walk, from the origin for a bus stop the journey starts from
+	distance float64
+}
+
+func (planner *JourneyPlanner) Rebuild(busLines []aggregate.BusLineBusStop) {
+	graph := newJourneyGraph(busLines, planner.MaxWalkingDistance)
+
+	planner.mu.Lock()
+	defer planner.mu.Unlock()
+	planner.graph = graph
+}
+
+// PlanJourney returns the fastest journey between two places for every number of transfers
+// up to maxTransfers, as long as the extra transfer makes the journey faster. A coordinate is
+// reached on foot from the nearest bus stops, the wait for the first bus is estimated from live bus positions.
+func (planner *JourneyPlanner) PlanJourney(ctx context.Context, from, to aggregate.JourneyPlace, maxTransfers int) ([]aggregate.Journey, error) {
+	graph, err := planner.getGraph(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	origin, origins, err := planner.accessBusStops(graph, from)
+	if err != nil {
+		return nil, err
+	}
+	destination, destinations, err := planner.accessBusStops(graph, to)
+	if err != nil {
+		return nil, err
+	}
+	if from.BusStopID != "" && from.BusStopID == to.BusStopID {
+		return nil, apperror.InvalidInput("origin and destination are the same bus stop: %s", from.BusStopID)
+	}
+
+	liveWaits := make(map[string]map[string]time.Duration, len(origins))
+	for busStopID := range origins {
+		if waits := planner.liveWaitTimes(ctx, busStopID); waits != nil {
+			liveWaits[busStopID] = waits
+		}
+	}
+	labels := planner.search(graph, origins, maxTransfers+1, liveWaits)
+
+	journeys := make([]aggregate.Journey, 0)
+	var fastest time.Duration
+	if walk, ok := planner.walkOnly(origin, destination); ok {
+		journeys = append(journeys, walk)
+		fastest = walk.Duration
+	}
+	for rides := range labels {
+		// a walk between the places is better than walking through bus stops
+		if rides == 0 && len(journeys) > 0 {
+			continue
+		}
+
+		alightStopID, arrival, ok := bestAlight(labels[rides], destinations)
+		if !ok || (len(journeys) > 0 && arrival >= fastest) {
+			continue
+		}
+		fastest = arrival
+		journeys = append(journeys, buildJourney(graph, labels, rides, alightStopID, origin, destination, destinations[alightStopID]))
+	}
+	return journeys, nil
+}
+
+// accessBusStops resolves a place, returning the bus stops to start or end a journey at with their walking distance
+func (planner *JourneyPlanner) accessBusStops(graph *journeyGraph, place aggregate.JourneyPlace) (entity.BusStop, map[string]float64, error) {
+	if place.BusStopID != "" {
+		busStop, ok := graph.busStops[place.BusStopID]
+		if !ok {
+			return entity.BusStop{}, nil, apperror.NotFound("cannot find bus stop with ID: %s", place.BusStopID)
+		}
+		return busStop, map[string]float64{busStop.ID: 0}, nil
+	}
+
+	busStops := make(map[string]float64)
+	for _, val := range graph.geoIndex.nearby(place.Location, planner.MaxAccessDistance, maxAccessBusStops) {
+		busStops[val.BusStop.BusStop.ID] = val.Distance
+	}
+	return entity.BusStop{Lat: place.Location.Lat, Lng: place.Location.Lng}, busStops, nil
+}
+
+// walkOnly returns a journey on foot when the places are within the access distance
+func (planner *JourneyPlanner) walkOnly(origin, destination entity.BusStop) (aggregate.Journey, bool) {
+	distance := location.CalculateStraightLine(location.Location{Lat: origin.Lat, Lng: origin.Lng}, location.Location{Lat: destination.Lat, Lng: destination.Lng})
+	if (origin.ID != "" && destination.ID != "") || distance > planner.MaxAccessDistance {
+		return aggregate.Journey{}, false
+	}
+
+	duration := common.WalkingDuration(distance)
+	return aggregate.Journey{
+		Legs: []aggregate.JourneyLeg{
+			{
+				Mode:     common.JourneyLegWalk,
+				From:     origin,
+				To:       destination,
+				Distance: distance,
+				Duration: duration,
+			},
+		},
+		Duration: duration,
+	}, true
+}
+
+// bestAlight returns the bus stop of round reaching the destination first, walking from it
+func bestAlight(round map[string]journeyLabel, destinations map[string]float64) (string, time.Duration, bool) {
+	bestStopID := ""
+	var best time.Duration
+	for _, busStopID := range sortedBusStopIDs(round) {
+		distance, ok := destinations[busStopID]
+		if !ok {
+			continue
+		}
+		arrival := round[busStopID].arrival + common.WalkingDuration(distance)
+		if bestStopID == "" || arrival < best {
+			bestStopID, best = busStopID, arrival
+		}
+	}
+	return bestStopID, best, bestStopID != ""
+}
+
+// search runs maxRides rounds from origins, labels[n] holds bus stops reached riding n buses
+// faster than with fewer rides.
+func (planner *JourneyPlanner) search(graph *journeyGraph, origins map[string]float64, maxRides int, liveWaits map[string]map[string]time.Duration) []map[string]journeyLabel {
+	best := make(map[string]time.Duration, len(origins))
+	labels := make([]map[string]journeyLabel, 0, maxRides+1)
+
+	round := make(map[string]journeyLabel, len(origins))
+	for busStopID, distance := range origins {
+		arrival := common.WalkingDuration(distance)
+		best[busStopID] = arrival
+		round[busStopID] = journeyLabel{
+			arrival:  arrival,
+			distance: distance,
+		}
+	}
+	planner.relaxWalks(graph, round, best)
+	labels = append(labels, round)
+
+	for rides := 1; rides <= maxRides; rides++ {
+		prev := labels[rides-1]
+		round = make(map[string]journeyLabel)
+
+		for _, busStopID := range sortedBusStopIDs(prev) {
+			label := prev[busStopID]
+			for _, ref := range graph.servedBy[busStopID] {
+				wait, live := planner.waitTime(label, liveWaits[busStopID], ref.busLineID)
+				boardAt := label.arrival + wait
+				distance := 0.0
+				stops := graph.lineStops[ref.busLineID]
+				for idx := ref.idx + 1; idx < len(stops); idx++ {
+					distance += stops[idx].distance
+					arrival := boardAt + common.TravelDuration(distance, common.MediumCrowd)
+					alightStopID := stops[idx].busStopID
+					if val, ok := best[alightStopID]; ok && val <= arrival {
+						continue
+					}
+
+					best[alightStopID] = arrival
+					round[alightStopID] = journeyLabel{
+						arrival:    arrival,
+						mode:       common.JourneyLegRide,
+						fromStopID: busStopID,
+						busLineID:  ref.busLineID,
+						wait:       wait,
+						live:       live,
+						distance:   distance,
+					}
+				}
+			}
+		}
+
+		if len(round) == 0 {
+			break
+		}
+		planner.relaxWalks(graph, round, best)
+		labels = append(labels, round)
+	}
+
+	return labels
+}
+
+// waitTime returns how long to wait for busLineID at a bus stop reached with label. Live positions
+// only tell when the first bus comes to a bus stop the journey starts from, the default wait time is
+// planned otherwise, also for bus lines with no running bus: one may set off before the rider boards.
+func (planner *JourneyPlanner) waitTime(label journeyLabel, liveWaits map[string]time.Duration, busLineID string) (wait time.Duration, live bool) {
+	if label.mode != "" || liveWaits == nil {
+		return planner.DefaultWaitTime, false
+	}
+
+	eta, ok := liveWaits[busLineID]
+	if !ok {
+		return planner.DefaultWaitTime, false
+	}
+	if eta < label.arrival {
+		// the bus leaves before the rider walks to the bus stop, take the next one
+		return planner.DefaultWaitTime, false
+	}
+	return eta - label.arrival, true
+}
+
+// relaxWalks adds bus stops reachable on foot from the bus stops reached in round
+func (planner *JourneyPlanner) relaxWalks(graph *journeyGraph, round map[string]journeyLabel, best map[string]time.Duration) {
+	for _, busStopID := range sortedBusStopIDs(round) {
+		label := round[busStopID]
+		for _, walk := range graph.walks[busStopID] {
+			arrival := label.arrival + common.WalkingDuration(walk.distance)
+			if val, ok := best[walk.busStopID]; ok && val <= arrival {
+				continue
+			}
+
+			best[walk.busStopID] = arrival
+			round[walk.busStopID] = journeyLabel{
+				arrival:    arrival,
+				mode:       common.JourneyLegWalk,
+				fromStopID: busStopID,
+				distance:   walk.distance,
+			}
+		}
+	}
+}
+
+// sortedBusStopIDs keeps the search deterministic when journeys tie
+func sortedBusStopIDs(round map[string]journeyLabel) []string {
+	busStopIDs := make([]string, 0, len(round))
+	for busStopID := range round {
+		busStopIDs = append(busStopIDs, busStopID)
+	}
+	sort.Strings(busStopIDs)
+	return busStopIDs
+}
+
+// buildJourney walks labels back from alightStopID reached riding rides buses,
+// adding the walks from origin and to destination.
+func buildJourney(graph *journeyGraph, labels []map[string]journeyLabel, rides int, alightStopID string, origin, destination entity.BusStop, egressDistance float64) aggregate.Journey {
+	legs := make([]aggregate.JourneyLeg, 0)
+	if egressDistance > 0 {
+		legs = append(legs, aggregate.JourneyLeg{
+			Mode:     common.JourneyLegWalk,
+			From:     graph.busStops[alightStopID],
+			To:       destination,
+			Distance: egressDistance,
+			Duration: common.WalkingDuration(egressDistance),
+		})
+	}
+
+	busStopID := alightStopID
+	for {
+		label := labels[rides][busStopID]
+		if label.mode == "" {
+			if label.distance > 0 {
+				legs = append(legs, aggregate.JourneyLeg{
+					Mode:     common.JourneyLegWalk,
+					From:     origin,
+					To:       graph.busStops[busStopID],
+					Distance: label.distance,
+					Duration: label.arrival,
+				})
+			}
+			break
+		}
+
+		switch label.mode {
+		case common.JourneyLegWalk:
+			legs = append(legs, aggregate.JourneyLeg{
+				Mode:     common.JourneyLegWalk,
+				From:     graph.busStops[label.fromStopID],
+				To:       graph.busStops[busStopID],
+				Distance: label.distance,
+				Duration: common.WalkingDuration(label.distance),
+			})
+		case common.JourneyLegRide:
+			busLine := graph.busLines[label.busLineID]
+			legs = append(legs, aggregate.JourneyLeg{
+				Mode:     common.JourneyLegRide,
+				From:     graph.busStops[label.fromStopID],
+				To:       graph.busStops[busStopID],
+				BusLine:  busLine,
+				Distance: label.distance,
+				Duration: common.TravelDuration(label.distance, common.MediumCrowd),
+			}, aggregate.JourneyLeg{
+				Mode:     common.JourneyLegWait,
+				From:     graph.busStops[label.fromStopID],
+				To:       graph.busStops[label.fromStopID],
+				BusLine:  busLine,
+				Duration: label.wait,
+				Live:     label.live,
+			})
+			rides--
+		}
+		busStopID = label.fromStopID
+	}
+
+	// legs were collected from the destination
+	for i, j := 0, len(legs)-1; i < j; i, j = i+1, j-1 {
+		legs[i], legs[j] = legs[j], legs[i]
+	}
+
+	journey := aggregate.Journey{
+		Legs: legs,
+	}
+	for _, leg := range legs {
+		journey.Duration += leg.Duration
+		if leg.Mode == common.JourneyLegRide {
+			journey.Transfers++
+		}
+	}
+	if journey.Transfers > 0 {
+		journey.Transfers--
+	}
+	return journey
+}
+
+// liveWaitTimes returns the wait for the nearest running bus of each bus line passing the bus stop,
+// nil when live positions are not available.
+func (planner *JourneyPlanner) liveWaitTimes(ctx context.Context, busStopID string) map[string]time.Duration {
+	incomingBuses, err := planner.RunningBusService.EstimatedArrivalTime(ctx, busStopID)
+	if err != nil {
+		logging.OrDefault(planner.Logger).WarnContext(ctx, "live arrivals, planning with the default wait time", "bus_stop_id", busStopID, "error", err)
+		return nil
+	}
+
+	waits := make(map[string]time.Duration, len(incomingBuses))
+	for _, val := range incomingBuses {
+		waits[val.BusLine.ID] = val.ArrivalTime
+	}
+	return waits
+}
+
+func (planner *JourneyPlanner) getGraph(ctx context.Context) (*journeyGraph, error) {
+	// reading the catalogue refreshes it when stale, which rebuilds the graph
+	busLines, err := planner.BusLineCatalogue.GetBusLines(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	planner.mu.RLock()
+	graph := planner.graph
+	planner.mu.RUnlock()
+	if graph != nil {
+		return graph, nil
+	}
+
+	planner.Rebuild(busLines)
+	planner.mu.RLock()
+	defer planner.mu.RUnlock()
+	return planner.graph, nil
+}
@@ -0,0 +1,198 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"bus-timing/internal/aggregate"
+	"bus-timing/internal/entity"
+	"bus-timing/pkg/apperror"
+	"bus-timing/pkg/common"
+	"bus-timing/pkg/location"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type mockRunningBusService struct {
+	estimatedArrivalTime func(ctx context.Context, busStopID string) ([]aggregate.IncomingBus, error)
+}
+
+func (m mockRunningBusService) EstimatedArrivalTime(ctx context.Context, busStopID string) ([]aggregate.IncomingBus, error) {
+	return m.estimatedArrivalTime(ctx, busStopID)
+}
+
+func legModes(journey aggregate.Journey) []common.JourneyLegMode {
+	modes := make([]common.JourneyLegMode, 0, len(journey.Legs))
+	for _, leg := range journey.Legs {
+		modes = append(modes, leg.Mode)
+	}
+	return modes
+}
+
+func TestJourneyPlanner_PlanJourney(t *testing.T) {
+	t.Parallel()
+
+	t.Run("happy case: direct bus with live wait", func(tt *testing.T) {
+		planner := &JourneyPlanner{
+			BusLineCatalogue: mockBusLineCatalogue(tt),
+			RunningBusService: mockRunningBusService{
+				estimatedArrivalTime: func(ctx context.Context, busStopID string) ([]aggregate.IncomingBus, error) {
+					return []aggregate.IncomingBus{
+						{
+							BusLine:     entity.BusLine{ID: "44481"},
+							BusPosition: entity.RunningBusPosition{CrowdLevel: common.LowCrowd},
+							Distance:    1000,
+							ArrivalTime: time.Minute,
+						},
+					}, nil
+				},
+			},
+			DefaultWaitTime: 5 * time.Minute,
+		}
+
+		resp, err := planner.PlanJourney(context.Background(), aggregate.JourneyPlace{BusStopID: "377906"}, aggregate.JourneyPlace{BusStopID: "378224"}, 2)
+		assert.NoError(tt, err)
+		assert.Len(tt, resp, 1)
+
+		journey := resp[0]
+		assert.Equal(tt, 0, journey.Transfers)
+		assert.Equal(tt, []common.JourneyLegMode{common.JourneyLegWait, common.JourneyLegRide}, legModes(journey))
+		assert.True(tt, journey.Legs[0].Live)
+		assert.Equal(tt, time.Minute, journey.Legs[0].Duration)
+		assert.Equal(tt, "44481", journey.Legs[1].BusLine.ID)
+		assert.Equal(tt, "378224", journey.Legs[1].To.ID)
+		assert.Equal(tt, journey.Legs[0].Duration+journey.Legs[1].Duration, journey.Duration)
+	})
+
+	t.Run("happy case: default wait for a bus line with no running bus", func(tt *testing.T) {
+		planner := &JourneyPlanner{
+			BusLineCatalogue: mockBusLineCatalogue(tt),
+			RunningBusService: mockRunningBusService{
+				estimatedArrivalTime: func(ctx context.Context, busStopID string) ([]aggregate.IncomingBus, error) {
+					// live arrivals of another bus line only
+					return []aggregate.IncomingBus{
+						{
+							BusLine:     entity.BusLine{ID: "44480"},
+							BusPosition: entity.RunningBusPosition{CrowdLevel: common.LowCrowd},
+							Distance:    1000,
+							ArrivalTime: time.Minute,
+						},
+					}, nil
+				},
+			},
+			DefaultWaitTime: 5 * time.Minute,
+		}
+
+		resp, err := planner.PlanJourney(context.Background(), aggregate.JourneyPlace{BusStopID: "377906"}, aggregate.JourneyPlace{BusStopID: "378224"}, 2)
+		assert.NoError(tt, err)
+		assert.Len(tt, resp, 1)
+
+		journey := resp[0]
+		assert.Equal(tt, []common.JourneyLegMode{common.JourneyLegWait, common.JourneyLegRide}, legModes(journey))
+		assert.False(tt, journey.Legs[0].Live)
+		assert.Equal(tt, 5*time.Minute, journey.Legs[0].Duration)
+		assert.Equal(tt, "44481", journey.Legs[1].BusLine.ID)
+	})
+
+	t.Run("happy case: transfer when no direct bus", func(tt *testing.T) {
+		planner := &JourneyPlanner{
+			BusLineCatalogue: mockBusLineCatalogue(tt),
+			RunningBusService: mockRunningBusService{
+				estimatedArrivalTime: func(ctx context.Context, busStopID string) ([]aggregate.IncomingBus, error) {
+					return nil, http.ErrServerClosed
+				},
+			},
+			DefaultWaitTime: 5 * time.Minute,
+		}
+
+		resp, err := planner.PlanJourney(context.Background(), aggregate.JourneyPlace{BusStopID: "383013"}, aggregate.JourneyPlace{BusStopID: "378202"}, 2)
+		assert.NoError(tt, err)
+		assert.Len(tt, resp, 1)
+
+		journey := resp[0]
+		assert.Equal(tt, 1, journey.Transfers)
+		assert.Equal(tt, []common.JourneyLegMode{common.JourneyLegWait, common.JourneyLegRide, common.JourneyLegWait, common.JourneyLegRide}, legModes(journey))
+		assert.False(tt, journey.Legs[0].Live)
+		assert.Equal(tt, "383013", journey.Legs[1].From.ID)
+		assert.Equal(tt, journey.Legs[1].To.ID, journey.Legs[3].From.ID)
+		assert.Equal(tt, "44478", journey.Legs[3].BusLine.ID)
+		assert.Equal(tt, "378202", journey.Legs[3].To.ID)
+
+		resp, err = planner.PlanJourney(context.Background(), aggregate.JourneyPlace{BusStopID: "383013"}, aggregate.JourneyPlace{BusStopID: "378202"}, 0)
+		assert.NoError(tt, err)
+		assert.Empty(tt, resp)
+	})
+
+	t.Run("happy case: coordinates walk to and from bus stops", func(tt *testing.T) {
+		planner := &JourneyPlanner{
+			BusLineCatalogue: mockBusLineCatalogue(tt),
+			RunningBusService: mockRunningBusService{
+				estimatedArrivalTime: func(ctx context.Context, busStopID string) ([]aggregate.IncomingBus, error) {
+					if busStopID != "377906" {
+						return nil, nil
+					}
+					return []aggregate.IncomingBus{
+						{
+							BusLine:     entity.BusLine{ID: "44481"},
+							BusPosition: entity.RunningBusPosition{CrowdLevel: common.LowCrowd},
+							Distance:    1000,
+							ArrivalTime: time.Minute,
+						},
+					}, nil
+				},
+			},
+			MaxAccessDistance: 300,
+			DefaultWaitTime:   5 * time.Minute,
+		}
+		// next to Pioneer MRT Station Exit B, and to LWN Library
+		from := aggregate.JourneyPlace{Location: location.Location{Lat: 1.33751, Lng: 103.69769}}
+		to := aggregate.JourneyPlace{Location: location.Location{Lat: 1.34843, Lng: 103.68055}}
+
+		resp, err := planner.PlanJourney(context.Background(), from, to, 2)
+		assert.NoError(tt, err)
+		assert.Len(tt, resp, 1)
+
+		journey := resp[0]
+		assert.Equal(tt, []common.JourneyLegMode{common.JourneyLegWalk, common.JourneyLegWait, common.JourneyLegRide, common.JourneyLegWalk}, legModes(journey))
+		assert.Empty(tt, journey.Legs[0].From.ID)
+		assert.Equal(tt, "377906", journey.Legs[0].To.ID)
+		assert.True(tt, journey.Legs[1].Live)
+		assert.Equal(tt, time.Minute, journey.Legs[0].Duration+journey.Legs[1].Duration)
+		assert.Equal(tt, "44481", journey.Legs[2].BusLine.ID)
+		assert.Equal(tt, "378224", journey.Legs[3].From.ID)
+		assert.Empty(tt, journey.Legs[3].To.ID)
+	})
+
+	t.Run("happy case: walk when places are close", func(tt *testing.T) {
+		planner := &JourneyPlanner{
+			BusLineCatalogue: mockBusLineCatalogue(tt),
+			RunningBusService: mockRunningBusService{
+				estimatedArrivalTime: func(ctx context.Context, busStopID string) ([]aggregate.IncomingBus, error) {
+					return nil, http.ErrServerClosed
+				},
+			},
+			MaxAccessDistance: 300,
+			DefaultWaitTime:   5 * time.Minute,
+		}
+		from := aggregate.JourneyPlace{Location: location.Location{Lat: 1.33751, Lng: 103.69769}}
+
+		resp, err := planner.PlanJourney(context.Background(), from, aggregate.JourneyPlace{BusStopID: "377906"}, 2)
+		assert.NoError(tt, err)
+		assert.Len(tt, resp, 1)
+		assert.Equal(tt, []common.JourneyLegMode{common.JourneyLegWalk}, legModes(resp[0]))
+		assert.Equal(tt, "377906", resp[0].Legs[0].To.ID)
+	})
+
+	t.Run("cannot find bus stop", func(tt *testing.T) {
+		planner := &JourneyPlanner{
+			BusLineCatalogue: mockBusLineCatalogue(tt),
+		}
+
+		resp, err := planner.PlanJourney(context.Background(), aggregate.JourneyPlace{BusStopID: "377906"}, aggregate.JourneyPlace{BusStopID: "-1"}, 2)
+		assert.Equal(tt, apperror.NotFound("cannot find bus stop with ID: %s", "-1"), err)
+		assert.Equal(tt, apperror.KindNotFound, apperror.KindOf(err))
+		assert.Nil(tt, resp)
+	})
+}
@@ -5,41 +5,47 @@ import (
 	"bus-timing/internal/entity"
 	"bus-timing/pkg/common"
 	"bus-timing/pkg/location"
+	"bus-timing/pkg/logging"
+	"bus-timing/pkg/metrics"
+	"bus-timing/pkg/tracing"
 	"bus-timing/pkg/uwave"
 	"context"
-	"fmt"
+	"log/slog"
 	"time"
+
+	"go.opentelemetry.io/otel/attribute"
 )
 
 type RunningBusService struct {
 	UWaveClient interface {
-		GetBusLines(ctx context.Context) (uwave.GetBusLineResponse, error)
 		GetRunningBusByBusLineID(ctx context.Context, busLineID string) (uwave.GetRunningBusResponse, error)
 	}
+	BusLineCatalogue interface {
+		GetBusStop(ctx context.Context, busStopID string) (aggregate.BusStopBusLines, error)
+	}
+	PositionHistoryRepository PositionHistoryRepository
+	Logger                    *slog.Logger
 }
 
-func (service *RunningBusService) EstimatedArrivalTime(ctx context.Context, busStopID string) ([]aggregate.IncomingBus, error) {
-	resp, err := service.UWaveClient.GetBusLines(ctx)
+func (service *RunningBusService) EstimatedArrivalTime(ctx context.Context, busStopID string) (_ []aggregate.IncomingBus, err error) {
+	defer metrics.ObserveDuration(metrics.ETAComputationDuration, time.Now())
+	ctx, span := tracing.Start(ctx, "RunningBusService.EstimatedArrivalTime", attribute.String("bus_stop.id", busStopID))
+	defer func() { tracing.End(span, err) }()
+
+	busStop, err := service.BusLineCatalogue.GetBusStop(ctx, busStopID)
 	if err != nil {
 		return nil, err
 	}
-
-	// get bus line data, return if no data
-	busLinesBusStops := toBusLinesBusStopAggregate(resp)
-	if busLinesBusStops == nil {
-		return nil, nil
-	}
-
-	busStopInfo := getBusStopInfo(busLinesBusStops, busStopID)
-	if busStopInfo == nil {
-		return nil, fmt.Errorf("cannot find bus stop with ID: %s", busStopID)
-	}
+	busStopInfo := &busStop.BusStop
 
 	// find bus line pass bus stop, return if no bus line existed
-	busLines := findBusLineByBusStopID(busLinesBusStops, busStopID)
-	if busLines == nil {
+	if len(busStop.BusLines) == 0 {
 		return nil, nil
 	}
+	busLines := make([]entity.BusLine, 0, len(busStop.BusLines))
+	for _, val := range busStop.BusLines {
+		busLines = append(busLines, val.BusLine)
+	}
 
 	incomingBus := []aggregate.IncomingBus{}
 	for _, busLine := range busLines {
@@ -49,6 +55,7 @@ func (service *RunningBusService) EstimatedArrivalTime(ctx context.Context, busS
 		}
 
 		runningBusPositions := toRunningBusPositionEntity(resp)
+		recordBusPositions(ctx, logging.OrDefault(service.Logger), service.PositionHistoryRepository, busLine.ID, runningBusPositions)
 		if len(runningBusPositions) == 0 {
 			continue
 		}
@@ -82,41 +89,14 @@ func (service *RunningBusService) EstimatedArrivalTime(ctx context.Context, busS
 			BusLine:     busLine,
 			BusPosition: nearestBus.RunningBusPosition,
 			Distance:    distance,
-			ArrivalTime: time.Duration(distance / common.MapCrowdLevelAndSpeed[nearestBus.RunningBusPosition.CrowdLevel]),
+			ArrivalTime: common.TravelDuration(distance, nearestBus.RunningBusPosition.CrowdLevel),
+			UpdatedAt:   nearestBus.UpdatedAt,
 		})
 	}
 
 	return incomingBus, nil
 }
 
-func getBusStopInfo(busLines []aggregate.BusLineBusStop, busStopID string) *entity.BusStop {
-	for _, v := range busLines {
-		for _, busStop := range v.BusStops {
-			if busStop.ID == busStopID {
-				return &busStop
-			}
-		}
-	}
-	return nil
-}
-
-func findBusLineByBusStopID(busLinesBusStops []aggregate.BusLineBusStop, busStopID string) []entity.BusLine {
-	if len(busLinesBusStops) == 0 {
-		return nil
-	}
-
-	busLines := make([]entity.BusLine, 0)
-	for _, val := range busLinesBusStops {
-		for _, busStop := range val.BusStops {
-			if busStop.ID == busStopID {
-				busLines = append(busLines, val.BusLine)
-				break
-			}
-		}
-	}
-	return busLines
-}
-
 func findNearestBusToBusStop(firstBusLinePath entity.BusLinePath, runningBusPositions []aggregate.BusPosition, busStop entity.BusStop) *aggregate.BusPosition {
 	if len(runningBusPositions) == 0 {
 		return nil
@@ -8,9 +8,11 @@ import (
 	"net/http"
 	"os"
 	"testing"
+	"time"
 
 	"bus-timing/internal/aggregate"
 	"bus-timing/internal/entity"
+	"bus-timing/pkg/apperror"
 	"bus-timing/pkg/common"
 	"bus-timing/pkg/location"
 	"bus-timing/pkg/uwave"
@@ -71,7 +73,7 @@ func TestRunningBusService_EstimatedArrivalTime(t *testing.T) {
 					ID: "44481",
 				},
 				Distance:    168,
-				ArrivalTime: 2,
+				ArrivalTime: 10 * time.Second,
 			},
 			{
 				Bus: entity.Bus{
@@ -87,12 +89,15 @@ func TestRunningBusService_EstimatedArrivalTime(t *testing.T) {
 					ID: "44480",
 				},
 				Distance:    169,
-				ArrivalTime: 2,
+				ArrivalTime: 10 * time.Second,
 			},
 		}
 
 		svc := &RunningBusService{
 			UWaveClient: uwaveClient,
+			BusLineCatalogue: &BusLineCatalogue{
+				BusLineService: &BusLiveService{UWaveClient: uwaveClient},
+			},
 		}
 
 		resp, err := svc.EstimatedArrivalTime(context.Background(), busStopID)
@@ -116,6 +121,9 @@ func TestRunningBusService_EstimatedArrivalTime(t *testing.T) {
 
 		svc := &RunningBusService{
 			UWaveClient: uwaveClient,
+			BusLineCatalogue: &BusLineCatalogue{
+				BusLineService: &BusLiveService{UWaveClient: uwaveClient},
+			},
 		}
 
 		resp, err := svc.EstimatedArrivalTime(context.Background(), busStopID)
@@ -135,10 +143,13 @@ func TestRunningBusService_EstimatedArrivalTime(t *testing.T) {
 				return busLinePosition, nil
 			},
 		}
-		expectedError := fmt.Errorf("cannot find bus stop with ID: %s", busStopID)
+		expectedError := apperror.NotFound("cannot find bus stop with ID: %s", busStopID)
 
 		svc := &RunningBusService{
 			UWaveClient: uwaveClient,
+			BusLineCatalogue: &BusLineCatalogue{
+				BusLineService: &BusLiveService{UWaveClient: uwaveClient},
+			},
 		}
 
 		resp, err := svc.EstimatedArrivalTime(context.Background(), busStopID)
@@ -162,6 +173,9 @@ func TestRunningBusService_EstimatedArrivalTime(t *testing.T) {
 
 		svc := &RunningBusService{
 			UWaveClient: uwaveClient,
+			BusLineCatalogue: &BusLineCatalogue{
+				BusLineService: &BusLiveService{UWaveClient: uwaveClient},
+			},
 		}
 
 		resp, err := svc.EstimatedArrivalTime(context.Background(), busStopID)
@@ -170,26 +184,37 @@ func TestRunningBusService_EstimatedArrivalTime(t *testing.T) {
 	})
 }
 
-func Test_findBusLineByBusStopID(t *testing.T) {
+func Test_busStopIndex(t *testing.T) {
 	t.Parallel()
 
 	t.Run("happy case", func(tt *testing.T) {
 		busLinesBusStops := mockBusLine()
 		busStopID := "377906"
 
-		expected := []entity.BusLine{
+		expected := []aggregate.ServedBusLine{
 			{
-				ID: "44481",
+				BusLine:   entity.BusLine{ID: "44481"},
+				StopOrder: 1,
 			},
 			{
-				ID: "44480",
+				BusLine:   entity.BusLine{ID: "44480"},
+				StopOrder: 1,
 			},
 		}
-		resp := findBusLineByBusStopID(busLinesBusStops, busStopID)
+		resp, ok := newBusStopIndex(busLinesBusStops).get(busStopID)
+		assert.True(tt, ok)
+		assert.Equal(tt, busStopID, resp.BusStop.ID)
+		assert.Len(tt, resp.BusLines, len(expected))
 		for i, val := range expected {
-			assert.Equal(tt, val.ID, resp[i].ID)
+			assert.Equal(tt, val.BusLine.ID, resp.BusLines[i].BusLine.ID)
+			assert.Equal(tt, val.StopOrder, resp.BusLines[i].StopOrder)
 		}
 	})
+
+	t.Run("cannot find bus stop", func(tt *testing.T) {
+		_, ok := newBusStopIndex(mockBusLine()).get("-1")
+		assert.False(tt, ok)
+	})
 }
 
 func Test_findNearestBusToBusStop(t *testing.T) {
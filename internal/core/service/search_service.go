@@ -0,0 +1,112 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"bus-timing/internal/aggregate"
+	"bus-timing/internal/entity"
+	"bus-timing/pkg/common"
+	"bus-timing/pkg/search"
+)
+
+// SearchService searches bus stops and bus lines by name, its index is rebuilt
+// every time the bus line catalogue refreshes (see Rebuild).
+type SearchService struct {
+	BusLineCatalogue interface {
+		GetBusLines(ctx context.Context) ([]aggregate.BusLineBusStop, error)
+	}
+
+	mu       sync.RWMutex
+	index    *search.Index
+	busStops map[string]entity.BusStop
+	busLines map[string]entity.BusLine
+}
+
+// document IDs are prefixed by their type, bus stops and bus lines may share IDs
+const (
+	busStopDocumentPrefix = "busStop:"
+	busLineDocumentPrefix = "busLine:"
+)
+
+func (service *SearchService) Rebuild(busLines []aggregate.BusLineBusStop) {
+	documents := make([]search.Document, 0)
+	busStopByID := make(map[string]entity.BusStop)
+	busLineByID := make(map[string]entity.BusLine)
+	for _, val := range busLines {
+		busLineByID[val.BusLine.ID] = val.BusLine
+		documents = append(documents, search.Document{
+			ID:   busLineDocumentPrefix + val.BusLine.ID,
+			Text: val.BusLine.FullName + " " + val.BusLine.ShortName,
+		})
+
+		for _, busStop := range val.BusStops {
+			if _, ok := busStopByID[busStop.ID]; ok {
+				continue
+			}
+			busStopByID[busStop.ID] = busStop
+			documents = append(documents, search.Document{
+				ID:   busStopDocumentPrefix + busStop.ID,
+				Text: busStop.Name,
+			})
+		}
+	}
+	index := search.NewIndex(documents)
+
+	service.mu.Lock()
+	defer service.mu.Unlock()
+	service.index = index
+	service.busStops = busStopByID
+	service.busLines = busLineByID
+}
+
+// Search returns at most limit bus stops and bus lines matching query, best first.
+// resultType filters the results when it is not empty.
+func (service *SearchService) Search(ctx context.Context, query string, resultType common.SearchResultType, limit int) ([]aggregate.SearchResult, error) {
+	// reading the catalogue refreshes it when stale, which rebuilds the index
+	busLines, err := service.BusLineCatalogue.GetBusLines(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	service.mu.RLock()
+	built := service.index != nil
+	service.mu.RUnlock()
+	if !built {
+		service.Rebuild(busLines)
+	}
+
+	service.mu.RLock()
+	defer service.mu.RUnlock()
+
+	results := make([]aggregate.SearchResult, 0)
+	// results of the other type are filtered out after ranking, so ask for all of them
+	for _, val := range service.index.Search(query, 0) {
+		var result aggregate.SearchResult
+		switch {
+		case strings.HasPrefix(val.ID, busStopDocumentPrefix):
+			result = aggregate.SearchResult{
+				Type:    common.SearchResultBusStop,
+				BusStop: service.busStops[strings.TrimPrefix(val.ID, busStopDocumentPrefix)],
+				Score:   val.Score,
+			}
+		case strings.HasPrefix(val.ID, busLineDocumentPrefix):
+			result = aggregate.SearchResult{
+				Type:    common.SearchResultBusLine,
+				BusLine: service.busLines[strings.TrimPrefix(val.ID, busLineDocumentPrefix)],
+				Score:   val.Score,
+			}
+		}
+		if resultType != "" && result.Type != resultType {
+			continue
+		}
+
+		results = append(results, result)
+		if len(results) == limit {
+			break
+		}
+	}
+
+	return results, nil
+}
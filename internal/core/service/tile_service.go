@@ -0,0 +1,245 @@
+package service
+
+import (
+	"container/list"
+	"context"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+
+	"bus-timing/internal/aggregate"
+	"bus-timing/pkg/location"
+	"bus-timing/pkg/mvt"
+)
+
+// TileService renders the bus network as Mapbox Vector Tiles. Rendered tiles are cached
+// until the bus line catalogue refreshes (see Rebuild).
+type TileService struct {
+	BusLineCatalogue interface {
+		GetBusLines(ctx context.Context) ([]aggregate.BusLineBusStop, error)
+	}
+	// MinBusStopZoom is the lowest zoom with bus stops, they clutter the map below it
+	MinBusStopZoom int
+	// CacheSize is the number of tiles kept, the least recently used are dropped first
+	CacheSize int
+
+	mu       sync.Mutex
+	network  *tileNetwork
+	tiles    map[mvt.Tile]*list.Element
+	recently *list.List
+}
+
+type cachedTile struct {
+	tile mvt.Tile
+	data []byte
+}
+
+// tileNetwork is the bus network of a catalogue refresh, with paths simplified per zoom
+type tileNetwork struct {
+	busLines []tileBusLine
+	busStops []tileBusStop
+
+	mu         sync.Mutex
+	simplified map[int][][]location.Location
+}
+
+type tileBusLine struct {
+	busLine   aggregate.BusLineBusStop
+	path      []location.Location
+	southWest location.Location
+	northEast location.Location
+}
+
+type tileBusStop struct {
+	location.Location
+	id         string
+	name       string
+	busLineIDs []string
+}
+
+const (
+	defaultTileCacheSize = 1024
+
+	busLineLayer = "busLines"
+	busStopLayer = "busStops"
+	// metersPerPixelAtEquator is the size of a pixel of a 256 pixels tile at zoom 0
+	metersPerPixelAtEquator = 156543.03392
+)
+
+// Rebuild drops the cached tiles, tiles are rendered from busLines from now on.
+func (service *TileService) Rebuild(busLines []aggregate.BusLineBusStop) {
+	network := newTileNetwork(busLines)
+
+	service.mu.Lock()
+	defer service.mu.Unlock()
+	service.network = network
+	service.tiles = make(map[mvt.Tile]*list.Element)
+	service.recently = list.New()
+}
+
+// GetTile returns the vector tile with the busLines and busStops layers.
+func (service *TileService) GetTile(ctx context.Context, tile mvt.Tile) ([]byte, error) {
+	// reading the catalogue refreshes it when stale, which drops the cached tiles
+	busLines, err := service.BusLineCatalogue.GetBusLines(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	service.mu.Lock()
+	if service.network == nil {
+		service.mu.Unlock()
+		service.Rebuild(busLines)
+		service.mu.Lock()
+	}
+	if element, ok := service.tiles[tile]; ok {
+		service.recently.MoveToFront(element)
+		data := element.Value.(*cachedTile).data
+		service.mu.Unlock()
+		return data, nil
+	}
+	network := service.network
+	service.mu.Unlock()
+
+	data := mvt.Encode(network.layers(tile, service.MinBusStopZoom))
+
+	service.mu.Lock()
+	defer service.mu.Unlock()
+	// the tile is not cached when the network was rebuilt while it was rendered
+	if service.network == network {
+		service.cache(tile, data)
+	}
+	return data, nil
+}
+
+func (service *TileService) cache(tile mvt.Tile, data []byte) {
+	if _, ok := service.tiles[tile]; ok {
+		return
+	}
+	size := service.CacheSize
+	if size <= 0 {
+		size = defaultTileCacheSize
+	}
+	for service.recently.Len() >= size {
+		oldest := service.recently.Back()
+		service.recently.Remove(oldest)
+		delete(service.tiles, oldest.Value.(*cachedTile).tile)
+	}
+	service.tiles[tile] = service.recently.PushFront(&cachedTile{tile: tile, data: data})
+}
+
+func newTileNetwork(busLines []aggregate.BusLineBusStop) *tileNetwork {
+	network := &tileNetwork{simplified: make(map[int][][]location.Location)}
+
+	busStopByID := make(map[string]*tileBusStop)
+	for _, val := range busLines {
+		busLine := tileBusLine{
+			busLine:   val,
+			path:      make([]location.Location, 0, len(val.BusLine.BusLinePaths)),
+			southWest: location.Location{Lat: math.Inf(1), Lng: math.Inf(1)},
+			northEast: location.Location{Lat: math.Inf(-1), Lng: math.Inf(-1)},
+		}
+		for _, point := range val.BusLine.BusLinePaths {
+			busLine.path = append(busLine.path, location.Location{Lat: point.Lat, Lng: point.Lng})
+			busLine.southWest.Lat = math.Min(busLine.southWest.Lat, point.Lat)
+			busLine.southWest.Lng = math.Min(busLine.southWest.Lng, point.Lng)
+			busLine.northEast.Lat = math.Max(busLine.northEast.Lat, point.Lat)
+			busLine.northEast.Lng = math.Max(busLine.northEast.Lng, point.Lng)
+		}
+		network.busLines = append(network.busLines, busLine)
+
+		for _, busStop := range val.BusStops {
+			stop, ok := busStopByID[busStop.ID]
+			if !ok {
+				stop = &tileBusStop{
+					Location: location.Location{Lat: busStop.Lat, Lng: busStop.Lng},
+					id:       busStop.ID,
+					name:     busStop.Name,
+				}
+				busStopByID[busStop.ID] = stop
+			}
+			stop.busLineIDs = append(stop.busLineIDs, val.BusLine.ID)
+		}
+	}
+
+	for _, stop := range busStopByID {
+		network.busStops = append(network.busStops, *stop)
+	}
+	sort.Slice(network.busStops, func(i, j int) bool {
+		return network.busStops[i].id < network.busStops[j].id
+	})
+	return network
+}
+
+// paths returns the paths of the bus lines simplified to a pixel at zoom
+func (network *tileNetwork) paths(zoom int) [][]location.Location {
+	network.mu.Lock()
+	defer network.mu.Unlock()
+	if paths, ok := network.simplified[zoom]; ok {
+		return paths
+	}
+
+	paths := make([][]location.Location, 0, len(network.busLines))
+	for _, busLine := range network.busLines {
+		if len(busLine.path) == 0 {
+			paths = append(paths, nil)
+			continue
+		}
+		lat := (busLine.southWest.Lat + busLine.northEast.Lat) / 2
+		tolerance := metersPerPixelAtEquator * math.Cos(lat*math.Pi/180) / math.Exp2(float64(zoom))
+		paths = append(paths, location.Simplify(busLine.path, tolerance))
+	}
+	network.simplified[zoom] = paths
+	return paths
+}
+
+func (network *tileNetwork) layers(tile mvt.Tile, minBusStopZoom int) []mvt.Layer {
+	southWest, northEast := tile.Bounds()
+
+	busLineFeatures := make([]mvt.Feature, 0)
+	paths := network.paths(tile.Z)
+	for i, busLine := range network.busLines {
+		if busLine.northEast.Lat < southWest.Lat || busLine.southWest.Lat > northEast.Lat ||
+			busLine.northEast.Lng < southWest.Lng || busLine.southWest.Lng > northEast.Lng {
+			continue
+		}
+		parts := tile.ClipLine(paths[i])
+		if len(parts) == 0 {
+			continue
+		}
+		busLineFeatures = append(busLineFeatures, mvt.Feature{
+			Type:     mvt.LineString,
+			Geometry: parts,
+			Properties: map[string]interface{}{
+				"id":        busLine.busLine.BusLine.ID,
+				"fullName":  busLine.busLine.BusLine.FullName,
+				"shortName": busLine.busLine.BusLine.ShortName,
+				"origin":    busLine.busLine.BusLine.Origin,
+			},
+		})
+	}
+
+	busStopFeatures := make([]mvt.Feature, 0)
+	if tile.Z >= minBusStopZoom {
+		for _, busStop := range network.busStops {
+			coordinate := tile.Project(busStop.Location)
+			if !tile.Contains(coordinate) {
+				continue
+			}
+			busStopFeatures = append(busStopFeatures, mvt.Feature{
+				Type:     mvt.Point,
+				Geometry: [][]mvt.Coordinate{{coordinate}},
+				Properties: map[string]interface{}{
+					"id":         busStop.id,
+					"name":       busStop.name,
+					"busLineIDs": strings.Join(busStop.busLineIDs, ","),
+				},
+			})
+		}
+	}
+
+	return []mvt.Layer{
+		{Name: busLineLayer, Features: busLineFeatures},
+		{Name: busStopLayer, Features: busStopFeatures},
+	}
+}
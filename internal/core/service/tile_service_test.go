@@ -0,0 +1,89 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"bus-timing/internal/aggregate"
+	"bus-timing/pkg/location"
+	"bus-timing/pkg/mvt"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// layerNames returns the names of the layers of a vector tile
+func layerNames(tt *testing.T, data []byte) []string {
+	names := make([]string, 0)
+	for len(data) > 0 {
+		num, typ, n := protowire.ConsumeTag(data)
+		assert.Greater(tt, n, 0)
+		data = data[n:]
+		layer, n := protowire.ConsumeBytes(data)
+		assert.Equal(tt, protowire.Number(3), num)
+		assert.Equal(tt, protowire.BytesType, typ)
+		data = data[n:]
+
+		for len(layer) > 0 {
+			num, typ, n := protowire.ConsumeTag(layer)
+			layer = layer[n:]
+			n = protowire.ConsumeFieldValue(num, typ, layer)
+			if num == 1 {
+				name, _ := protowire.ConsumeString(layer)
+				names = append(names, name)
+			}
+			layer = layer[n:]
+		}
+	}
+	return names
+}
+
+func TestTileService_GetTile(t *testing.T) {
+	t.Parallel()
+
+	// Pioneer MRT Station Exit B, bus stop 377906
+	busStop := location.Location{Lat: 1.33781, Lng: 103.69739}
+
+	t.Run("happy case: bus stops from the minimum zoom", func(tt *testing.T) {
+		service := &TileService{
+			BusLineCatalogue: mockBusLineCatalogue(tt),
+			MinBusStopZoom:   14,
+		}
+
+		data, err := service.GetTile(context.Background(), mvt.TileAt(busStop, 16))
+		assert.NoError(tt, err)
+		assert.Equal(tt, []string{busLineLayer, busStopLayer}, layerNames(tt, data))
+
+		data, err = service.GetTile(context.Background(), mvt.TileAt(busStop, 10))
+		assert.NoError(tt, err)
+		assert.Equal(tt, []string{busLineLayer}, layerNames(tt, data))
+
+		data, err = service.GetTile(context.Background(), mvt.Tile{Z: 10, X: 0, Y: 0})
+		assert.NoError(tt, err)
+		assert.Empty(tt, data)
+	})
+
+	t.Run("happy case: tiles are cached until the catalogue refreshes", func(tt *testing.T) {
+		catalogue := mockBusLineCatalogue(tt)
+		service := &TileService{
+			BusLineCatalogue: catalogue,
+			CacheSize:        1,
+		}
+
+		tile := mvt.TileAt(busStop, 16)
+		_, err := service.GetTile(context.Background(), tile)
+		assert.NoError(tt, err)
+		assert.Contains(tt, service.tiles, tile)
+
+		_, err = service.GetTile(context.Background(), mvt.TileAt(busStop, 12))
+		assert.NoError(tt, err)
+		assert.NotContains(tt, service.tiles, tile)
+		assert.Len(tt, service.tiles, 1)
+
+		service.Rebuild([]aggregate.BusLineBusStop{})
+		assert.Empty(tt, service.tiles)
+		data, err := service.GetTile(context.Background(), tile)
+		assert.NoError(tt, err)
+		assert.Empty(tt, data)
+	})
+}
@@ -0,0 +1,164 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"bus-timing/internal/aggregate"
+	"bus-timing/pkg/apperror"
+
+	"github.com/pkg/errors"
+)
+
+// APIKeyRepository keeps API keys in memory. When a file path is given the keys
+// are also written to that file as a JSON array on every change, so they survive restarts.
+type APIKeyRepository struct {
+	filePath string
+
+	mu   sync.RWMutex
+	keys map[string]aggregate.APIKey
+	// byHash indexes the IDs of the keys by key hash
+	byHash map[string]string
+}
+
+type apiKeyRow struct {
+	ID         string    `json:"id"`
+	Name       string    `json:"name"`
+	KeyHash    string    `json:"keyHash"`
+	RateLimit  float64   `json:"rateLimit"`
+	Burst      int       `json:"burst"`
+	DailyQuota int64     `json:"dailyQuota"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+// NewAPIKeyRepository creates the repository, loading the keys file when filePath is not empty.
+func NewAPIKeyRepository(filePath string) (*APIKeyRepository, error) {
+	repo := &APIKeyRepository{
+		filePath: filePath,
+		keys:     make(map[string]aggregate.APIKey),
+		byHash:   make(map[string]string),
+	}
+	if filePath == "" {
+		return repo, nil
+	}
+
+	data, err := os.ReadFile(filePath)
+	if os.IsNotExist(err) {
+		return repo, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "APIKeyRepository.load")
+	}
+	rows := []apiKeyRow{}
+	if err := json.Unmarshal(data, &rows); err != nil {
+		return nil, errors.Wrap(err, "APIKeyRepository.load")
+	}
+	for _, row := range rows {
+		repo.keys[row.ID] = aggregate.APIKey(row)
+		repo.byHash[row.KeyHash] = row.ID
+	}
+	return repo, nil
+}
+
+// Create saves a new key, its ID and creation time are set by the repository.
+func (repo *APIKeyRepository) Create(ctx context.Context, apiKey aggregate.APIKey) (aggregate.APIKey, error) {
+	id, err := newID()
+	if err != nil {
+		return aggregate.APIKey{}, errors.Wrap(err, "APIKeyRepository.Create")
+	}
+	apiKey.ID = id
+	apiKey.CreatedAt = time.Now()
+
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	repo.keys[apiKey.ID] = apiKey
+	repo.byHash[apiKey.KeyHash] = apiKey.ID
+	if err := repo.persist(); err != nil {
+		delete(repo.keys, apiKey.ID)
+		delete(repo.byHash, apiKey.KeyHash)
+		return aggregate.APIKey{}, err
+	}
+	return apiKey, nil
+}
+
+func (repo *APIKeyRepository) GetByHash(ctx context.Context, keyHash string) (aggregate.APIKey, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	id, ok := repo.byHash[keyHash]
+	if !ok {
+		return aggregate.APIKey{}, apperror.NotFound("api key not found")
+	}
+	return repo.keys[id], nil
+}
+
+// List returns every key, oldest first.
+func (repo *APIKeyRepository) List(ctx context.Context) ([]aggregate.APIKey, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	keys := make([]aggregate.APIKey, 0, len(repo.keys))
+	for _, apiKey := range repo.keys {
+		keys = append(keys, apiKey)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].CreatedAt.Equal(keys[j].CreatedAt) {
+			return keys[i].ID < keys[j].ID
+		}
+		return keys[i].CreatedAt.Before(keys[j].CreatedAt)
+	})
+	return keys, nil
+}
+
+func (repo *APIKeyRepository) Delete(ctx context.Context, apiKeyID string) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	previous, ok := repo.keys[apiKeyID]
+	if !ok {
+		return apperror.NotFound("api key not found: %s", apiKeyID)
+	}
+
+	delete(repo.keys, apiKeyID)
+	delete(repo.byHash, previous.KeyHash)
+	if err := repo.persist(); err != nil {
+		repo.keys[apiKeyID] = previous
+		repo.byHash[previous.KeyHash] = apiKeyID
+		return err
+	}
+	return nil
+}
+
+// persist is called with the repository locked, the file is replaced atomically.
+func (repo *APIKeyRepository) persist() error {
+	if repo.filePath == "" {
+		return nil
+	}
+
+	rows := make([]apiKeyRow, 0, len(repo.keys))
+	for _, apiKey := range repo.keys {
+		rows = append(rows, apiKeyRow(apiKey))
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		return rows[i].ID < rows[j].ID
+	})
+	data, err := json.Marshal(rows)
+	if err != nil {
+		return errors.Wrap(err, "APIKeyRepository.persist")
+	}
+
+	if err := os.MkdirAll(filepath.Dir(repo.filePath), 0o755); err != nil {
+		return errors.Wrap(err, "APIKeyRepository.persist")
+	}
+	tmpPath := repo.filePath + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0o600); err != nil {
+		return errors.Wrap(err, "APIKeyRepository.persist")
+	}
+	return errors.Wrap(os.Rename(tmpPath, repo.filePath), "APIKeyRepository.persist")
+}
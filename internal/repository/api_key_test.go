@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"bus-timing/internal/aggregate"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIKeyRepository(t *testing.T) {
+	t.Parallel()
+
+	t.Run("happy case: keys survive a restart", func(tt *testing.T) {
+		filePath := filepath.Join(tt.TempDir(), "api_keys.json")
+		repo, err := NewAPIKeyRepository(filePath)
+		assert.NoError(tt, err)
+
+		first, err := repo.Create(context.Background(), aggregate.APIKey{Name: "partner", KeyHash: "hash-1", RateLimit: 1, Burst: 2, DailyQuota: 100})
+		assert.NoError(tt, err)
+		assert.NotEmpty(tt, first.ID)
+		second, err := repo.Create(context.Background(), aggregate.APIKey{Name: "other", KeyHash: "hash-2"})
+		assert.NoError(tt, err)
+		assert.NoError(tt, repo.Delete(context.Background(), second.ID))
+
+		reloaded, err := NewAPIKeyRepository(filePath)
+		assert.NoError(tt, err)
+		apiKeys, err := reloaded.List(context.Background())
+		assert.NoError(tt, err)
+		assert.Len(tt, apiKeys, 1)
+		apiKey, err := reloaded.GetByHash(context.Background(), "hash-1")
+		assert.NoError(tt, err)
+		assert.Equal(tt, first.ID, apiKey.ID)
+		assert.Equal(tt, int64(100), apiKey.DailyQuota)
+	})
+
+	t.Run("bad case: unknown key", func(tt *testing.T) {
+		repo, err := NewAPIKeyRepository("")
+		assert.NoError(tt, err)
+
+		_, err = repo.GetByHash(context.Background(), "missing")
+		assert.Error(tt, err)
+		assert.Error(tt, repo.Delete(context.Background(), "missing"))
+	})
+}
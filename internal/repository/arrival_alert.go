@@ -0,0 +1,215 @@
+package repository
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"bus-timing/internal/aggregate"
+	"bus-timing/pkg/apperror"
+
+	"github.com/pkg/errors"
+)
+
+// ArrivalAlertRepository keeps arrival alerts in memory. When a file path is given the alerts
+// are also written to that file as a JSON array on every change, so they survive restarts.
+type ArrivalAlertRepository struct {
+	filePath string
+
+	mu     sync.RWMutex
+	alerts map[string]aggregate.ArrivalAlert
+}
+
+type arrivalAlertRow struct {
+	ID          string    `json:"id"`
+	Owner       string    `json:"owner"`
+	BusLineID   string    `json:"busLineID"`
+	BusStopID   string    `json:"busStopID"`
+	Threshold   int64     `json:"threshold"`
+	CallbackURL string    `json:"callbackURL"`
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+// NewArrivalAlertRepository creates the repository, loading the alerts file when filePath is not empty.
+func NewArrivalAlertRepository(filePath string) (*ArrivalAlertRepository, error) {
+	repo := &ArrivalAlertRepository{
+		filePath: filePath,
+		alerts:   make(map[string]aggregate.ArrivalAlert),
+	}
+	if filePath == "" {
+		return repo, nil
+	}
+
+	data, err := os.ReadFile(filePath)
+	if os.IsNotExist(err) {
+		return repo, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "ArrivalAlertRepository.load")
+	}
+	rows := []arrivalAlertRow{}
+	if err := json.Unmarshal(data, &rows); err != nil {
+		return nil, errors.Wrap(err, "ArrivalAlertRepository.load")
+	}
+	for _, row := range rows {
+		repo.alerts[row.ID] = toArrivalAlert(row)
+	}
+	return repo, nil
+}
+
+// Create saves a new alert, its ID and timestamps are set by the repository.
+func (repo *ArrivalAlertRepository) Create(ctx context.Context, alert aggregate.ArrivalAlert) (aggregate.ArrivalAlert, error) {
+	id, err := newID()
+	if err != nil {
+		return aggregate.ArrivalAlert{}, errors.Wrap(err, "ArrivalAlertRepository.Create")
+	}
+	now := time.Now()
+	alert.ID = id
+	alert.CreatedAt = now
+	alert.UpdatedAt = now
+
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	repo.alerts[alert.ID] = alert
+	if err := repo.persist(); err != nil {
+		delete(repo.alerts, alert.ID)
+		return aggregate.ArrivalAlert{}, err
+	}
+	return alert, nil
+}
+
+func (repo *ArrivalAlertRepository) Get(ctx context.Context, alertID string) (aggregate.ArrivalAlert, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	alert, ok := repo.alerts[alertID]
+	if !ok {
+		return aggregate.ArrivalAlert{}, apperror.NotFound("arrival alert not found: %s", alertID)
+	}
+	return alert, nil
+}
+
+// List returns every alert, oldest first.
+func (repo *ArrivalAlertRepository) List(ctx context.Context) ([]aggregate.ArrivalAlert, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	alerts := make([]aggregate.ArrivalAlert, 0, len(repo.alerts))
+	for _, alert := range repo.alerts {
+		alerts = append(alerts, alert)
+	}
+	sort.Slice(alerts, func(i, j int) bool {
+		if alerts[i].CreatedAt.Equal(alerts[j].CreatedAt) {
+			return alerts[i].ID < alerts[j].ID
+		}
+		return alerts[i].CreatedAt.Before(alerts[j].CreatedAt)
+	})
+	return alerts, nil
+}
+
+// Update replaces the alert with the same ID, keeping its creation time.
+func (repo *ArrivalAlertRepository) Update(ctx context.Context, alert aggregate.ArrivalAlert) (aggregate.ArrivalAlert, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	previous, ok := repo.alerts[alert.ID]
+	if !ok {
+		return aggregate.ArrivalAlert{}, apperror.NotFound("arrival alert not found: %s", alert.ID)
+	}
+	alert.CreatedAt = previous.CreatedAt
+	alert.UpdatedAt = time.Now()
+
+	repo.alerts[alert.ID] = alert
+	if err := repo.persist(); err != nil {
+		repo.alerts[alert.ID] = previous
+		return aggregate.ArrivalAlert{}, err
+	}
+	return alert, nil
+}
+
+func (repo *ArrivalAlertRepository) Delete(ctx context.Context, alertID string) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	previous, ok := repo.alerts[alertID]
+	if !ok {
+		return apperror.NotFound("arrival alert not found: %s", alertID)
+	}
+
+	delete(repo.alerts, alertID)
+	if err := repo.persist(); err != nil {
+		repo.alerts[alertID] = previous
+		return err
+	}
+	return nil
+}
+
+// persist is called with the repository locked, the file is replaced atomically.
+func (repo *ArrivalAlertRepository) persist() error {
+	if repo.filePath == "" {
+		return nil
+	}
+
+	rows := make([]arrivalAlertRow, 0, len(repo.alerts))
+	for _, alert := range repo.alerts {
+		rows = append(rows, toArrivalAlertRow(alert))
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		return rows[i].ID < rows[j].ID
+	})
+	data, err := json.Marshal(rows)
+	if err != nil {
+		return errors.Wrap(err, "ArrivalAlertRepository.persist")
+	}
+
+	if err := os.MkdirAll(filepath.Dir(repo.filePath), 0o755); err != nil {
+		return errors.Wrap(err, "ArrivalAlertRepository.persist")
+	}
+	tmpPath := repo.filePath + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0o644); err != nil {
+		return errors.Wrap(err, "ArrivalAlertRepository.persist")
+	}
+	return errors.Wrap(os.Rename(tmpPath, repo.filePath), "ArrivalAlertRepository.persist")
+}
+
+func newID() (string, error) {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+func toArrivalAlertRow(alert aggregate.ArrivalAlert) arrivalAlertRow {
+	return arrivalAlertRow{
+		ID:          alert.ID,
+		Owner:       alert.Owner,
+		BusLineID:   alert.BusLineID,
+		BusStopID:   alert.BusStopID,
+		Threshold:   int64(alert.Threshold / time.Second),
+		CallbackURL: alert.CallbackURL,
+		CreatedAt:   alert.CreatedAt,
+		UpdatedAt:   alert.UpdatedAt,
+	}
+}
+
+func toArrivalAlert(row arrivalAlertRow) aggregate.ArrivalAlert {
+	return aggregate.ArrivalAlert{
+		ID:          row.ID,
+		Owner:       row.Owner,
+		BusLineID:   row.BusLineID,
+		BusStopID:   row.BusStopID,
+		Threshold:   time.Duration(row.Threshold) * time.Second,
+		CallbackURL: row.CallbackURL,
+		CreatedAt:   row.CreatedAt,
+		UpdatedAt:   row.UpdatedAt,
+	}
+}
@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"bus-timing/internal/aggregate"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestArrivalAlertRepository(t *testing.T) {
+	t.Parallel()
+
+	t.Run("happy case: alerts survive a restart", func(tt *testing.T) {
+		filePath := filepath.Join(tt.TempDir(), "alerts.json")
+		repo, err := NewArrivalAlertRepository(filePath)
+		assert.NoError(tt, err)
+
+		first, err := repo.Create(context.Background(), aggregate.ArrivalAlert{
+			Owner:       "partner",
+			BusLineID:   "44480",
+			BusStopID:   "377906",
+			Threshold:   5 * time.Minute,
+			CallbackURL: "https://example.com/hook",
+		})
+		assert.NoError(tt, err)
+		assert.NotEmpty(tt, first.ID)
+		second, err := repo.Create(context.Background(), aggregate.ArrivalAlert{
+			BusLineID:   "44481",
+			BusStopID:   "378204",
+			Threshold:   time.Minute,
+			CallbackURL: "https://example.com/hook",
+		})
+		assert.NoError(tt, err)
+
+		first.Threshold = 3 * time.Minute
+		_, err = repo.Update(context.Background(), first)
+		assert.NoError(tt, err)
+		assert.NoError(tt, repo.Delete(context.Background(), second.ID))
+
+		reloaded, err := NewArrivalAlertRepository(filePath)
+		assert.NoError(tt, err)
+		alerts, err := reloaded.List(context.Background())
+		assert.NoError(tt, err)
+		assert.Len(tt, alerts, 1)
+		assert.Equal(tt, first.ID, alerts[0].ID)
+		assert.Equal(tt, 3*time.Minute, alerts[0].Threshold)
+		assert.Equal(tt, "partner", alerts[0].Owner)
+	})
+
+	t.Run("bad case: unknown alert", func(tt *testing.T) {
+		repo, err := NewArrivalAlertRepository("")
+		assert.NoError(tt, err)
+
+		_, err = repo.Get(context.Background(), "missing")
+		assert.Error(tt, err)
+		_, err = repo.Update(context.Background(), aggregate.ArrivalAlert{ID: "missing"})
+		assert.Error(tt, err)
+		assert.Error(tt, repo.Delete(context.Background(), "missing"))
+	})
+}
@@ -0,0 +1,324 @@
+package repository
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"bus-timing/internal/aggregate"
+	"bus-timing/internal/entity"
+	"bus-timing/pkg/common"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// compactThreshold is how many pruned records the history file may keep before it is rewritten
+	compactThreshold = 10000
+	// compactInterval is how often the history file is rewritten when records were pruned
+	compactInterval = 24 * time.Hour
+)
+
+// PositionHistoryRepository keeps observed bus positions in memory, grouped by bus line.
+// When a file path is given every record is also appended to that file as a JSON line,
+// so the history survives restarts. The file is rewritten without the records older than
+// retention daily, or sooner when many were pruned.
+type PositionHistoryRepository struct {
+	retention        time.Duration
+	filePath         string
+	compactThreshold int
+
+	mu      sync.RWMutex
+	records map[string][]aggregate.BusPositionRecord
+	// latest position of each vehicle, keyed by bus line ID and vehicle plate
+	latest map[string]aggregate.BusPositionRecord
+	file   *os.File
+	// pruned is how many records of the file were pruned from memory since it was compacted
+	pruned      int
+	compactedAt time.Time
+}
+
+type positionRecordRow struct {
+	BusLineID    string    `json:"busLineID"`
+	VehiclePlate string    `json:"vehiclePlate"`
+	Bearing      float64   `json:"bearing"`
+	CrowdLevel   string    `json:"crowdLevel"`
+	Lat          float64   `json:"lat"`
+	Lng          float64   `json:"lng"`
+	RecordedAt   time.Time `json:"recordedAt"`
+}
+
+// NewPositionHistoryRepository creates the repository, loading and compacting the history file
+// when filePath is not empty. A zero retention keeps records forever.
+func NewPositionHistoryRepository(filePath string, retention time.Duration) (*PositionHistoryRepository, error) {
+	repo := &PositionHistoryRepository{
+		retention:        retention,
+		filePath:         filePath,
+		compactThreshold: compactThreshold,
+		records:          make(map[string][]aggregate.BusPositionRecord),
+		latest:           make(map[string]aggregate.BusPositionRecord),
+	}
+	if filePath == "" {
+		return repo, nil
+	}
+
+	if err := repo.load(filePath); err != nil {
+		return nil, errors.Wrap(err, "PositionHistoryRepository.load")
+	}
+
+	if err := os.MkdirAll(filepath.Dir(filePath), 0o755); err != nil {
+		return nil, errors.Wrap(err, "PositionHistoryRepository.open")
+	}
+	// rewrite the file with the records kept after applying retention
+	if err := repo.compact(time.Now()); err != nil {
+		return nil, err
+	}
+
+	return repo, nil
+}
+
+func (repo *PositionHistoryRepository) Save(ctx context.Context, records []aggregate.BusPositionRecord) error {
+	if len(records) == 0 {
+		return nil
+	}
+
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	saved := make([]aggregate.BusPositionRecord, 0, len(records))
+	for _, record := range records {
+		// positions served again from cache are not new observations
+		key := latestKey(record)
+		if latest, ok := repo.latest[key]; ok && samePosition(latest, record) {
+			continue
+		}
+		if latest, ok := repo.latest[key]; !ok || latest.RecordedAt.Before(record.RecordedAt) {
+			repo.latest[key] = record
+		}
+		saved = append(saved, record)
+
+		lineRecords := repo.records[record.BusLineID]
+		// concurrent observers may save slightly out of order, keep records sorted by time
+		idx := sort.Search(len(lineRecords), func(i int) bool {
+			return lineRecords[i].RecordedAt.After(record.RecordedAt)
+		})
+		lineRecords = append(lineRecords, aggregate.BusPositionRecord{})
+		copy(lineRecords[idx+1:], lineRecords[idx:])
+		lineRecords[idx] = record
+		repo.records[record.BusLineID] = lineRecords
+	}
+	now := time.Now()
+	repo.pruned += repo.prune(now)
+
+	if repo.file == nil {
+		return nil
+	}
+	if err := repo.appendToFile(saved); err != nil {
+		return err
+	}
+	if repo.pruned >= repo.compactThreshold || (repo.pruned > 0 && now.Sub(repo.compactedAt) >= compactInterval) {
+		return repo.compact(now)
+	}
+	return nil
+}
+
+// FindByBusLineID returns records of the bus line observed in [from, to], ordered by time.
+func (repo *PositionHistoryRepository) FindByBusLineID(ctx context.Context, busLineID string, from, to time.Time) ([]aggregate.BusPositionRecord, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	records := repo.records[busLineID]
+	start := sort.Search(len(records), func(i int) bool {
+		return !records[i].RecordedAt.Before(from)
+	})
+	end := sort.Search(len(records), func(i int) bool {
+		return records[i].RecordedAt.After(to)
+	})
+	if start >= end {
+		return nil, nil
+	}
+
+	result := make([]aggregate.BusPositionRecord, end-start)
+	copy(result, records[start:end])
+	return result, nil
+}
+
+func (repo *PositionHistoryRepository) Close() error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	if repo.file == nil {
+		return nil
+	}
+	err := repo.file.Close()
+	repo.file = nil
+	return err
+}
+
+func (repo *PositionHistoryRepository) load(filePath string) error {
+	file, err := os.Open(filePath)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		row := positionRecordRow{}
+		if err := json.Unmarshal(scanner.Bytes(), &row); err != nil {
+			// skip a line broken by an interrupted write
+			continue
+		}
+		record := toBusPositionRecord(row)
+		repo.records[row.BusLineID] = append(repo.records[row.BusLineID], record)
+		if latest, ok := repo.latest[latestKey(record)]; !ok || latest.RecordedAt.Before(record.RecordedAt) {
+			repo.latest[latestKey(record)] = record
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	for busLineID, records := range repo.records {
+		sort.SliceStable(records, func(i, j int) bool {
+			return records[i].RecordedAt.Before(records[j].RecordedAt)
+		})
+		repo.records[busLineID] = records
+	}
+	repo.prune(time.Now())
+	return nil
+}
+
+// prune drops the records older than retention and returns how many were dropped
+func (repo *PositionHistoryRepository) prune(now time.Time) int {
+	if repo.retention <= 0 {
+		return 0
+	}
+
+	threshold := now.Add(-repo.retention)
+	pruned := 0
+	for busLineID, records := range repo.records {
+		// records are sorted, most saves have nothing to prune
+		if len(records) == 0 || !records[0].RecordedAt.Before(threshold) {
+			continue
+		}
+		idx := sort.Search(len(records), func(i int) bool {
+			return !records[i].RecordedAt.Before(threshold)
+		})
+		pruned += idx
+		if idx == len(records) {
+			delete(repo.records, busLineID)
+			continue
+		}
+		// the head of the array is released when append next grows it
+		repo.records[busLineID] = records[idx:]
+	}
+	if pruned == 0 {
+		return 0
+	}
+	for key, record := range repo.latest {
+		if record.RecordedAt.Before(threshold) {
+			delete(repo.latest, key)
+		}
+	}
+	return pruned
+}
+
+// compact replaces the file with one holding the records in memory, then appends to it
+func (repo *PositionHistoryRepository) compact(now time.Time) error {
+	tmp, err := os.CreateTemp(filepath.Dir(repo.filePath), filepath.Base(repo.filePath)+".*.tmp")
+	if err != nil {
+		return errors.Wrap(err, "PositionHistoryRepository.compact")
+	}
+	// left behind only when the rename failed
+	defer os.Remove(tmp.Name())
+
+	for _, records := range repo.records {
+		if err := writeRecords(tmp, records); err != nil {
+			tmp.Close()
+			return errors.Wrap(err, "PositionHistoryRepository.compact")
+		}
+	}
+	if err := tmp.Chmod(0o644); err != nil {
+		tmp.Close()
+		return errors.Wrap(err, "PositionHistoryRepository.compact")
+	}
+	if err := tmp.Close(); err != nil {
+		return errors.Wrap(err, "PositionHistoryRepository.compact")
+	}
+	if err := os.Rename(tmp.Name(), repo.filePath); err != nil {
+		return errors.Wrap(err, "PositionHistoryRepository.compact")
+	}
+
+	file, err := os.OpenFile(repo.filePath, os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return errors.Wrap(err, "PositionHistoryRepository.open")
+	}
+	if repo.file != nil {
+		repo.file.Close()
+	}
+	repo.file = file
+	repo.pruned = 0
+	repo.compactedAt = now
+	return nil
+}
+
+func (repo *PositionHistoryRepository) appendToFile(records []aggregate.BusPositionRecord) error {
+	return errors.Wrap(writeRecords(repo.file, records), "PositionHistoryRepository.appendToFile")
+}
+
+func writeRecords(w io.Writer, records []aggregate.BusPositionRecord) error {
+	writer := bufio.NewWriter(w)
+	encoder := json.NewEncoder(writer)
+	for _, record := range records {
+		if err := encoder.Encode(toPositionRecordRow(record)); err != nil {
+			return err
+		}
+	}
+	return writer.Flush()
+}
+
+func latestKey(record aggregate.BusPositionRecord) string {
+	return record.BusLineID + "/" + record.Bus.VehiclePlate
+}
+
+func samePosition(a, b aggregate.BusPositionRecord) bool {
+	return a.Bus == b.Bus && a.RunningBusPosition == b.RunningBusPosition
+}
+
+func toPositionRecordRow(record aggregate.BusPositionRecord) positionRecordRow {
+	return positionRecordRow{
+		BusLineID:    record.BusLineID,
+		VehiclePlate: record.Bus.VehiclePlate,
+		Bearing:      record.Bus.Bearing,
+		CrowdLevel:   string(record.RunningBusPosition.CrowdLevel),
+		Lat:          record.RunningBusPosition.Lat,
+		Lng:          record.RunningBusPosition.Lng,
+		RecordedAt:   record.RecordedAt,
+	}
+}
+
+func toBusPositionRecord(row positionRecordRow) aggregate.BusPositionRecord {
+	return aggregate.BusPositionRecord{
+		BusLineID: row.BusLineID,
+		Bus: entity.Bus{
+			VehiclePlate: row.VehiclePlate,
+			Bearing:      row.Bearing,
+		},
+		RunningBusPosition: entity.RunningBusPosition{
+			Lat:        row.Lat,
+			Lng:        row.Lng,
+			CrowdLevel: common.CrowdLevel(row.CrowdLevel),
+		},
+		RecordedAt: row.RecordedAt,
+	}
+}
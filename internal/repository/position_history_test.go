@@ -0,0 +1,209 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"bus-timing/internal/aggregate"
+	"bus-timing/internal/entity"
+	"bus-timing/pkg/common"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func mockBusPositionRecord(busLineID, vehiclePlate string, lat float64, recordedAt time.Time) aggregate.BusPositionRecord {
+	return aggregate.BusPositionRecord{
+		BusLineID: busLineID,
+		Bus: entity.Bus{
+			VehiclePlate: vehiclePlate,
+			Bearing:      159.4,
+		},
+		RunningBusPosition: entity.RunningBusPosition{
+			Lat:        lat,
+			Lng:        103.695944,
+			CrowdLevel: common.LowCrowd,
+		},
+		RecordedAt: recordedAt,
+	}
+}
+
+func TestPositionHistoryRepository_FindByBusLineID(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now().Truncate(time.Second)
+
+	t.Run("happy case: records in range", func(tt *testing.T) {
+		repo, err := NewPositionHistoryRepository("", 0)
+		assert.NoError(tt, err)
+
+		err = repo.Save(context.Background(), []aggregate.BusPositionRecord{
+			mockBusPositionRecord("44480", "PD1064Z", 1.338066, now.Add(-3*time.Minute)),
+			mockBusPositionRecord("44480", "PD1064Z", 1.337651, now.Add(-time.Minute)),
+			mockBusPositionRecord("44481", "PD621Y", 1.354558, now.Add(-time.Minute)),
+		})
+		assert.NoError(tt, err)
+		// saved out of order
+		err = repo.Save(context.Background(), []aggregate.BusPositionRecord{
+			mockBusPositionRecord("44480", "PD698B", 1.346225, now.Add(-2*time.Minute)),
+		})
+		assert.NoError(tt, err)
+
+		resp, err := repo.FindByBusLineID(context.Background(), "44480", now.Add(-2*time.Minute), now)
+		assert.NoError(tt, err)
+		assert.Len(tt, resp, 2)
+		assert.Equal(tt, "PD698B", resp[0].Bus.VehiclePlate)
+		assert.Equal(tt, "PD1064Z", resp[1].Bus.VehiclePlate)
+	})
+
+	t.Run("unchanged position is not recorded again", func(tt *testing.T) {
+		repo, err := NewPositionHistoryRepository("", 0)
+		assert.NoError(tt, err)
+
+		for i := 2; i > 0; i-- {
+			err = repo.Save(context.Background(), []aggregate.BusPositionRecord{
+				mockBusPositionRecord("44480", "PD1064Z", 1.338066, now.Add(-time.Duration(i)*time.Minute)),
+			})
+			assert.NoError(tt, err)
+		}
+
+		resp, err := repo.FindByBusLineID(context.Background(), "44480", now.Add(-time.Hour), now)
+		assert.NoError(tt, err)
+		assert.Len(tt, resp, 1)
+	})
+
+	t.Run("records older than retention are dropped", func(tt *testing.T) {
+		repo, err := NewPositionHistoryRepository("", time.Hour)
+		assert.NoError(tt, err)
+
+		err = repo.Save(context.Background(), []aggregate.BusPositionRecord{
+			mockBusPositionRecord("44480", "PD1064Z", 1.338066, now.Add(-2*time.Hour)),
+			mockBusPositionRecord("44480", "PD698B", 1.346225, now.Add(-time.Minute)),
+		})
+		assert.NoError(tt, err)
+
+		resp, err := repo.FindByBusLineID(context.Background(), "44480", now.Add(-3*time.Hour), now)
+		assert.NoError(tt, err)
+		assert.Len(tt, resp, 1)
+		assert.Equal(tt, "PD698B", resp[0].Bus.VehiclePlate)
+	})
+
+	t.Run("records are reloaded from file", func(tt *testing.T) {
+		filePath := filepath.Join(tt.TempDir(), "history", "positions.jsonl")
+		repo, err := NewPositionHistoryRepository(filePath, 0)
+		assert.NoError(tt, err)
+
+		expected := mockBusPositionRecord("44480", "PD1064Z", 1.338066, now.Add(-time.Minute))
+		err = repo.Save(context.Background(), []aggregate.BusPositionRecord{expected})
+		assert.NoError(tt, err)
+		assert.NoError(tt, repo.Close())
+
+		reloaded, err := NewPositionHistoryRepository(filePath, 0)
+		assert.NoError(tt, err)
+		defer reloaded.Close()
+
+		resp, err := reloaded.FindByBusLineID(context.Background(), "44480", now.Add(-time.Hour), now)
+		assert.NoError(tt, err)
+		assert.Len(tt, resp, 1)
+		assert.Equal(tt, expected.Bus, resp[0].Bus)
+		assert.Equal(tt, expected.RunningBusPosition, resp[0].RunningBusPosition)
+		assert.True(tt, expected.RecordedAt.Equal(resp[0].RecordedAt))
+	})
+}
+
+func TestPositionHistoryRepository_Save(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now().Truncate(time.Second)
+	fileLines := func(tt *testing.T, filePath string) int {
+		data, err := os.ReadFile(filePath)
+		assert.NoError(tt, err)
+		return bytes.Count(data, []byte("\n"))
+	}
+
+	t.Run("happy case: the file is compacted once enough records are pruned", func(tt *testing.T) {
+		filePath := filepath.Join(tt.TempDir(), "positions.jsonl")
+		repo, err := NewPositionHistoryRepository(filePath, time.Hour)
+		assert.NoError(tt, err)
+		defer repo.Close()
+		repo.compactThreshold = 3
+
+		// records older than retention are appended, then pruned from memory
+		for i := 0; i < 2; i++ {
+			err = repo.Save(context.Background(), []aggregate.BusPositionRecord{
+				mockBusPositionRecord("44480", "PD1064Z", 1.33+float64(i)/100, now.Add(-time.Duration(3-i)*time.Hour)),
+			})
+			assert.NoError(tt, err)
+		}
+		assert.Equal(tt, 2, repo.pruned)
+		assert.Equal(tt, 2, fileLines(tt, filePath))
+
+		err = repo.Save(context.Background(), []aggregate.BusPositionRecord{
+			mockBusPositionRecord("44480", "PD1064Z", 1.33, now.Add(-90*time.Minute)),
+			mockBusPositionRecord("44480", "PD1064Z", 1.34, now.Add(-time.Minute)),
+			mockBusPositionRecord("44481", "PD621Y", 1.35, now.Add(-time.Minute)),
+		})
+		assert.NoError(tt, err)
+		assert.Zero(tt, repo.pruned)
+		assert.Equal(tt, 2, fileLines(tt, filePath))
+
+		// records are still appended after compacting
+		err = repo.Save(context.Background(), []aggregate.BusPositionRecord{
+			mockBusPositionRecord("44481", "PD621Y", 1.36, now),
+		})
+		assert.NoError(tt, err)
+		assert.Equal(tt, 3, fileLines(tt, filePath))
+		assert.NoError(tt, repo.Close())
+
+		reloaded, err := NewPositionHistoryRepository(filePath, time.Hour)
+		assert.NoError(tt, err)
+		defer reloaded.Close()
+		resp, err := reloaded.FindByBusLineID(context.Background(), "44481", now.Add(-time.Hour), now)
+		assert.NoError(tt, err)
+		assert.Len(tt, resp, 2)
+	})
+
+	t.Run("happy case: the file is compacted daily", func(tt *testing.T) {
+		filePath := filepath.Join(tt.TempDir(), "positions.jsonl")
+		repo, err := NewPositionHistoryRepository(filePath, time.Hour)
+		assert.NoError(tt, err)
+		defer repo.Close()
+
+		err = repo.Save(context.Background(), []aggregate.BusPositionRecord{
+			mockBusPositionRecord("44480", "PD1064Z", 1.33, now.Add(-2*time.Hour)),
+			mockBusPositionRecord("44480", "PD698B", 1.34, now.Add(-time.Minute)),
+		})
+		assert.NoError(tt, err)
+		// compacted at creation
+		assert.Equal(tt, 2, fileLines(tt, filePath))
+
+		repo.compactedAt = now.Add(-compactInterval)
+		err = repo.Save(context.Background(), []aggregate.BusPositionRecord{
+			mockBusPositionRecord("44480", "PD698B", 1.35, now),
+		})
+		assert.NoError(tt, err)
+		assert.Equal(tt, 2, fileLines(tt, filePath))
+	})
+
+	t.Run("happy case: records are pruned in place", func(tt *testing.T) {
+		repo, err := NewPositionHistoryRepository("", time.Hour)
+		assert.NoError(tt, err)
+
+		err = repo.Save(context.Background(), []aggregate.BusPositionRecord{
+			mockBusPositionRecord("44480", "PD1064Z", 1.33, now.Add(-30*time.Minute)),
+			mockBusPositionRecord("44480", "PD698B", 1.34, now.Add(-20*time.Minute)),
+		})
+		assert.NoError(tt, err)
+		before := repo.records["44480"]
+
+		assert.Zero(tt, repo.prune(now))
+		assert.Equal(tt, &before[0], &repo.records["44480"][0])
+
+		assert.Equal(tt, 1, repo.prune(now.Add(35*time.Minute)))
+		assert.Equal(tt, &before[1], &repo.records["44480"][0])
+		assert.NotContains(tt, repo.latest, "44480/PD1064Z")
+	})
+}
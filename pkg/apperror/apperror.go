@@ -0,0 +1,105 @@
+package apperror
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Kind tells why a request failed, it is what clients can rely on
+type Kind string
+
+const (
+	KindInvalidInput        Kind = "invalid_input"
+	KindNotFound            Kind = "not_found"
+	KindUnauthorized        Kind = "unauthorized"
+	KindForbidden           Kind = "forbidden"
+	KindRateLimited         Kind = "rate_limited"
+	KindUpstreamUnavailable Kind = "upstream_unavailable"
+	KindTimeout             Kind = "timeout"
+	KindInternal            Kind = "internal"
+)
+
+// Error is an error of a known kind, Message is safe to show to clients.
+type Error struct {
+	Kind    Kind
+	Message string
+	Err     error
+	// RetryAfter is how long clients should wait before retrying, for rate limited errors
+	RetryAfter time.Duration
+}
+
+func (e *Error) Error() string {
+	if e.Err == nil {
+		return e.Message
+	}
+	return fmt.Sprintf("%s: %s", e.Message, e.Err)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+func InvalidInput(format string, args ...interface{}) error {
+	return &Error{Kind: KindInvalidInput, Message: fmt.Sprintf(format, args...)}
+}
+
+func NotFound(format string, args ...interface{}) error {
+	return &Error{Kind: KindNotFound, Message: fmt.Sprintf(format, args...)}
+}
+
+func Unauthorized(format string, args ...interface{}) error {
+	return &Error{Kind: KindUnauthorized, Message: fmt.Sprintf(format, args...)}
+}
+
+func Forbidden(format string, args ...interface{}) error {
+	return &Error{Kind: KindForbidden, Message: fmt.Sprintf(format, args...)}
+}
+
+func RateLimited(retryAfter time.Duration, format string, args ...interface{}) error {
+	return &Error{Kind: KindRateLimited, Message: fmt.Sprintf(format, args...), RetryAfter: retryAfter}
+}
+
+// Upstream wraps the failure of a call to another service, a deadline is reported as a timeout.
+func Upstream(err error, format string, args ...interface{}) error {
+	kind := KindUpstreamUnavailable
+	if errors.Is(err, context.DeadlineExceeded) {
+		kind = KindTimeout
+	}
+	return &Error{Kind: kind, Message: fmt.Sprintf(format, args...), Err: err}
+}
+
+// KindOf returns the kind of the first Error in the chain of err,
+// errors without a kind are internal unless a deadline was exceeded.
+func KindOf(err error) Kind {
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr.Kind
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return KindTimeout
+	}
+	return KindInternal
+}
+
+// MessageOf returns the message of the first Error in the chain of err, internal errors are not detailed to clients.
+func MessageOf(err error) string {
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr.Message
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return "request timed out"
+	}
+	return "internal error"
+}
+
+// RetryAfterOf returns how long to wait before retrying, 0 when err does not tell.
+func RetryAfterOf(err error) time.Duration {
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr.RetryAfter
+	}
+	return 0
+}
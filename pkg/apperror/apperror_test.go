@@ -0,0 +1,32 @@
+package apperror
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKindOf(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name    string
+		err     error
+		kind    Kind
+		message string
+	}{
+		{"not found", NotFound("cannot find bus stop with ID: %s", "1"), KindNotFound, "cannot find bus stop with ID: 1"},
+		{"wrapped", errors.Wrap(InvalidInput("invalid limit: %d", 0), "Port.GetBusStops"), KindInvalidInput, "invalid limit: 0"},
+		{"upstream", Upstream(fmt.Errorf("connection refused"), "uWave bus lines"), KindUpstreamUnavailable, "uWave bus lines"},
+		{"upstream deadline", Upstream(context.DeadlineExceeded, "uWave bus lines"), KindTimeout, "uWave bus lines"},
+		{"deadline", errors.Wrap(context.DeadlineExceeded, "RunningBusService"), KindTimeout, "request timed out"},
+		{"unknown", fmt.Errorf("disk full"), KindInternal, "internal error"},
+	}
+	for _, c := range cases {
+		assert.Equal(t, c.kind, KindOf(c.err), c.name)
+		assert.Equal(t, c.message, MessageOf(c.err), c.name)
+	}
+}
@@ -0,0 +1,33 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryCache_Run(t *testing.T) {
+	t.Parallel()
+
+	t.Run("happy case: expired entries are swept without being read", func(tt *testing.T) {
+		c := NewMemoryCache()
+		assert.NoError(tt, c.Set(context.Background(), "expiring", []byte("1"), time.Millisecond))
+		assert.NoError(tt, c.Set(context.Background(), "kept", []byte("2"), 0))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go c.Run(ctx, time.Millisecond)
+
+		assert.Eventually(tt, func() bool {
+			c.mu.RLock()
+			defer c.mu.RUnlock()
+			_, ok := c.items["expiring"]
+			return !ok
+		}, time.Second, time.Millisecond)
+		value, err := c.Get(context.Background(), "kept")
+		assert.NoError(tt, err)
+		assert.Equal(tt, []byte("2"), value)
+	})
+}
@@ -0,0 +1,34 @@
+package cache
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisCache shares cached values between every instance connected to the same Redis.
+type RedisCache struct {
+	Client *redis.Client
+}
+
+func (c *RedisCache) Get(ctx context.Context, key string) ([]byte, error) {
+	value, err := c.Client.Get(ctx, key).Bytes()
+	if err == redis.Nil {
+		return nil, ErrCacheMiss
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "RedisCache.Get")
+	}
+	return value, nil
+}
+
+// Set stores value under key, a zero ttl never expires.
+func (c *RedisCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return errors.Wrap(c.Client.Set(ctx, key, value, ttl).Err(), "RedisCache.Set")
+}
+
+func (c *RedisCache) Delete(ctx context.Context, key string) error {
+	return errors.Wrap(c.Client.Del(ctx, key).Err(), "RedisCache.Delete")
+}
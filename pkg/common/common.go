@@ -1,5 +1,7 @@
 package common
 
+import "time"
+
 type (
 	RunningBusStatus string
 
@@ -17,3 +19,41 @@ var MapCrowdLevelAndSpeed = map[CrowdLevel]float64{
 	MediumCrowd: 50.0,
 	LowCrowd:    60.0,
 }
+
+type SearchResultType string
+
+const (
+	SearchResultBusStop SearchResultType = "busStop"
+	SearchResultBusLine SearchResultType = "busLine"
+)
+
+type JourneyLegMode string
+
+const (
+	JourneyLegWalk JourneyLegMode = "walk"
+	JourneyLegWait JourneyLegMode = "wait"
+	JourneyLegRide JourneyLegMode = "ride"
+)
+
+// WalkingSpeed in km/h
+const WalkingSpeed = 5.0
+
+// TravelDuration is the time a bus needs to run distance meters, reading MapCrowdLevelAndSpeed in km/h.
+// Unknown crowd levels run at the medium crowd speed.
+func TravelDuration(distance float64, crowdLevel CrowdLevel) time.Duration {
+	speed, ok := MapCrowdLevelAndSpeed[crowdLevel]
+	if !ok {
+		speed = MapCrowdLevelAndSpeed[MediumCrowd]
+	}
+	return durationAtSpeed(distance, speed)
+}
+
+// WalkingDuration is the time needed to walk distance meters.
+func WalkingDuration(distance float64) time.Duration {
+	return durationAtSpeed(distance, WalkingSpeed)
+}
+
+func durationAtSpeed(distance, speed float64) time.Duration {
+	metersPerSecond := speed * 1000 / 3600
+	return time.Duration(distance / metersPerSecond * float64(time.Second)).Round(time.Second)
+}
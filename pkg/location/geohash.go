@@ -0,0 +1,103 @@
+package location
+
+import "math"
+
+const geohashBase32 = "0123456789bcdefghjkmnpqrstuvwxyz"
+
+// EncodeGeohash returns the geohash of location with precision characters.
+func EncodeGeohash(location Location, precision int) string {
+	latRange := [2]float64{-90, 90}
+	lngRange := [2]float64{-180, 180}
+
+	hash := make([]byte, 0, precision)
+	isLng := true
+	bit, idx := 0, 0
+	for len(hash) < precision {
+		if isLng {
+			mid := (lngRange[0] + lngRange[1]) / 2
+			if location.Lng >= mid {
+				idx = idx<<1 | 1
+				lngRange[0] = mid
+			} else {
+				idx = idx << 1
+				lngRange[1] = mid
+			}
+		} else {
+			mid := (latRange[0] + latRange[1]) / 2
+			if location.Lat >= mid {
+				idx = idx<<1 | 1
+				latRange[0] = mid
+			} else {
+				idx = idx << 1
+				latRange[1] = mid
+			}
+		}
+		isLng = !isLng
+
+		bit++
+		if bit == 5 {
+			hash = append(hash, geohashBase32[idx])
+			bit, idx = 0, 0
+		}
+	}
+
+	return string(hash)
+}
+
+// GeohashCellSize returns the height and width in degrees of a geohash cell with precision characters.
+func GeohashCellSize(precision int) (latDegree, lngDegree float64) {
+	bits := precision * 5
+	lngBits := (bits + 1) / 2
+	latBits := bits / 2
+	return 180 / math.Pow(2, float64(latBits)), 360 / math.Pow(2, float64(lngBits))
+}
+
+// GeohashesInRadius returns the geohash cells with precision characters covering
+// the bounding box of the circle around center, radius in meters.
+// Nil is returned for a center or radius that is not a finite number, or a negative radius.
+func GeohashesInRadius(center Location, radius float64, precision int) []string {
+	if !isFinite(center.Lat) || !isFinite(center.Lng) || !isFinite(radius) || radius < 0 {
+		return nil
+	}
+	latDelta := radius / metersPerDegree
+	lngDelta := math.Min(radius/(metersPerDegree*math.Max(math.Cos(center.Lat*math.Pi/180), 0.01)), 180)
+	cellLat, cellLng := GeohashCellSize(precision)
+
+	minLat, maxLat := math.Max(center.Lat-latDelta, -90), math.Min(center.Lat+latDelta, 90)
+	minLng, maxLng := center.Lng-lngDelta, center.Lng+lngDelta
+	rows := int(math.Ceil((maxLat - minLat) / cellLat))
+	cols := int(math.Ceil((maxLng - minLng) / cellLng))
+
+	seen := make(map[string]struct{})
+	hashes := make([]string, 0)
+	for row := 0; row <= rows; row++ {
+		lat := math.Min(minLat+float64(row)*cellLat, maxLat)
+		for col := 0; col <= cols; col++ {
+			lng := math.Min(minLng+float64(col)*cellLng, maxLng)
+			hash := EncodeGeohash(Location{Lat: lat, Lng: normalizeLng(lng)}, precision)
+			if _, ok := seen[hash]; !ok {
+				seen[hash] = struct{}{}
+				hashes = append(hashes, hash)
+			}
+		}
+	}
+
+	return hashes
+}
+
+func isFinite(val float64) bool {
+	return !math.IsNaN(val) && !math.IsInf(val, 0)
+}
+
+// metersPerDegree is the length of one degree of latitude
+const metersPerDegree = 111320.0
+
+func normalizeLng(lng float64) float64 {
+	switch {
+	case lng < -180:
+		return lng + 360
+	case lng >= 180:
+		return lng - 360
+	}
+	return lng
+}
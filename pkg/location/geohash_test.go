@@ -0,0 +1,42 @@
+package location
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncodeGeohash(t *testing.T) {
+	t.Parallel()
+
+	t.Run("happy case", func(tt *testing.T) {
+		assert.Equal(tt, "u4pruydqqvj", EncodeGeohash(Location{Lat: 57.64911, Lng: 10.40744}, 11))
+		assert.Equal(tt, "u4pr", EncodeGeohash(Location{Lat: 57.64911, Lng: 10.40744}, 4))
+	})
+}
+
+func TestGeohashesInRadius(t *testing.T) {
+	t.Parallel()
+
+	t.Run("cells cover every point in radius", func(tt *testing.T) {
+		center := Location{Lat: 1.33781, Lng: 103.69739}
+		hashes := GeohashesInRadius(center, 1000, 6)
+
+		points := []Location{
+			{Lat: 1.34564, Lng: 103.6978},
+			{Lat: 1.33781, Lng: 103.6888},
+			{Lat: 1.33200, Lng: 103.70300},
+		}
+		for _, point := range points {
+			assert.LessOrEqual(tt, CalculateStraightLine(center, point), 1000.0)
+			assert.Contains(tt, hashes, EncodeGeohash(point, 6))
+		}
+	})
+
+	t.Run("bad case: not finite", func(tt *testing.T) {
+		assert.Nil(tt, GeohashesInRadius(Location{Lat: math.NaN(), Lng: 103.69739}, 1000, 6))
+		assert.Nil(tt, GeohashesInRadius(Location{Lat: 1.33781, Lng: math.Inf(1)}, 1000, 6))
+		assert.Nil(tt, GeohashesInRadius(Location{Lat: 1.33781, Lng: 103.69739}, math.NaN(), 6))
+	})
+}
@@ -0,0 +1,47 @@
+package location
+
+// GeoJSON types of RFC 7946, positions are [longitude, latitude].
+
+type FeatureCollection struct {
+	Type     string    `json:"type"`
+	Features []Feature `json:"features"`
+}
+
+type Feature struct {
+	Type       string                 `json:"type"`
+	Geometry   Geometry               `json:"geometry"`
+	Properties map[string]interface{} `json:"properties"`
+}
+
+// Geometry coordinates are a position for a Point and a list of positions for a LineString
+type Geometry struct {
+	Type        string      `json:"type"`
+	Coordinates interface{} `json:"coordinates"`
+}
+
+func NewFeatureCollection(features []Feature) FeatureCollection {
+	if features == nil {
+		features = make([]Feature, 0)
+	}
+	return FeatureCollection{Type: "FeatureCollection", Features: features}
+}
+
+func NewFeature(geometry Geometry, properties map[string]interface{}) Feature {
+	return Feature{Type: "Feature", Geometry: geometry, Properties: properties}
+}
+
+func NewPoint(point Location) Geometry {
+	return Geometry{Type: "Point", Coordinates: geoJSONPosition(point)}
+}
+
+func NewLineString(path []Location) Geometry {
+	positions := make([][]float64, 0, len(path))
+	for _, point := range path {
+		positions = append(positions, geoJSONPosition(point))
+	}
+	return Geometry{Type: "LineString", Coordinates: positions}
+}
+
+func geoJSONPosition(point Location) []float64 {
+	return []float64{point.Lng, point.Lat}
+}
@@ -0,0 +1,71 @@
+package location
+
+import (
+	"math"
+	"strings"
+)
+
+// polylinePrecision is the number of decimals kept by the Google encoded polyline format
+const polylinePrecision = 1e5
+
+// EncodePolyline returns path in the Google encoded polyline format.
+func EncodePolyline(path []Location) string {
+	var builder strings.Builder
+	var lastLat, lastLng int64
+	for _, point := range path {
+		lat := int64(math.Round(point.Lat * polylinePrecision))
+		lng := int64(math.Round(point.Lng * polylinePrecision))
+		encodePolylineValue(&builder, lat-lastLat)
+		encodePolylineValue(&builder, lng-lastLng)
+		lastLat, lastLng = lat, lng
+	}
+	return builder.String()
+}
+
+// DecodePolyline returns the path of a Google encoded polyline, an invalid polyline is decoded up to its last complete point.
+func DecodePolyline(polyline string) []Location {
+	path := make([]Location, 0)
+	var lat, lng int64
+	for i := 0; i < len(polyline); {
+		deltaLat, next, ok := decodePolylineValue(polyline, i)
+		if !ok {
+			break
+		}
+		deltaLng, next, ok := decodePolylineValue(polyline, next)
+		if !ok {
+			break
+		}
+		i = next
+		lat, lng = lat+deltaLat, lng+deltaLng
+		path = append(path, Location{Lat: float64(lat) / polylinePrecision, Lng: float64(lng) / polylinePrecision})
+	}
+	return path
+}
+
+func encodePolylineValue(builder *strings.Builder, value int64) {
+	shifted := value << 1
+	if value < 0 {
+		shifted = ^shifted
+	}
+	for shifted >= 0x20 {
+		builder.WriteByte(byte((0x20 | (shifted & 0x1f)) + 63))
+		shifted >>= 5
+	}
+	builder.WriteByte(byte(shifted + 63))
+}
+
+func decodePolylineValue(polyline string, i int) (int64, int, bool) {
+	var result int64
+	for shift := uint(0); i < len(polyline); shift += 5 {
+		chunk := int64(polyline[i]) - 63
+		i++
+		result |= (chunk & 0x1f) << shift
+		if chunk < 0x20 {
+			if result&1 != 0 {
+				return ^(result >> 1), i, true
+			}
+			return result >> 1, i, true
+		}
+	}
+	return 0, i, false
+}
@@ -0,0 +1,57 @@
+package location
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncodePolyline(t *testing.T) {
+	t.Parallel()
+
+	// example of the Google encoded polyline format documentation
+	path := []Location{
+		{Lat: 38.5, Lng: -120.2},
+		{Lat: 40.7, Lng: -120.95},
+		{Lat: 43.252, Lng: -126.453},
+	}
+
+	t.Run("happy case", func(tt *testing.T) {
+		assert.Equal(tt, "_p~iF~ps|U_ulLnnqC_mqNvxq`@", EncodePolyline(path))
+		assert.Equal(tt, "", EncodePolyline(nil))
+	})
+
+	t.Run("happy case: decode", func(tt *testing.T) {
+		assert.Equal(tt, path, DecodePolyline("_p~iF~ps|U_ulLnnqC_mqNvxq`@"))
+		assert.Empty(tt, DecodePolyline(""))
+	})
+
+	t.Run("bad case: truncated polyline", func(tt *testing.T) {
+		assert.Equal(tt, path[:1], DecodePolyline("_p~iF~ps|U_ulL"))
+	})
+}
+
+func TestGeoJSON(t *testing.T) {
+	t.Parallel()
+
+	t.Run("happy case", func(tt *testing.T) {
+		collection := NewFeatureCollection([]Feature{
+			NewFeature(NewPoint(Location{Lat: 1.3, Lng: 103.7}), map[string]interface{}{"id": "377906"}),
+			NewFeature(NewLineString([]Location{{Lat: 1.3, Lng: 103.7}, {Lat: 1.4, Lng: 103.8}}), nil),
+		})
+
+		data, err := json.Marshal(collection)
+		assert.NoError(tt, err)
+		assert.JSONEq(tt, `{"type":"FeatureCollection","features":[
+			{"type":"Feature","geometry":{"type":"Point","coordinates":[103.7,1.3]},"properties":{"id":"377906"}},
+			{"type":"Feature","geometry":{"type":"LineString","coordinates":[[103.7,1.3],[103.8,1.4]]},"properties":null}
+		]}`, string(data))
+	})
+
+	t.Run("happy case: empty collection", func(tt *testing.T) {
+		data, err := json.Marshal(NewFeatureCollection(nil))
+		assert.NoError(tt, err)
+		assert.JSONEq(tt, `{"type":"FeatureCollection","features":[]}`, string(data))
+	})
+}
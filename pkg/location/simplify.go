@@ -0,0 +1,54 @@
+package location
+
+import "math"
+
+// Simplify drops the points of path closer than tolerance meters to the line kept through them,
+// with the Douglas–Peucker algorithm. The first and last points are always kept.
+func Simplify(path []Location, tolerance float64) []Location {
+	if len(path) <= 2 || tolerance <= 0 {
+		return path
+	}
+
+	keep := make([]bool, len(path))
+	keep[0], keep[len(path)-1] = true, true
+	// ranges left to simplify, an explicit stack keeps long paths off the call stack
+	stack := [][2]int{{0, len(path) - 1}}
+	for len(stack) > 0 {
+		first, last := stack[len(stack)-1][0], stack[len(stack)-1][1]
+		stack = stack[:len(stack)-1]
+
+		farthest, maxDistance := -1, tolerance
+		for i := first + 1; i < last; i++ {
+			if distance := distanceToSegment(path[i], path[first], path[last]); distance > maxDistance {
+				farthest, maxDistance = i, distance
+			}
+		}
+		if farthest < 0 {
+			continue
+		}
+		keep[farthest] = true
+		stack = append(stack, [2]int{first, farthest}, [2]int{farthest, last})
+	}
+
+	simplified := make([]Location, 0, len(path))
+	for i, point := range path {
+		if keep[i] {
+			simplified = append(simplified, point)
+		}
+	}
+	return simplified
+}
+
+// distanceToSegment is the distance in meters from point to the segment AB,
+// on an equirectangular projection which is precise enough at bus line scale.
+func distanceToSegment(point, A, B Location) float64 {
+	lngScale := math.Cos(point.Lat * math.Pi / 180)
+	x, y := (point.Lng-A.Lng)*lngScale, point.Lat-A.Lat
+	dx, dy := (B.Lng-A.Lng)*lngScale, B.Lat-A.Lat
+
+	t := 0.0
+	if length := dx*dx + dy*dy; length > 0 {
+		t = math.Max(0, math.Min(1, (x*dx+y*dy)/length))
+	}
+	return math.Hypot(x-t*dx, y-t*dy) * metersPerDegree
+}
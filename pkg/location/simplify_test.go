@@ -0,0 +1,36 @@
+package location
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSimplify(t *testing.T) {
+	t.Parallel()
+
+	// a straight road east with a 100m detour north in the middle
+	path := []Location{
+		{Lat: 1.3400, Lng: 103.6900},
+		{Lat: 1.34001, Lng: 103.6910},
+		{Lat: 1.3400, Lng: 103.6920},
+		{Lat: 1.3409, Lng: 103.6930},
+		{Lat: 1.3400, Lng: 103.6940},
+		{Lat: 1.34001, Lng: 103.6950},
+		{Lat: 1.3400, Lng: 103.6960},
+	}
+
+	t.Run("happy case: points within tolerance are dropped", func(tt *testing.T) {
+		simplified := Simplify(path, 10)
+		assert.Equal(tt, []Location{path[0], path[2], path[3], path[4], path[6]}, simplified)
+	})
+
+	t.Run("happy case: large tolerance keeps the ends", func(tt *testing.T) {
+		assert.Equal(tt, []Location{path[0], path[6]}, Simplify(path, 500))
+	})
+
+	t.Run("happy case: no tolerance keeps every point", func(tt *testing.T) {
+		assert.Equal(tt, path, Simplify(path, 0))
+		assert.Equal(tt, path[:2], Simplify(path[:2], 10))
+	})
+}
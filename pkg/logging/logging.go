@@ -0,0 +1,120 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	"bus-timing/pkg/middlewares/requestid"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Formats of the log records
+const (
+	FormatJSON = "json"
+	FormatText = "text"
+)
+
+type Options struct {
+	// Level is debug, info, warn or error, info when empty
+	Level string
+	// Format is FormatJSON or FormatText, json when empty
+	Format string
+	// Output is where records are written, stdout when nil
+	Output io.Writer
+}
+
+// New returns a logger adding the request ID and the trace ID of the context to the records.
+func New(options Options) (*slog.Logger, error) {
+	var level slog.Level
+	if options.Level != "" {
+		if err := level.UnmarshalText([]byte(options.Level)); err != nil {
+			return nil, fmt.Errorf("logging: unknown level %q", options.Level)
+		}
+	}
+	output := options.Output
+	if output == nil {
+		output = os.Stdout
+	}
+
+	handlerOptions := &slog.HandlerOptions{Level: level}
+	var handler slog.Handler
+	switch strings.ToLower(options.Format) {
+	case "", FormatJSON:
+		handler = slog.NewJSONHandler(output, handlerOptions)
+	case FormatText:
+		handler = slog.NewTextHandler(output, handlerOptions)
+	default:
+		return nil, fmt.Errorf("logging: unknown format %q", options.Format)
+	}
+	return slog.New(contextHandler{Handler: handler}), nil
+}
+
+// OrDefault is the logger injected into a component, the default one when none was.
+func OrDefault(logger *slog.Logger) *slog.Logger {
+	if logger != nil {
+		return logger
+	}
+	return slog.Default()
+}
+
+// contextHandler adds the attributes carried by the context of a record
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, record slog.Record) error {
+	if ctx != nil {
+		if requestID := requestid.FromContext(ctx); requestID != "" {
+			record.AddAttrs(slog.String("request_id", requestID))
+		}
+		if spanContext := trace.SpanContextFromContext(ctx); spanContext.IsValid() {
+			record.AddAttrs(slog.String("trace_id", spanContext.TraceID().String()))
+		}
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{Handler: h.Handler.WithGroup(name)}
+}
+
+// Middleware logs a record per request once it is answered, a warning for client errors and an error for server errors.
+func Middleware(logger *slog.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		status := c.Writer.Status()
+		level := slog.LevelInfo
+		switch {
+		case status >= 500:
+			level = slog.LevelError
+		case status >= 400:
+			level = slog.LevelWarn
+		}
+		attrs := []slog.Attr{
+			slog.String("method", c.Request.Method),
+			slog.String("route", c.FullPath()),
+			slog.String("path", c.Request.URL.Path),
+			slog.Int("status", status),
+			slog.Duration("latency", time.Since(start)),
+			slog.String("client_ip", c.ClientIP()),
+			slog.Int("size", c.Writer.Size()),
+		}
+		if err := c.Errors.Last(); err != nil {
+			attrs = append(attrs, slog.String("error", err.Error()))
+		}
+		logger.LogAttrs(c, level, "request", attrs...)
+	}
+}
@@ -0,0 +1,97 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"bus-timing/pkg/middlewares/requestid"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func decode(tt *testing.T, buf *bytes.Buffer) map[string]any {
+	record := map[string]any{}
+	assert.NoError(tt, json.Unmarshal(buf.Bytes(), &record))
+	return record
+}
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	t.Run("happy case: request and trace IDs of the context", func(tt *testing.T) {
+		buf := &bytes.Buffer{}
+		logger, err := New(Options{Output: buf})
+		assert.NoError(tt, err)
+
+		traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+		spanID, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+		ctx := trace.ContextWithSpanContext(requestid.NewContext(context.Background(), "req-1"),
+			trace.NewSpanContext(trace.SpanContextConfig{TraceID: traceID, SpanID: spanID}))
+		logger.With("bus_line_id", "44480").InfoContext(ctx, "poll bus positions")
+
+		record := decode(tt, buf)
+		assert.Equal(tt, "poll bus positions", record["msg"])
+		assert.Equal(tt, "44480", record["bus_line_id"])
+		assert.Equal(tt, "req-1", record["request_id"])
+		assert.Equal(tt, "4bf92f3577b34da6a3ce929d0e0e4736", record["trace_id"])
+	})
+
+	t.Run("happy case: level", func(tt *testing.T) {
+		buf := &bytes.Buffer{}
+		logger, err := New(Options{Level: "warn", Format: FormatText, Output: buf})
+		assert.NoError(tt, err)
+
+		logger.Info("hidden")
+		assert.Empty(tt, buf.String())
+		logger.Warn("shown")
+		assert.Contains(tt, buf.String(), "msg=shown")
+	})
+
+	t.Run("bad case: unknown level or format", func(tt *testing.T) {
+		_, err := New(Options{Level: "verbose"})
+		assert.Error(tt, err)
+		_, err = New(Options{Format: "xml"})
+		assert.Error(tt, err)
+	})
+}
+
+func TestMiddleware(t *testing.T) {
+	t.Parallel()
+
+	serve := func(tt *testing.T, status int) map[string]any {
+		buf := &bytes.Buffer{}
+		logger, err := New(Options{Output: buf})
+		assert.NoError(tt, err)
+
+		gin.SetMode(gin.TestMode)
+		router := gin.New()
+		router.Use(requestid.RequestIDMiddleware(), Middleware(logger))
+		router.GET("/api/busStops/:busStopID", func(c *gin.Context) {
+			c.Status(status)
+		})
+		req := httptest.NewRequest(http.MethodGet, "/api/busStops/378204", nil)
+		req.Header.Set(requestid.Header, "req-1")
+		router.ServeHTTP(httptest.NewRecorder(), req)
+		return decode(tt, buf)
+	}
+
+	t.Run("happy case: request record", func(tt *testing.T) {
+		record := serve(tt, http.StatusOK)
+		assert.Equal(tt, "INFO", record["level"])
+		assert.Equal(tt, "/api/busStops/:busStopID", record["route"])
+		assert.Equal(tt, "/api/busStops/378204", record["path"])
+		assert.Equal(tt, float64(http.StatusOK), record["status"])
+		assert.Equal(tt, "req-1", record["request_id"])
+	})
+
+	t.Run("happy case: failures are warnings and errors", func(tt *testing.T) {
+		assert.Equal(tt, "WARN", serve(tt, http.StatusNotFound)["level"])
+		assert.Equal(tt, "ERROR", serve(tt, http.StatusBadGateway)["level"])
+	})
+}
@@ -0,0 +1,55 @@
+package metrics
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHTTPMiddleware(t *testing.T) {
+	t.Parallel()
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(HTTPMiddleware())
+	router.GET("/api/v1/busLines/:busLineID", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	router.GET("/metrics", gin.WrapH(Handler()))
+
+	serve := func(path string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+		return recorder
+	}
+
+	t.Run("happy case: requests are labelled by route template", func(tt *testing.T) {
+		counter := HTTPRequests.WithLabelValues(http.MethodGet, "/api/v1/busLines/:busLineID", "200")
+		before := testutil.ToFloat64(counter)
+		serve("/api/v1/busLines/44478")
+		serve("/api/v1/busLines/44479")
+		assert.Equal(tt, before+2, testutil.ToFloat64(counter))
+	})
+
+	t.Run("happy case: unknown paths share one series", func(tt *testing.T) {
+		counter := HTTPRequests.WithLabelValues(http.MethodGet, unmatchedRoute, "404")
+		before := testutil.ToFloat64(counter)
+		serve("/unknown/1")
+		serve("/unknown/2")
+		assert.Equal(tt, before+2, testutil.ToFloat64(counter))
+	})
+
+	t.Run("happy case: metrics are served", func(tt *testing.T) {
+		recorder := serve("/metrics")
+		assert.Equal(tt, http.StatusOK, recorder.Code)
+		body, err := io.ReadAll(recorder.Body)
+		assert.NoError(tt, err)
+		assert.Contains(tt, string(body), "bus_timing_http_requests_total")
+		assert.Contains(tt, string(body), "go_goroutines")
+	})
+}
@@ -0,0 +1,46 @@
+package apikey
+
+import (
+	"context"
+
+	"bus-timing/pkg/apperror"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Header carries the API key of partner apps
+const Header = "X-API-Key"
+
+const apiKeyIDKey = "apikey.id"
+
+type Authorizer interface {
+	Authorize(ctx context.Context, key string) (string, error)
+}
+
+// APIKeyMiddleware lets requests with an authorized API key through, the ID of their key is read with Get.
+// Refused requests are answered by the error handler, with a Retry-After header when rate limited.
+func APIKeyMiddleware(authorizer Authorizer) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(Header)
+		if key == "" {
+			c.Error(apperror.Unauthorized("missing api key"))
+			c.Abort()
+			return
+		}
+
+		apiKeyID, err := authorizer.Authorize(c, key)
+		if err != nil {
+			c.Error(err)
+			c.Abort()
+			return
+		}
+
+		c.Set(apiKeyIDKey, apiKeyID)
+		c.Next()
+	}
+}
+
+// Get returns the ID of the API key of the request.
+func Get(c *gin.Context) string {
+	return c.GetString(apiKeyIDKey)
+}
@@ -0,0 +1,62 @@
+package apikey
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"bus-timing/pkg/apperror"
+	"bus-timing/pkg/middlewares/errorhandler"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockAuthorizer map[string]error
+
+func (authorizer mockAuthorizer) Authorize(ctx context.Context, key string) (string, error) {
+	if err, ok := authorizer[key]; ok {
+		return "", err
+	}
+	return "id-" + key, nil
+}
+
+func TestAPIKeyMiddleware(t *testing.T) {
+	t.Parallel()
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(errorhandler.ErrorHandlerMiddleware(), APIKeyMiddleware(mockAuthorizer{
+		"unknown": apperror.Unauthorized("invalid api key"),
+		"limited": apperror.RateLimited(2*time.Second, "rate limit exceeded"),
+	}))
+	router.GET("/", func(c *gin.Context) {
+		c.String(http.StatusOK, Get(c))
+	})
+	serve := func(key string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		if key != "" {
+			req.Header.Set(Header, key)
+		}
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	t.Run("happy case", func(tt *testing.T) {
+		recorder := serve("partner")
+		assert.Equal(tt, http.StatusOK, recorder.Code)
+		assert.Equal(tt, "id-partner", recorder.Body.String())
+	})
+
+	t.Run("bad case: missing, unknown or rate limited key", func(tt *testing.T) {
+		assert.Equal(tt, http.StatusUnauthorized, serve("").Code)
+		assert.Equal(tt, http.StatusUnauthorized, serve("unknown").Code)
+
+		recorder := serve("limited")
+		assert.Equal(tt, http.StatusTooManyRequests, recorder.Code)
+		assert.Equal(tt, "2", recorder.Header().Get("Retry-After"))
+	})
+}
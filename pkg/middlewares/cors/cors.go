@@ -1,19 +1,138 @@
 package cors
 
-import "github.com/gin-gonic/gin"
+import (
+	"errors"
+	"net/http"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	defaultAllowedMethods = []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodDelete, http.MethodOptions}
+	defaultAllowedHeaders = []string{"Content-Type", "Authorization", "Accept", "Cache-Control", "X-Requested-With"}
+)
+
+// Options is the CORS policy, requests from other origins get no CORS headers.
+type Options struct {
+	// AllowedOrigins are exact origins, e.g. https://example.com, "*" allows every origin
+	AllowedOrigins []string
+	// AllowedOriginPatterns are regular expressions matching whole origins, e.g. https://.*\.example\.com
+	AllowedOriginPatterns []string
+	// AllowedMethods and AllowedHeaders answer preflight requests, "*" in AllowedHeaders allows the requested headers
+	AllowedMethods []string
+	AllowedHeaders []string
+	// ExposedHeaders are the response headers scripts can read
+	ExposedHeaders   []string
+	AllowCredentials bool
+	// MaxAge is how many seconds browsers may cache a preflight response, not sent when 0
+	MaxAge int
+}
+
+// OriginMatcher reports whether the policy allows an origin, it fails when a pattern does not compile.
+func OriginMatcher(options Options) (func(origin string) bool, error) {
+	anyOrigin := slices.Contains(options.AllowedOrigins, "*")
+	patterns := make([]*regexp.Regexp, 0, len(options.AllowedOriginPatterns))
+	for _, val := range options.AllowedOriginPatterns {
+		pattern, err := regexp.Compile("^(?:" + val + ")$")
+		if err != nil {
+			return nil, err
+		}
+		patterns = append(patterns, pattern)
+	}
+	return func(origin string) bool {
+		if anyOrigin || slices.Contains(options.AllowedOrigins, origin) {
+			return true
+		}
+		for _, pattern := range patterns {
+			if pattern.MatchString(origin) {
+				return true
+			}
+		}
+		return false
+	}, nil
+}
+
+// CorsMiddleware answers preflight requests and adds the CORS headers to the responses of allowed origins.
+// It fails when a pattern does not compile, or when credentials are allowed for every origin which browsers refuse.
+func CorsMiddleware(options Options) (gin.HandlerFunc, error) {
+	anyOrigin := slices.Contains(options.AllowedOrigins, "*")
+	if anyOrigin && options.AllowCredentials {
+		return nil, errors.New("cors: credentials cannot be allowed for every origin, list the allowed origins")
+	}
+	allowed, err := OriginMatcher(options)
+	if err != nil {
+		return nil, err
+	}
+
+	methods := options.AllowedMethods
+	if len(methods) == 0 {
+		methods = defaultAllowedMethods
+	}
+	allowedMethods := strings.ToUpper(strings.Join(methods, ", "))
+	headers := options.AllowedHeaders
+	if len(headers) == 0 {
+		headers = defaultAllowedHeaders
+	}
+	anyHeader := slices.Contains(headers, "*")
+	allowedHeaders := strings.Join(headers, ", ")
+	exposedHeaders := strings.Join(options.ExposedHeaders, ", ")
 
-func CorsMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
-		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With")
-		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT")
+		origin := c.GetHeader("Origin")
+		preflight := c.Request.Method == http.MethodOptions && c.GetHeader("Access-Control-Request-Method") != ""
+		header := c.Writer.Header()
+		if origin == "" {
+			c.Next()
+			return
+		}
 
-		if c.Request.Method == "OPTIONS" {
-			c.AbortWithStatus(204)
+		// the answer depends on the origin unless every origin gets the same one
+		if !anyOrigin {
+			header.Add("Vary", "Origin")
+		}
+		if !allowed(origin) {
+			if preflight {
+				c.AbortWithStatus(http.StatusForbidden)
+				return
+			}
+			c.Next()
 			return
 		}
 
-		c.Next()
-	}
+		if anyOrigin {
+			header.Set("Access-Control-Allow-Origin", "*")
+		} else {
+			header.Set("Access-Control-Allow-Origin", origin)
+		}
+		if options.AllowCredentials {
+			header.Set("Access-Control-Allow-Credentials", "true")
+		}
+
+		if !preflight {
+			if exposedHeaders != "" {
+				header.Set("Access-Control-Expose-Headers", exposedHeaders)
+			}
+			c.Next()
+			return
+		}
+
+		header.Add("Vary", "Access-Control-Request-Method")
+		header.Add("Vary", "Access-Control-Request-Headers")
+		header.Set("Access-Control-Allow-Methods", allowedMethods)
+		if anyHeader {
+			if requested := c.GetHeader("Access-Control-Request-Headers"); requested != "" {
+				header.Set("Access-Control-Allow-Headers", requested)
+			}
+		} else {
+			header.Set("Access-Control-Allow-Headers", allowedHeaders)
+		}
+		if options.MaxAge > 0 {
+			header.Set("Access-Control-Max-Age", strconv.Itoa(options.MaxAge))
+		}
+		c.AbortWithStatus(http.StatusNoContent)
+	}, nil
 }
@@ -0,0 +1,123 @@
+package cors
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func serve(tt *testing.T, options Options, method, origin string, headers map[string]string) *httptest.ResponseRecorder {
+	middleware, err := CorsMiddleware(options)
+	assert.NoError(tt, err)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware)
+	router.GET("/", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(method, "/", nil)
+	if origin != "" {
+		req.Header.Set("Origin", origin)
+	}
+	for key, val := range headers {
+		req.Header.Set(key, val)
+	}
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+	return recorder
+}
+
+func TestCorsMiddleware(t *testing.T) {
+	t.Parallel()
+
+	options := Options{
+		AllowedOrigins:        []string{"https://app.example.com"},
+		AllowedOriginPatterns: []string{`https://[a-z]+\.partner\.com`},
+		AllowedMethods:        []string{"get", "post"},
+		AllowedHeaders:        []string{"Authorization", "X-API-Key"},
+		ExposedHeaders:        []string{"X-Request-ID", "Retry-After"},
+		AllowCredentials:      true,
+		MaxAge:                600,
+	}
+	preflight := map[string]string{"Access-Control-Request-Method": http.MethodGet}
+
+	t.Run("happy case: allowed origins", func(tt *testing.T) {
+		for _, origin := range []string{"https://app.example.com", "https://maps.partner.com"} {
+			recorder := serve(tt, options, http.MethodGet, origin, nil)
+			assert.Equal(tt, http.StatusOK, recorder.Code)
+			assert.Equal(tt, origin, recorder.Header().Get("Access-Control-Allow-Origin"))
+			assert.Equal(tt, "true", recorder.Header().Get("Access-Control-Allow-Credentials"))
+			assert.Equal(tt, "X-Request-ID, Retry-After", recorder.Header().Get("Access-Control-Expose-Headers"))
+			assert.Equal(tt, "Origin", recorder.Header().Get("Vary"))
+		}
+	})
+
+	t.Run("happy case: preflight", func(tt *testing.T) {
+		recorder := serve(tt, options, http.MethodOptions, "https://app.example.com", preflight)
+		assert.Equal(tt, http.StatusNoContent, recorder.Code)
+		assert.Equal(tt, "GET, POST", recorder.Header().Get("Access-Control-Allow-Methods"))
+		assert.Equal(tt, "Authorization, X-API-Key", recorder.Header().Get("Access-Control-Allow-Headers"))
+		assert.Equal(tt, "600", recorder.Header().Get("Access-Control-Max-Age"))
+	})
+
+	t.Run("happy case: any origin and requested headers", func(tt *testing.T) {
+		options := Options{AllowedOrigins: []string{"*"}, AllowedHeaders: []string{"*"}}
+		recorder := serve(tt, options, http.MethodOptions, "https://anywhere.com", map[string]string{
+			"Access-Control-Request-Method":  http.MethodGet,
+			"Access-Control-Request-Headers": "x-custom",
+		})
+		assert.Equal(tt, http.StatusNoContent, recorder.Code)
+		assert.Equal(tt, "*", recorder.Header().Get("Access-Control-Allow-Origin"))
+		assert.Empty(tt, recorder.Header().Get("Access-Control-Allow-Credentials"))
+		assert.Equal(tt, "x-custom", recorder.Header().Get("Access-Control-Allow-Headers"))
+	})
+
+	t.Run("bad case: other origins", func(tt *testing.T) {
+		// patterns match whole origins
+		for _, origin := range []string{"https://evil.com", "https://maps.partner.com.evil.com"} {
+			recorder := serve(tt, options, http.MethodGet, origin, nil)
+			assert.Equal(tt, http.StatusOK, recorder.Code)
+			assert.Empty(tt, recorder.Header().Get("Access-Control-Allow-Origin"))
+
+			recorder = serve(tt, options, http.MethodOptions, origin, preflight)
+			assert.Equal(tt, http.StatusForbidden, recorder.Code)
+		}
+
+		recorder := serve(tt, options, http.MethodGet, "", nil)
+		assert.Equal(tt, http.StatusOK, recorder.Code)
+		assert.Empty(tt, recorder.Header().Get("Access-Control-Allow-Origin"))
+	})
+
+	t.Run("bad case: invalid options", func(tt *testing.T) {
+		_, err := CorsMiddleware(Options{AllowedOrigins: []string{"*"}, AllowCredentials: true})
+		assert.Error(tt, err)
+		_, err = CorsMiddleware(Options{AllowedOriginPatterns: []string{"("}})
+		assert.Error(tt, err)
+	})
+}
+
+func TestOriginMatcher(t *testing.T) {
+	t.Parallel()
+
+	t.Run("happy case", func(tt *testing.T) {
+		allowed, err := OriginMatcher(Options{
+			AllowedOrigins:        []string{"https://example.com"},
+			AllowedOriginPatterns: []string{`https://.*\.example\.com`},
+		})
+		assert.NoError(tt, err)
+		assert.True(tt, allowed("https://example.com"))
+		assert.True(tt, allowed("https://app.example.com"))
+		assert.False(tt, allowed("https://example.com.evil.com"))
+		assert.False(tt, allowed("http://example.com"))
+	})
+
+	t.Run("bad case: invalid pattern", func(tt *testing.T) {
+		_, err := OriginMatcher(Options{AllowedOriginPatterns: []string{"("}})
+		assert.Error(tt, err)
+	})
+}
@@ -0,0 +1,29 @@
+package deprecation
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DeprecationMiddleware tells clients the route is deprecated in favour of the successor route,
+// :name segments of successor are filled with the parameters of the request. Sunset is sent when it is set.
+func DeprecationMiddleware(successor string, sunset time.Time) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		link := successor
+		for _, param := range c.Params {
+			link = strings.ReplaceAll(link, ":"+param.Key, url.PathEscape(param.Value))
+		}
+		c.Writer.Header().Set("Deprecation", "true")
+		c.Writer.Header().Set("Link", fmt.Sprintf(`<%s>; rel="successor-version"`, link))
+		if !sunset.IsZero() {
+			c.Writer.Header().Set("Sunset", sunset.UTC().Format(http.TimeFormat))
+		}
+
+		c.Next()
+	}
+}
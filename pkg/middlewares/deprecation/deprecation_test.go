@@ -0,0 +1,43 @@
+package deprecation
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeprecationMiddleware(t *testing.T) {
+	t.Parallel()
+	gin.SetMode(gin.TestMode)
+
+	t.Run("happy case: successor with request parameters", func(tt *testing.T) {
+		router := gin.New()
+		sunset := time.Date(2027, time.January, 1, 0, 0, 0, 0, time.UTC)
+		router.GET("/api/busStop/:busStopID", DeprecationMiddleware("/api/v2/busStops/:busStopID/arrivals", sunset), func(c *gin.Context) {
+			c.Status(http.StatusOK)
+		})
+
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/api/busStop/377906", nil))
+		assert.Equal(tt, http.StatusOK, recorder.Code)
+		assert.Equal(tt, "true", recorder.Header().Get("Deprecation"))
+		assert.Equal(tt, `</api/v2/busStops/377906/arrivals>; rel="successor-version"`, recorder.Header().Get("Link"))
+		assert.Equal(tt, "Fri, 01 Jan 2027 00:00:00 GMT", recorder.Header().Get("Sunset"))
+	})
+
+	t.Run("happy case: no sunset", func(tt *testing.T) {
+		router := gin.New()
+		router.GET("/api/busLines", DeprecationMiddleware("/api/v2/busLines", time.Time{}), func(c *gin.Context) {
+			c.Status(http.StatusOK)
+		})
+
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/api/busLines", nil))
+		assert.Equal(tt, `</api/v2/busLines>; rel="successor-version"`, recorder.Header().Get("Link"))
+		assert.Empty(tt, recorder.Header().Get("Sunset"))
+	})
+}
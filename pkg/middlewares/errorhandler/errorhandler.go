@@ -0,0 +1,69 @@
+package errorhandler
+
+import (
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+
+	"bus-timing/pkg/apperror"
+	"bus-timing/pkg/middlewares/requestid"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ErrorResponse is the body of every failed request
+type ErrorResponse struct {
+	Code      apperror.Kind `json:"code"`
+	Message   string        `json:"message"`
+	RequestID string        `json:"requestID"`
+	// Error repeats Message for clients reading the former {"error": message} body
+	Error string `json:"error"`
+}
+
+var statusByKind = map[apperror.Kind]int{
+	apperror.KindInvalidInput:        http.StatusBadRequest,
+	apperror.KindNotFound:            http.StatusNotFound,
+	apperror.KindUnauthorized:        http.StatusUnauthorized,
+	apperror.KindForbidden:           http.StatusForbidden,
+	apperror.KindRateLimited:         http.StatusTooManyRequests,
+	apperror.KindUpstreamUnavailable: http.StatusBadGateway,
+	apperror.KindTimeout:             http.StatusGatewayTimeout,
+	apperror.KindInternal:            http.StatusInternalServerError,
+}
+
+// StatusOf returns the HTTP status of an error kind.
+func StatusOf(kind apperror.Kind) int {
+	if status, ok := statusByKind[kind]; ok {
+		return status
+	}
+	return http.StatusInternalServerError
+}
+
+// ErrorHandlerMiddleware answers the last error a handler added with c.Error,
+// with the status of its kind and an ErrorResponse.
+func ErrorHandlerMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		err := c.Errors.Last()
+		if err == nil || c.Writer.Written() {
+			return
+		}
+
+		kind := apperror.KindOf(err.Err)
+		if kind == apperror.KindInternal {
+			slog.ErrorContext(c, "request failed", "error", err.Err)
+		}
+		if retryAfter := apperror.RetryAfterOf(err.Err); retryAfter > 0 {
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+		}
+		message := apperror.MessageOf(err.Err)
+		c.JSON(StatusOf(kind), ErrorResponse{
+			Code:      kind,
+			Message:   message,
+			RequestID: requestid.Get(c),
+			Error:     message,
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package errorhandler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"bus-timing/pkg/apperror"
+	"bus-timing/pkg/middlewares/requestid"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func serve(tt *testing.T, handler gin.HandlerFunc) (*httptest.ResponseRecorder, ErrorResponse) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(requestid.RequestIDMiddleware(), ErrorHandlerMiddleware())
+	router.GET("/", handler)
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(requestid.Header, "req-1")
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	resp := ErrorResponse{}
+	if recorder.Code != http.StatusOK {
+		assert.NoError(tt, json.Unmarshal(recorder.Body.Bytes(), &resp))
+	}
+	return recorder, resp
+}
+
+func TestErrorHandlerMiddleware(t *testing.T) {
+	t.Parallel()
+
+	t.Run("happy case: status of the error kind", func(tt *testing.T) {
+		cases := map[int]error{
+			http.StatusBadRequest:          apperror.InvalidInput("invalid bus stop: %s", "x"),
+			http.StatusNotFound:            apperror.NotFound("cannot find bus stop with ID: %s", "-1"),
+			http.StatusUnauthorized:        apperror.Unauthorized("missing token"),
+			http.StatusForbidden:           apperror.Forbidden("requires the admin role"),
+			http.StatusBadGateway:          apperror.Upstream(errors.New("connection refused"), "uwave is unavailable"),
+			http.StatusInternalServerError: errors.New("disk full"),
+		}
+		for status, err := range cases {
+			recorder, resp := serve(tt, func(c *gin.Context) {
+				c.Error(err)
+			})
+			assert.Equal(tt, status, recorder.Code)
+			assert.Equal(tt, apperror.KindOf(err), resp.Code)
+			assert.Equal(tt, apperror.MessageOf(err), resp.Message)
+			assert.Equal(tt, resp.Message, resp.Error)
+			assert.Equal(tt, "req-1", resp.RequestID)
+		}
+	})
+
+	t.Run("happy case: rate limited errors tell when to retry", func(tt *testing.T) {
+		recorder, resp := serve(tt, func(c *gin.Context) {
+			c.Error(apperror.RateLimited(1500*time.Millisecond, "rate limit exceeded"))
+		})
+		assert.Equal(tt, http.StatusTooManyRequests, recorder.Code)
+		assert.Equal(tt, "2", recorder.Header().Get("Retry-After"))
+		assert.Equal(tt, apperror.KindRateLimited, resp.Code)
+	})
+
+	t.Run("happy case: written responses are kept", func(tt *testing.T) {
+		recorder, _ := serve(tt, func(c *gin.Context) {
+			c.Error(errors.New("ignored"))
+			c.String(http.StatusOK, "ok")
+		})
+		assert.Equal(tt, http.StatusOK, recorder.Code)
+		assert.Equal(tt, "ok", recorder.Body.String())
+	})
+}
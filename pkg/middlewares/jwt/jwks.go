@@ -0,0 +1,168 @@
+package jwt
+
+import (
+	"context"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+
+	"bus-timing/pkg/apperror"
+
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	defaultJWKSRefreshInterval = time.Hour
+	// minJWKSRefetchInterval limits how often the keys are fetched
+	minJWKSRefetchInterval = 30 * time.Second
+	jwksTimeout            = 10 * time.Second
+)
+
+// JWKS is the JSON Web Key Set of an external issuer, its RSA keys are fetched from URL
+// and refetched every RefreshInterval, or when a token is signed with a key it does not have.
+// Fetches are made at most every 30 seconds and shared by the requests waiting for them.
+type JWKS struct {
+	URL string
+	// Issuer and Audience are the iss and aud claims tokens must have, they are not checked when empty
+	Issuer          string
+	Audience        string
+	RefreshInterval time.Duration
+	Client          *http.Client
+
+	group       singleflight.Group
+	mu          sync.Mutex
+	keys        map[string]*rsa.PublicKey
+	fetchedAt   time.Time
+	attemptedAt time.Time
+}
+
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Kid string `json:"kid"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+// Key returns the RSA key with the key ID kid.
+// A key that was fetched is returned right away, stale keys are refreshed in the background.
+func (jwks *JWKS) Key(kid string) (*rsa.PublicKey, error) {
+	key, ok, stale := jwks.cached(kid)
+	if ok {
+		if stale {
+			go jwks.refresh()
+		}
+		return key, nil
+	}
+
+	if err := jwks.refresh(); err != nil {
+		return nil, err
+	}
+	if key, ok, _ = jwks.cached(kid); !ok {
+		return nil, apperror.Unauthorized("invalid token: unknown key %q", kid)
+	}
+	return key, nil
+}
+
+// cached returns the key with the key ID kid, and whether the keys are stale and may be fetched again
+func (jwks *JWKS) cached(kid string) (*rsa.PublicKey, bool, bool) {
+	jwks.mu.Lock()
+	defer jwks.mu.Unlock()
+
+	refreshInterval := jwks.RefreshInterval
+	if refreshInterval <= 0 {
+		refreshInterval = defaultJWKSRefreshInterval
+	}
+	key, ok := jwks.keys[kid]
+	stale := time.Since(jwks.fetchedAt) > refreshInterval && time.Since(jwks.attemptedAt) > minJWKSRefetchInterval
+	return key, ok, stale
+}
+
+// refresh fetches the keys, unless they were fetched less than minJWKSRefetchInterval ago.
+// Failed fetches are not retried right away either, not to flood an unavailable issuer,
+// and the keys of a previous fetch are kept meanwhile.
+func (jwks *JWKS) refresh() error {
+	_, err, _ := jwks.group.Do("", func() (interface{}, error) {
+		jwks.mu.Lock()
+		if time.Since(jwks.attemptedAt) <= minJWKSRefetchInterval {
+			jwks.mu.Unlock()
+			return nil, nil
+		}
+		jwks.attemptedAt = time.Now()
+		jwks.mu.Unlock()
+
+		keys, err := jwks.fetch()
+		if err != nil {
+			return nil, err
+		}
+		jwks.mu.Lock()
+		jwks.keys = keys
+		jwks.fetchedAt = time.Now()
+		jwks.mu.Unlock()
+		return nil, nil
+	})
+	return err
+}
+
+func (jwks *JWKS) fetch() (map[string]*rsa.PublicKey, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), jwksTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, jwks.URL, nil)
+	if err != nil {
+		return nil, err
+	}
+	client := jwks.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, apperror.Upstream(err, "jwks is unavailable")
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return nil, apperror.Upstream(fmt.Errorf("status %d", resp.StatusCode), "jwks is unavailable")
+	}
+
+	body := struct {
+		Keys []jsonWebKey `json:"keys"`
+	}{}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, apperror.Upstream(err, "invalid response from jwks")
+	}
+
+	keys := make(map[string]*rsa.PublicKey)
+	for _, val := range body.Keys {
+		if val.Kty != "RSA" || (val.Use != "" && val.Use != "sig") {
+			continue
+		}
+		key, err := rsaPublicKey(val)
+		if err != nil {
+			return nil, apperror.Upstream(err, "invalid key %q from jwks", val.Kid)
+		}
+		keys[val.Kid] = key
+	}
+	return keys, nil
+}
+
+func rsaPublicKey(key jsonWebKey) (*rsa.PublicKey, error) {
+	n, err := base64.RawURLEncoding.DecodeString(key.N)
+	if err != nil {
+		return nil, err
+	}
+	e, err := base64.RawURLEncoding.DecodeString(key.E)
+	if err != nil {
+		return nil, err
+	}
+	exponent := new(big.Int).SetBytes(e)
+	if !exponent.IsInt64() || exponent.Int64() > 1<<31-1 {
+		return nil, fmt.Errorf("exponent is too large")
+	}
+	return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(exponent.Int64())}, nil
+}
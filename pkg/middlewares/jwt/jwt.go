@@ -1,89 +1,234 @@
 package jwt
 
 import (
-	"log"
-	"net/http"
+	"crypto/subtle"
+	"errors"
+	"strings"
 	"time"
 
-	"github.com/dgrijalva/jwt-go"
+	"bus-timing/pkg/apperror"
+
 	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	// AccessToken and RefreshToken are the token types of the tokens issued here
+	AccessToken  = "access"
+	RefreshToken = "refresh"
+
+	bearerPrefix = "bearer "
+	claimsKey    = "jwt.claims"
+
+	defaultAccessTokenTTL  = 15 * time.Minute
+	defaultRefreshTokenTTL = 24 * time.Hour
 )
 
 type JWTClaims struct {
-	UserID int `json:"client"`
-	jwt.StandardClaims
+	ClientID string   `json:"client,omitempty"`
+	Roles    []string `json:"roles,omitempty"`
+	// TokenType tells access tokens from refresh tokens, it is empty on tokens of external issuers
+	TokenType string `json:"tokenType,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// Client can exchange its secret for tokens carrying its roles
+type Client struct {
+	Secret string
+	Roles  []string
 }
 
-func Authorized() gin.HandlerFunc {
+type TokenPair struct {
+	AccessToken  string `json:"accessToken"`
+	RefreshToken string `json:"refreshToken"`
+	// ExpiresIn is how many seconds the access token is valid
+	ExpiresIn int64 `json:"expiresIn"`
+}
+
+// Authenticator issues HS256 tokens signed with SecretKey and accepts them, as well as RS256 tokens
+// of an external issuer publishing its keys at a JWKS endpoint.
+type Authenticator struct {
+	SecretKey []byte
+	// Issuer is the iss claim of the tokens issued here
+	Issuer          string
+	AccessTokenTTL  time.Duration
+	RefreshTokenTTL time.Duration
+	// Clients maps the IDs of the clients allowed to request tokens to their credentials
+	Clients map[string]Client
+	// JWKS verifies RS256 tokens, they are refused when nil
+	JWKS *JWKS
+}
+
+// Authorized lets requests with a valid access token in the Authorization header through,
+// their claims are read with GetClaims.
+func (authenticator *Authenticator) Authorized() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		headerAuthorization := c.GetHeader("Authorization")
-
-		if headerAuthorization != "" {
-			token, err := decodeToken(headerAuthorization)
-			if err != nil {
-				log.Println("JWT Authorized", err.Error())
-				c.Abort()
-
-				c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid Token"})
-				return
-			}
-
-			if token.Valid {
-				c.Next()
-			}
-		} else {
+		token, ok := BearerToken(c.GetHeader("Authorization"))
+		if !ok {
+			c.Error(apperror.Unauthorized("missing token"))
 			c.Abort()
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Missing Token"})
 			return
 		}
+
+		claims, err := authenticator.Parse(token)
+		if err == nil && claims.TokenType == RefreshToken {
+			err = apperror.Unauthorized("invalid token: refresh tokens cannot authorize requests")
+		}
+		if err != nil {
+			c.Error(err)
+			c.Abort()
+			return
+		}
+
+		c.Set(claimsKey, *claims)
+		c.Next()
+	}
+}
+
+// GetClaims returns the claims of the token Authorized let through.
+func GetClaims(c *gin.Context) (JWTClaims, bool) {
+	val, ok := c.Get(claimsKey)
+	if !ok {
+		return JWTClaims{}, false
 	}
+	claims, ok := val.(JWTClaims)
+	return claims, ok
 }
 
-func decodeToken(tokenHeader string) (*jwt.Token, error) {
-	return jwt.ParseWithClaims(
-		tokenHeader,
-		&JWTClaims{},
-		func(token *jwt.Token) (interface{}, error) {
-			return []byte("config.Config.SecretKeyJWT"), nil
-		},
-	)
+// BearerToken returns the token of a "Bearer <token>" Authorization header.
+func BearerToken(header string) (string, bool) {
+	if len(header) < len(bearerPrefix) || !strings.EqualFold(header[:len(bearerPrefix)], bearerPrefix) {
+		return "", false
+	}
+	token := strings.TrimSpace(header[len(bearerPrefix):])
+	return token, token != ""
 }
 
-func GetClaims(token string) JWTClaims {
-	jwtToken, err := decodeToken(token)
+// Parse verifies the signature, expiry, issuer and audience of a token and returns its claims.
+func (authenticator *Authenticator) Parse(tokenString string) (*JWTClaims, error) {
+	// the issuer and audience to expect depend on who signed the token
+	unverified, _, err := jwt.NewParser().ParseUnverified(tokenString, &JWTClaims{})
 	if err != nil {
-		return JWTClaims{}
+		return nil, apperror.Unauthorized("invalid token: %s", err)
 	}
 
-	claims, ok := jwtToken.Claims.(*JWTClaims)
-	if !ok {
-		return JWTClaims{}
+	claims := &JWTClaims{}
+	if _, err := jwt.ParseWithClaims(tokenString, claims, authenticator.key, authenticator.parserOptions(unverified.Method)...); err != nil {
+		var appErr *apperror.Error
+		if errors.As(err, &appErr) {
+			return nil, appErr
+		}
+		return nil, apperror.Unauthorized("invalid token: %s", err)
+	}
+	return claims, nil
+}
+
+// parserOptions accept the signing method only, with the issuer and audience of its tokens
+func (authenticator *Authenticator) parserOptions(method jwt.SigningMethod) []jwt.ParserOption {
+	options := []jwt.ParserOption{jwt.WithValidMethods([]string{method.Alg()})}
+	switch method {
+	case jwt.SigningMethodHS256:
+		if authenticator.Issuer != "" {
+			options = append(options, jwt.WithIssuer(authenticator.Issuer))
+		}
+	case jwt.SigningMethodRS256:
+		if authenticator.JWKS != nil && authenticator.JWKS.Issuer != "" {
+			options = append(options, jwt.WithIssuer(authenticator.JWKS.Issuer))
+		}
+		if authenticator.JWKS != nil && authenticator.JWKS.Audience != "" {
+			options = append(options, jwt.WithAudience(authenticator.JWKS.Audience))
+		}
 	}
+	return options
+}
 
-	return *claims
+// key returns the key verifying the signature of token
+func (authenticator *Authenticator) key(token *jwt.Token) (interface{}, error) {
+	switch token.Method {
+	case jwt.SigningMethodHS256:
+		if len(authenticator.SecretKey) == 0 {
+			return nil, apperror.Unauthorized("invalid token: HS256 tokens are not accepted")
+		}
+		return authenticator.SecretKey, nil
+	case jwt.SigningMethodRS256:
+		if authenticator.JWKS == nil {
+			return nil, apperror.Unauthorized("invalid token: RS256 tokens are not accepted")
+		}
+		kid, _ := token.Header["kid"].(string)
+		return authenticator.JWKS.Key(kid)
+	default:
+		return nil, apperror.Unauthorized("invalid token: unsupported signing method %s", token.Method.Alg())
+	}
 }
 
-// GenerateJWT the Claims
-func GenerateJWT(claims JWTClaims) (map[string]string, error) {
-	claims.ExpiresAt = time.Now().Add(15 * time.Minute).Unix()
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	accessToken, err := token.SignedString([]byte("config.Config.SecretKeyJWT"))
-	if err != nil {
-		return nil, err
+// GenerateJWT signs an access token and a refresh token for the subject and client of claims.
+func (authenticator *Authenticator) GenerateJWT(claims JWTClaims) (TokenPair, error) {
+	if len(authenticator.SecretKey) == 0 {
+		return TokenPair{}, errors.New("jwt: no secret key to sign tokens")
+	}
+	accessTokenTTL := authenticator.AccessTokenTTL
+	if accessTokenTTL <= 0 {
+		accessTokenTTL = defaultAccessTokenTTL
+	}
+	refreshTokenTTL := authenticator.RefreshTokenTTL
+	if refreshTokenTTL <= 0 {
+		refreshTokenTTL = defaultRefreshTokenTTL
 	}
 
-	refreshToken := jwt.New(jwt.SigningMethodHS256)
-	rtClaims := refreshToken.Claims.(jwt.MapClaims)
-	rtClaims["sub"] = 1
-	rtClaims["exp"] = time.Now().Add(time.Hour * 24).Unix()
+	now := time.Now()
+	claims.Issuer = authenticator.Issuer
+	claims.IssuedAt = jwt.NewNumericDate(now)
 
-	rfToken, err := refreshToken.SignedString([]byte("secret"))
+	claims.TokenType = AccessToken
+	claims.ExpiresAt = jwt.NewNumericDate(now.Add(accessTokenTTL))
+	accessToken, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(authenticator.SecretKey)
 	if err != nil {
-		return nil, err
+		return TokenPair{}, err
 	}
 
-	return map[string]string{
-		"access_token":  accessToken,
-		"refresh_token": rfToken,
+	claims.TokenType = RefreshToken
+	claims.ExpiresAt = jwt.NewNumericDate(now.Add(refreshTokenTTL))
+	refreshToken, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(authenticator.SecretKey)
+	if err != nil {
+		return TokenPair{}, err
+	}
+
+	return TokenPair{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int64(accessTokenTTL.Seconds()),
 	}, nil
 }
+
+// IssueToken exchanges the credentials of a client for tokens.
+func (authenticator *Authenticator) IssueToken(clientID, clientSecret string) (TokenPair, error) {
+	client, ok := authenticator.Clients[clientID]
+	if !ok || subtle.ConstantTimeCompare([]byte(client.Secret), []byte(clientSecret)) != 1 {
+		return TokenPair{}, apperror.Unauthorized("invalid client credentials")
+	}
+	return authenticator.GenerateJWT(JWTClaims{
+		ClientID:         clientID,
+		Roles:            client.Roles,
+		RegisteredClaims: jwt.RegisteredClaims{Subject: clientID},
+	})
+}
+
+// RefreshToken exchanges a refresh token issued here for new tokens, with the current roles of the client.
+func (authenticator *Authenticator) RefreshToken(refreshToken string) (TokenPair, error) {
+	claims, err := authenticator.Parse(refreshToken)
+	if err != nil {
+		return TokenPair{}, err
+	}
+	if claims.TokenType != RefreshToken {
+		return TokenPair{}, apperror.Unauthorized("invalid token: not a refresh token")
+	}
+	client, ok := authenticator.Clients[claims.ClientID]
+	if !ok {
+		return TokenPair{}, apperror.Unauthorized("invalid token: unknown client %q", claims.ClientID)
+	}
+	return authenticator.GenerateJWT(JWTClaims{
+		ClientID:         claims.ClientID,
+		Roles:            client.Roles,
+		RegisteredClaims: jwt.RegisteredClaims{Subject: claims.Subject},
+	})
+}
@@ -0,0 +1,293 @@
+package jwt
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"bus-timing/pkg/apperror"
+	"bus-timing/pkg/middlewares/errorhandler"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+func mockAuthenticator() *Authenticator {
+	return &Authenticator{
+		SecretKey: []byte("secret"),
+		Issuer:    "bus-timing",
+		Clients: map[string]Client{
+			"partner": {Secret: "partner-secret", Roles: []string{RolePartner}},
+			"admin":   {Secret: "admin-secret", Roles: []string{RoleAdmin}},
+		},
+	}
+}
+
+func serve(authenticator *Authenticator, header string) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(errorhandler.ErrorHandlerMiddleware(), authenticator.Authorized())
+	router.GET("/", func(c *gin.Context) {
+		claims, _ := GetClaims(c)
+		c.String(http.StatusOK, claims.Subject)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	if header != "" {
+		req.Header.Set("Authorization", header)
+	}
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+	return recorder
+}
+
+func TestBearerToken(t *testing.T) {
+	t.Parallel()
+
+	token, ok := BearerToken("Bearer abc.def.ghi")
+	assert.True(t, ok)
+	assert.Equal(t, "abc.def.ghi", token)
+	token, ok = BearerToken("bearer  abc.def.ghi ")
+	assert.True(t, ok)
+	assert.Equal(t, "abc.def.ghi", token)
+	_, ok = BearerToken("abc.def.ghi")
+	assert.False(t, ok)
+	_, ok = BearerToken("Bearer ")
+	assert.False(t, ok)
+}
+
+func TestAuthenticator_Authorized(t *testing.T) {
+	t.Parallel()
+
+	t.Run("happy case: issued access token", func(tt *testing.T) {
+		authenticator := mockAuthenticator()
+		tokens, err := authenticator.IssueToken("partner", "partner-secret")
+		assert.NoError(tt, err)
+		assert.Equal(tt, int64(defaultAccessTokenTTL.Seconds()), tokens.ExpiresIn)
+
+		recorder := serve(authenticator, "Bearer "+tokens.AccessToken)
+		assert.Equal(tt, http.StatusOK, recorder.Code)
+		assert.Equal(tt, "partner", recorder.Body.String())
+	})
+
+	t.Run("bad case: missing, refresh or foreign tokens", func(tt *testing.T) {
+		authenticator := mockAuthenticator()
+		tokens, err := authenticator.IssueToken("partner", "partner-secret")
+		assert.NoError(tt, err)
+		foreign := &Authenticator{SecretKey: []byte("other"), Issuer: "bus-timing"}
+		foreignTokens, err := foreign.GenerateJWT(JWTClaims{ClientID: "partner"})
+		assert.NoError(tt, err)
+		otherIssuer := &Authenticator{SecretKey: []byte("secret"), Issuer: "other"}
+		otherIssuerTokens, err := otherIssuer.GenerateJWT(JWTClaims{ClientID: "partner"})
+		assert.NoError(tt, err)
+
+		for _, header := range []string{"", tokens.AccessToken, "Bearer " + tokens.RefreshToken, "Bearer " + foreignTokens.AccessToken, "Bearer " + otherIssuerTokens.AccessToken} {
+			recorder := serve(authenticator, header)
+			assert.Equal(tt, http.StatusUnauthorized, recorder.Code, header)
+			resp := errorhandler.ErrorResponse{}
+			assert.NoError(tt, json.Unmarshal(recorder.Body.Bytes(), &resp))
+			assert.Equal(tt, apperror.KindUnauthorized, resp.Code)
+		}
+	})
+
+	t.Run("bad case: expired token", func(tt *testing.T) {
+		authenticator := mockAuthenticator()
+		expired := jwt.NewWithClaims(jwt.SigningMethodHS256, JWTClaims{
+			TokenType:        AccessToken,
+			RegisteredClaims: jwt.RegisteredClaims{Issuer: "bus-timing", ExpiresAt: jwt.NewNumericDate(time.Now().Add(-time.Minute))},
+		})
+		token, err := expired.SignedString(authenticator.SecretKey)
+		assert.NoError(tt, err)
+		assert.Equal(tt, http.StatusUnauthorized, serve(authenticator, "Bearer "+token).Code)
+	})
+}
+
+func TestAuthenticator_IssueToken(t *testing.T) {
+	t.Parallel()
+
+	t.Run("happy case: refresh", func(tt *testing.T) {
+		authenticator := mockAuthenticator()
+		tokens, err := authenticator.IssueToken("partner", "partner-secret")
+		assert.NoError(tt, err)
+
+		refreshed, err := authenticator.RefreshToken(tokens.RefreshToken)
+		assert.NoError(tt, err)
+		claims, err := authenticator.Parse(refreshed.AccessToken)
+		assert.NoError(tt, err)
+		assert.Equal(tt, "partner", claims.ClientID)
+		assert.Equal(tt, []string{RolePartner}, claims.Roles)
+		assert.Equal(tt, AccessToken, claims.TokenType)
+	})
+
+	t.Run("bad case: invalid credentials or access token", func(tt *testing.T) {
+		authenticator := mockAuthenticator()
+		_, err := authenticator.IssueToken("partner", "wrong")
+		assert.Equal(tt, apperror.Unauthorized("invalid client credentials"), err)
+		_, err = authenticator.IssueToken("unknown", "")
+		assert.Equal(tt, apperror.Unauthorized("invalid client credentials"), err)
+
+		tokens, err := authenticator.IssueToken("partner", "partner-secret")
+		assert.NoError(tt, err)
+		_, err = authenticator.RefreshToken(tokens.AccessToken)
+		assert.Equal(tt, apperror.Unauthorized("invalid token: not a refresh token"), err)
+	})
+}
+
+// mockJWKSHandler answers the key set of privateKey with the key ID key-1, after release is closed when not nil
+func mockJWKSHandler(privateKey *rsa.PrivateKey, fetches *atomic.Int32, release chan struct{}) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		fetches.Add(1)
+		if release != nil {
+			<-release
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"keys": []jsonWebKey{{
+				Kty: "RSA",
+				Use: "sig",
+				Kid: "key-1",
+				N:   base64.RawURLEncoding.EncodeToString(privateKey.N.Bytes()),
+				E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(privateKey.E)).Bytes()),
+			}},
+		})
+	}
+}
+
+func TestJWKS(t *testing.T) {
+	t.Parallel()
+
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	fetches := &atomic.Int32{}
+	server := httptest.NewServer(mockJWKSHandler(privateKey, fetches, nil))
+	defer server.Close()
+
+	authenticator := &Authenticator{JWKS: &JWKS{URL: server.URL, Issuer: "https://issuer.example", Audience: "bus-timing"}}
+	sign := func(kid, issuer string, audience ...string) string {
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, JWTClaims{
+			RegisteredClaims: jwt.RegisteredClaims{
+				Subject:   "rider",
+				Issuer:    issuer,
+				Audience:  audience,
+				ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+			},
+		})
+		token.Header["kid"] = kid
+		signed, err := token.SignedString(privateKey)
+		assert.NoError(t, err)
+		return signed
+	}
+
+	recorder := serve(authenticator, "Bearer "+sign("key-1", "https://issuer.example", "bus-timing"))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "rider", recorder.Body.String())
+	// aud may be an array
+	recorder = serve(authenticator, "Bearer "+sign("key-1", "https://issuer.example", "other-service", "bus-timing"))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	assert.Equal(t, http.StatusUnauthorized, serve(authenticator, "Bearer "+sign("key-1", "https://other.example", "bus-timing")).Code)
+	assert.Equal(t, http.StatusUnauthorized, serve(authenticator, "Bearer "+sign("key-1", "https://issuer.example", "other-service")).Code)
+	assert.Equal(t, http.StatusUnauthorized, serve(authenticator, "Bearer "+sign("key-1", "https://issuer.example")).Code)
+	// an unknown key is not refetched right after a fetch
+	assert.Equal(t, http.StatusUnauthorized, serve(authenticator, "Bearer "+sign("key-2", "https://issuer.example", "bus-timing")).Code)
+	assert.Equal(t, int32(1), fetches.Load())
+	// HS256 tokens are refused without a secret key
+	tokens, err := mockAuthenticator().GenerateJWT(JWTClaims{})
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusUnauthorized, serve(authenticator, "Bearer "+tokens.AccessToken).Code)
+}
+
+func TestJWKS_Key(t *testing.T) {
+	t.Parallel()
+
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+
+	t.Run("happy case: concurrent first requests share one fetch", func(tt *testing.T) {
+		fetches := &atomic.Int32{}
+		release := make(chan struct{})
+		server := httptest.NewServer(mockJWKSHandler(privateKey, fetches, release))
+		defer server.Close()
+
+		jwks := &JWKS{URL: server.URL}
+		wg := sync.WaitGroup{}
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				key, err := jwks.Key("key-1")
+				assert.NoError(tt, err)
+				assert.Equal(tt, privateKey.N, key.N)
+			}()
+		}
+		assert.Eventually(tt, func() bool { return fetches.Load() == 1 }, time.Second, time.Millisecond)
+		close(release)
+		wg.Wait()
+		assert.Equal(tt, int32(1), fetches.Load())
+	})
+
+	t.Run("happy case: stale keys are answered while they are refetched", func(tt *testing.T) {
+		fetches := &atomic.Int32{}
+		release := make(chan struct{})
+		server := httptest.NewServer(mockJWKSHandler(privateKey, fetches, release))
+		defer server.Close()
+		defer close(release)
+
+		jwks := &JWKS{URL: server.URL}
+		jwks.keys = map[string]*rsa.PublicKey{"key-1": &privateKey.PublicKey}
+		jwks.fetchedAt = time.Now().Add(-2 * defaultJWKSRefreshInterval)
+		jwks.attemptedAt = jwks.fetchedAt
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			key, err := jwks.Key("key-1")
+			assert.NoError(tt, err)
+			assert.Equal(tt, &privateKey.PublicKey, key)
+		}()
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			tt.Fatal("Key waited for the refetch")
+		}
+		assert.Eventually(tt, func() bool { return fetches.Load() == 1 }, time.Second, time.Millisecond)
+	})
+}
+
+func TestRequireRole(t *testing.T) {
+	t.Parallel()
+
+	authenticator := mockAuthenticator()
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(errorhandler.ErrorHandlerMiddleware(), authenticator.Authorized())
+	router.GET("/", RequireRole(RoleOperator, RolePartner), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	router.GET("/admin", RequireRole(RoleAdmin), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	serveAs := func(clientID, path string) int {
+		tokens, err := authenticator.IssueToken(clientID, clientID+"-secret")
+		assert.NoError(t, err)
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("Authorization", "Bearer "+tokens.AccessToken)
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+		return recorder.Code
+	}
+
+	assert.Equal(t, http.StatusOK, serveAs("partner", "/"))
+	assert.Equal(t, http.StatusForbidden, serveAs("partner", "/admin"))
+	// admins have every role
+	assert.Equal(t, http.StatusOK, serveAs("admin", "/"))
+	assert.Equal(t, http.StatusOK, serveAs("admin", "/admin"))
+}
@@ -0,0 +1,50 @@
+package jwt
+
+import (
+	"slices"
+	"strings"
+
+	"bus-timing/pkg/apperror"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Roles of the clients of the API
+const (
+	RoleRider    = "rider"
+	RolePartner  = "partner"
+	RoleOperator = "operator"
+	// RoleAdmin is granted every role requirement
+	RoleAdmin = "admin"
+)
+
+// HasRole tells whether the claims have one of roles, admins have them all.
+func (claims JWTClaims) HasRole(roles ...string) bool {
+	if slices.Contains(claims.Roles, RoleAdmin) {
+		return true
+	}
+	for _, role := range roles {
+		if slices.Contains(claims.Roles, role) {
+			return true
+		}
+	}
+	return false
+}
+
+// RequireRole lets requests through when their token has one of roles, it follows Authorized.
+func RequireRole(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, ok := GetClaims(c)
+		if !ok {
+			c.Error(apperror.Unauthorized("missing token"))
+			c.Abort()
+			return
+		}
+		if !claims.HasRole(roles...) {
+			c.Error(apperror.Forbidden("requires the %s role", strings.Join(roles, " or ")))
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,62 @@
+package requestid
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	Header = "X-Request-ID"
+
+	contextKey = "requestID"
+	// maxLength bounds request IDs given by clients
+	maxLength = 128
+)
+
+type contextKeyType struct{}
+
+// RequestIDMiddleware keeps the X-Request-ID of the request, or generates one,
+// and sends it back in the response. The request context carries it to the logs.
+func RequestIDMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(Header)
+		if requestID == "" || len(requestID) > maxLength {
+			requestID = newRequestID()
+		}
+		c.Set(contextKey, requestID)
+		c.Request = c.Request.WithContext(NewContext(c.Request.Context(), requestID))
+		c.Writer.Header().Set(Header, requestID)
+
+		c.Next()
+	}
+}
+
+// Get returns the request ID of the request, empty when the middleware is not used.
+func Get(c *gin.Context) string {
+	return c.GetString(contextKey)
+}
+
+// NewContext returns a copy of ctx carrying requestID.
+func NewContext(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, contextKeyType{}, requestID)
+}
+
+// FromContext returns the request ID ctx carries, handlers' gin.Context included.
+func FromContext(ctx context.Context) string {
+	if c, ok := ctx.(*gin.Context); ok {
+		return Get(c)
+	}
+	requestID, _ := ctx.Value(contextKeyType{}).(string)
+	return requestID
+}
+
+func newRequestID() string {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(buf)
+}
@@ -0,0 +1,53 @@
+package requestid
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestIDMiddleware(t *testing.T) {
+	t.Parallel()
+
+	serve := func(requestID string) (string, string, string) {
+		gin.SetMode(gin.TestMode)
+		router := gin.New()
+		router.Use(RequestIDMiddleware())
+		var fromGin, fromRequest string
+		router.GET("/", func(c *gin.Context) {
+			fromGin = FromContext(c)
+			fromRequest = FromContext(c.Request.Context())
+		})
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		if requestID != "" {
+			req.Header.Set(Header, requestID)
+		}
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+		return recorder.Header().Get(Header), fromGin, fromRequest
+	}
+
+	t.Run("happy case: request ID of the request", func(tt *testing.T) {
+		header, fromGin, fromRequest := serve("req-1")
+		assert.Equal(tt, "req-1", header)
+		assert.Equal(tt, "req-1", fromGin)
+		assert.Equal(tt, "req-1", fromRequest)
+	})
+
+	t.Run("happy case: generated request ID", func(tt *testing.T) {
+		header, fromGin, fromRequest := serve("")
+		assert.Len(tt, header, 32)
+		assert.Equal(tt, header, fromGin)
+		assert.Equal(tt, header, fromRequest)
+	})
+
+	t.Run("bad case: too long request ID is replaced", func(tt *testing.T) {
+		header, _, _ := serve(strings.Repeat("a", maxLength+1))
+		assert.Len(tt, header, 32)
+	})
+}
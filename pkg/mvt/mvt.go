@@ -0,0 +1,321 @@
+package mvt
+
+import (
+	"math"
+	"sort"
+
+	"bus-timing/pkg/location"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+const (
+	// Extent is the number of coordinate units across a tile
+	Extent = 4096
+	// Buffer is how many units beyond the tile edges geometries are kept, so renderers can draw across edges
+	Buffer = 64
+	// MaxZoom is the highest zoom served, Web Mercator tiles are not useful beyond it
+	MaxZoom = 22
+
+	// maxLat is the latitude of the top edge of the Web Mercator world
+	maxLat = 85.05112878
+)
+
+type GeomType uint32
+
+const (
+	Point      GeomType = 1
+	LineString GeomType = 2
+)
+
+// Tile is a Web Mercator (XYZ) tile.
+type Tile struct {
+	Z int
+	X int
+	Y int
+}
+
+type Coordinate struct {
+	X int64
+	Y int64
+}
+
+// Feature geometry has one part with the points of a Point feature, and one part per line of a LineString feature.
+// Properties values are strings, booleans, integers or float64.
+type Feature struct {
+	Type       GeomType
+	Geometry   [][]Coordinate
+	Properties map[string]interface{}
+}
+
+type Layer struct {
+	Name     string
+	Features []Feature
+}
+
+// TileAt returns the tile of point at zoom.
+func TileAt(point location.Location, zoom int) Tile {
+	n := math.Exp2(float64(zoom))
+	x, y := mercator(point, n)
+	clamp := func(val float64) int {
+		return int(math.Max(0, math.Min(n-1, math.Floor(val))))
+	}
+	return Tile{Z: zoom, X: clamp(x), Y: clamp(y)}
+}
+
+// Valid tells whether the tile exists.
+func (tile Tile) Valid() bool {
+	if tile.Z < 0 || tile.Z > MaxZoom {
+		return false
+	}
+	n := 1 << tile.Z
+	return tile.X >= 0 && tile.X < n && tile.Y >= 0 && tile.Y < n
+}
+
+// Bounds returns the south west and north east corners of the tile, with the buffer.
+func (tile Tile) Bounds() (location.Location, location.Location) {
+	n := math.Exp2(float64(tile.Z))
+	buffer := float64(Buffer) / Extent
+	west := (float64(tile.X)-buffer)/n*360 - 180
+	east := (float64(tile.X)+1+buffer)/n*360 - 180
+	north := tileLat(float64(tile.Y)-buffer, n)
+	south := tileLat(float64(tile.Y)+1+buffer, n)
+	return location.Location{Lat: south, Lng: west}, location.Location{Lat: north, Lng: east}
+}
+
+// Project returns the coordinate of point in the tile, points outside of the tile are outside of [0, Extent).
+func (tile Tile) Project(point location.Location) Coordinate {
+	x, y := mercator(point, math.Exp2(float64(tile.Z)))
+	return Coordinate{
+		X: int64(math.Round((x - float64(tile.X)) * Extent)),
+		Y: int64(math.Round((y - float64(tile.Y)) * Extent)),
+	}
+}
+
+// Contains tells whether the coordinate is in the tile or its buffer.
+func (tile Tile) Contains(coordinate Coordinate) bool {
+	return outcode(coordinate) == 0
+}
+
+// ClipLine projects path and returns the parts of it crossing the tile or its buffer.
+// Segments are kept whole, renderers clip them at the buffer.
+func (tile Tile) ClipLine(path []location.Location) [][]Coordinate {
+	parts := make([][]Coordinate, 0)
+	var part []Coordinate
+	var previous Coordinate
+	for i, point := range path {
+		coordinate := tile.Project(point)
+		if i > 0 && coordinate == previous {
+			continue
+		}
+		if i > 0 && outcode(previous)&outcode(coordinate) == 0 {
+			if len(part) == 0 {
+				part = append(part, previous)
+			}
+			part = append(part, coordinate)
+		} else if len(part) > 0 {
+			parts = append(parts, part)
+			part = nil
+		}
+		previous = coordinate
+	}
+	if len(part) > 0 {
+		parts = append(parts, part)
+	}
+	return parts
+}
+
+// outcode tells on which sides of the buffered tile a coordinate is, 0 when it is inside
+func outcode(coordinate Coordinate) int {
+	code := 0
+	switch {
+	case coordinate.X < -Buffer:
+		code |= 1
+	case coordinate.X > Extent+Buffer:
+		code |= 2
+	}
+	switch {
+	case coordinate.Y < -Buffer:
+		code |= 4
+	case coordinate.Y > Extent+Buffer:
+		code |= 8
+	}
+	return code
+}
+
+// mercator returns the Web Mercator position of point in tiles, for n tiles across the world
+func mercator(point location.Location, n float64) (float64, float64) {
+	lat := math.Max(-maxLat, math.Min(maxLat, point.Lat)) * math.Pi / 180
+	x := (point.Lng + 180) / 360 * n
+	y := (1 - math.Log(math.Tan(lat)+1/math.Cos(lat))/math.Pi) / 2 * n
+	return x, y
+}
+
+func tileLat(y, n float64) float64 {
+	return math.Atan(math.Sinh(math.Pi*(1-2*y/n))) * 180 / math.Pi
+}
+
+// Encode returns the Mapbox Vector Tile (version 2) of layers, empty layers are left out.
+func Encode(layers []Layer) []byte {
+	var tile []byte
+	for _, layer := range layers {
+		if len(layer.Features) == 0 {
+			continue
+		}
+		tile = protowire.AppendTag(tile, 3, protowire.BytesType)
+		tile = protowire.AppendBytes(tile, encodeLayer(layer))
+	}
+	return tile
+}
+
+func encodeLayer(layer Layer) []byte {
+	var buf []byte
+	buf = protowire.AppendTag(buf, 15, protowire.VarintType)
+	buf = protowire.AppendVarint(buf, 2)
+	buf = protowire.AppendTag(buf, 1, protowire.BytesType)
+	buf = protowire.AppendString(buf, layer.Name)
+
+	keys := make([]string, 0)
+	keyIndex := make(map[string]int)
+	values := make([][]byte, 0)
+	valueIndex := make(map[string]int)
+	for _, feature := range layer.Features {
+		geometry := encodeGeometry(feature)
+		if len(geometry) == 0 {
+			continue
+		}
+
+		// keys are sorted so a tile is encoded the same every time
+		names := make([]string, 0, len(feature.Properties))
+		for name := range feature.Properties {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		tags := make([]uint64, 0, 2*len(names))
+		for _, name := range names {
+			value, ok := encodeValue(feature.Properties[name])
+			if !ok {
+				continue
+			}
+			key, ok := keyIndex[name]
+			if !ok {
+				key = len(keys)
+				keyIndex[name] = key
+				keys = append(keys, name)
+			}
+			idx, ok := valueIndex[string(value)]
+			if !ok {
+				idx = len(values)
+				valueIndex[string(value)] = idx
+				values = append(values, value)
+			}
+			tags = append(tags, uint64(key), uint64(idx))
+		}
+
+		var encoded []byte
+		if len(tags) > 0 {
+			encoded = protowire.AppendTag(encoded, 2, protowire.BytesType)
+			encoded = protowire.AppendBytes(encoded, packed(tags))
+		}
+		encoded = protowire.AppendTag(encoded, 3, protowire.VarintType)
+		encoded = protowire.AppendVarint(encoded, uint64(feature.Type))
+		encoded = protowire.AppendTag(encoded, 4, protowire.BytesType)
+		encoded = protowire.AppendBytes(encoded, packed(geometry))
+
+		buf = protowire.AppendTag(buf, 2, protowire.BytesType)
+		buf = protowire.AppendBytes(buf, encoded)
+	}
+
+	for _, key := range keys {
+		buf = protowire.AppendTag(buf, 3, protowire.BytesType)
+		buf = protowire.AppendString(buf, key)
+	}
+	for _, value := range values {
+		buf = protowire.AppendTag(buf, 4, protowire.BytesType)
+		buf = protowire.AppendBytes(buf, value)
+	}
+	buf = protowire.AppendTag(buf, 5, protowire.VarintType)
+	buf = protowire.AppendVarint(buf, Extent)
+	return buf
+}
+
+// geometry commands
+const (
+	moveTo = 1
+	lineTo = 2
+)
+
+// encodeGeometry returns the commands drawing the feature, empty when it has nothing to draw
+func encodeGeometry(feature Feature) []uint64 {
+	geometry := make([]uint64, 0)
+	var cursor Coordinate
+	draw := func(coordinate Coordinate) {
+		geometry = append(geometry, protowire.EncodeZigZag(coordinate.X-cursor.X), protowire.EncodeZigZag(coordinate.Y-cursor.Y))
+		cursor = coordinate
+	}
+
+	switch feature.Type {
+	case Point:
+		points := make([]Coordinate, 0)
+		for _, part := range feature.Geometry {
+			points = append(points, part...)
+		}
+		if len(points) == 0 {
+			return nil
+		}
+		geometry = append(geometry, command(moveTo, len(points)))
+		for _, point := range points {
+			draw(point)
+		}
+	case LineString:
+		for _, part := range feature.Geometry {
+			if len(part) < 2 {
+				continue
+			}
+			geometry = append(geometry, command(moveTo, 1))
+			draw(part[0])
+			geometry = append(geometry, command(lineTo, len(part)-1))
+			for _, point := range part[1:] {
+				draw(point)
+			}
+		}
+	}
+	return geometry
+}
+
+func command(id, count int) uint64 {
+	return uint64(id&0x7 | count<<3)
+}
+
+func packed(vals []uint64) []byte {
+	var buf []byte
+	for _, val := range vals {
+		buf = protowire.AppendVarint(buf, val)
+	}
+	return buf
+}
+
+// encodeValue returns the Value message of a property, false for unsupported types
+func encodeValue(val interface{}) ([]byte, bool) {
+	var buf []byte
+	switch v := val.(type) {
+	case string:
+		buf = protowire.AppendTag(buf, 1, protowire.BytesType)
+		buf = protowire.AppendString(buf, v)
+	case float64:
+		buf = protowire.AppendTag(buf, 3, protowire.Fixed64Type)
+		buf = protowire.AppendFixed64(buf, math.Float64bits(v))
+	case int:
+		buf = protowire.AppendTag(buf, 6, protowire.VarintType)
+		buf = protowire.AppendVarint(buf, protowire.EncodeZigZag(int64(v)))
+	case int64:
+		buf = protowire.AppendTag(buf, 6, protowire.VarintType)
+		buf = protowire.AppendVarint(buf, protowire.EncodeZigZag(v))
+	case bool:
+		buf = protowire.AppendTag(buf, 7, protowire.VarintType)
+		buf = protowire.AppendVarint(buf, protowire.EncodeBool(v))
+	default:
+		return nil, false
+	}
+	return buf, true
+}
@@ -0,0 +1,119 @@
+package mvt
+
+import (
+	"testing"
+
+	"bus-timing/pkg/location"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// fields returns the fields of a protobuf message by number, values of varint fields are their bytes
+func fields(tt *testing.T, buf []byte) map[protowire.Number][][]byte {
+	result := make(map[protowire.Number][][]byte)
+	for len(buf) > 0 {
+		num, typ, n := protowire.ConsumeTag(buf)
+		assert.Greater(tt, n, 0)
+		buf = buf[n:]
+		n = protowire.ConsumeFieldValue(num, typ, buf)
+		assert.Greater(tt, n, 0)
+		value := buf[:n]
+		if typ == protowire.BytesType {
+			value, _ = protowire.ConsumeBytes(buf)
+		}
+		result[num] = append(result[num], value)
+		buf = buf[n:]
+	}
+	return result
+}
+
+func varints(buf []byte) []uint64 {
+	vals := make([]uint64, 0)
+	for len(buf) > 0 {
+		val, n := protowire.ConsumeVarint(buf)
+		vals = append(vals, val)
+		buf = buf[n:]
+	}
+	return vals
+}
+
+func TestTile(t *testing.T) {
+	t.Parallel()
+
+	t.Run("happy case: project", func(tt *testing.T) {
+		assert.Equal(tt, Coordinate{X: 2048, Y: 2048}, Tile{}.Project(location.Location{}))
+		assert.Equal(tt, Coordinate{X: 0, Y: 0}, Tile{Z: 1, X: 1, Y: 1}.Project(location.Location{}))
+		assert.True(tt, Tile{Z: 2, X: 3, Y: 3}.Valid())
+		assert.False(tt, Tile{Z: 2, X: 4, Y: 3}.Valid())
+		assert.False(tt, Tile{Z: MaxZoom + 1}.Valid())
+		assert.Equal(tt, Tile{Z: 15, X: 25822, Y: 16262}, TileAt(location.Location{Lat: 1.33751, Lng: 103.69769}, 15))
+	})
+
+	t.Run("happy case: bounds", func(tt *testing.T) {
+		southWest, northEast := Tile{Z: 1, X: 1, Y: 0}.Bounds()
+		assert.InDelta(tt, 0, southWest.Lat, 3)
+		assert.InDelta(tt, 0, southWest.Lng, 3)
+		assert.Greater(tt, northEast.Lat, 85.0)
+		assert.Greater(tt, northEast.Lng, 180.0)
+	})
+
+	t.Run("happy case: clip line", func(tt *testing.T) {
+		tile := Tile{Z: 1, X: 1, Y: 1}
+		// from the north west tile, through the south east one, back to the north west one
+		parts := tile.ClipLine([]location.Location{
+			{Lat: 10, Lng: -10},
+			{Lat: -10, Lng: 10},
+			{Lat: -20, Lng: 20},
+			{Lat: 20, Lng: -20},
+			{Lat: 30, Lng: -30},
+		})
+		assert.Len(tt, parts, 1)
+		assert.Len(tt, parts[0], 4)
+		assert.True(tt, tile.Contains(parts[0][1]))
+		assert.False(tt, tile.Contains(parts[0][0]))
+	})
+}
+
+func TestEncode(t *testing.T) {
+	t.Parallel()
+
+	t.Run("happy case", func(tt *testing.T) {
+		buf := Encode([]Layer{
+			{
+				Name: "busStops",
+				Features: []Feature{
+					{Type: Point, Geometry: [][]Coordinate{{{X: 25, Y: 17}}}, Properties: map[string]interface{}{"id": "377906", "stale": false}},
+					{Type: Point, Geometry: [][]Coordinate{{{X: 1, Y: 1}}}, Properties: map[string]interface{}{"id": "378224", "stale": false}},
+				},
+			},
+			{
+				Name: "busLines",
+				Features: []Feature{
+					{Type: LineString, Geometry: [][]Coordinate{{{X: 2, Y: 2}, {X: 2, Y: 10}, {X: 10, Y: 10}}}},
+				},
+			},
+			{Name: "empty"},
+		})
+
+		layers := fields(tt, buf)[3]
+		assert.Len(tt, layers, 2)
+
+		busStops := fields(tt, layers[0])
+		assert.Equal(tt, "busStops", string(busStops[1][0]))
+		assert.Equal(tt, []string{"id", "stale"}, []string{string(busStops[3][0]), string(busStops[3][1])})
+		// the stale value is shared by both features
+		assert.Len(tt, busStops[4], 3)
+		assert.Len(tt, busStops[2], 2)
+
+		point := fields(tt, busStops[2][0])
+		assert.Equal(tt, []uint64{0, 0, 1, 1}, varints(point[2][0]))
+		assert.Equal(tt, []uint64{uint64(Point)}, varints(point[3][0]))
+		// MoveTo(1) +25 +17
+		assert.Equal(tt, []uint64{9, 50, 34}, varints(point[4][0]))
+
+		line := fields(tt, fields(tt, layers[1])[2][0])
+		// MoveTo(1) +2 +2, LineTo(2) +0 +8 +8 +0
+		assert.Equal(tt, []uint64{9, 4, 4, 18, 0, 16, 16, 0}, varints(line[4][0]))
+	})
+}
@@ -0,0 +1,55 @@
+package openapi
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testBusStop struct {
+	ID   string  `json:"id"`
+	Lat  float64 `json:"lat"`
+	Name string  `json:"name,omitempty"`
+}
+
+type testNearbyBusStop struct {
+	testBusStop
+	Distance float64 `json:"distance"`
+}
+
+type testResponse struct {
+	Payload   []testNearbyBusStop `json:"payload"`
+	Next      *testResponse       `json:"next,omitempty"`
+	UpdatedAt time.Time           `json:"updatedAt"`
+	Status    int                 `json:"status"`
+	internal  string
+}
+
+func TestDocument_SchemaOf(t *testing.T) {
+	t.Parallel()
+
+	doc := NewDocument(Info{Title: "test", Version: "1"})
+	schema := doc.SchemaOf(testResponse{})
+	assert.Equal(t, "#/components/schemas/testResponse", schema.Ref)
+
+	response := doc.Components.Schemas["testResponse"]
+	assert.Equal(t, []string{"payload", "updatedAt", "status"}, response.Required)
+	assert.Equal(t, "array", response.Properties["payload"].Type)
+	assert.Equal(t, "#/components/schemas/testNearbyBusStop", response.Properties["payload"].Items.Ref)
+	assert.Equal(t, "#/components/schemas/testResponse", response.Properties["next"].Ref)
+	assert.Equal(t, "date-time", response.Properties["updatedAt"].Format)
+	assert.NotContains(t, response.Properties, "internal")
+
+	nearby := doc.Components.Schemas["testNearbyBusStop"]
+	assert.ElementsMatch(t, []string{"id", "lat", "name", "distance"}, keys(nearby.Properties))
+	assert.Equal(t, []string{"id", "lat", "distance"}, nearby.Required)
+}
+
+func keys(m map[string]*Schema) []string {
+	result := make([]string, 0, len(m))
+	for key := range m {
+		result = append(result, key)
+	}
+	return result
+}
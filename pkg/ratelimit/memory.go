@@ -0,0 +1,93 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// MemoryLimiter is a process local limiter, each instance limits its clients on its own.
+type MemoryLimiter struct {
+	mu       sync.Mutex
+	buckets  map[string]memoryBucket
+	counters map[string]memoryCounter
+}
+
+type memoryBucket struct {
+	tokens    float64
+	updatedAt time.Time
+}
+
+type memoryCounter struct {
+	value     int64
+	expiresAt time.Time
+}
+
+func NewMemoryLimiter() *MemoryLimiter {
+	return &MemoryLimiter{
+		buckets:  make(map[string]memoryBucket),
+		counters: make(map[string]memoryCounter),
+	}
+}
+
+func (l *MemoryLimiter) Take(ctx context.Context, key string, rate float64, burst int) (bool, time.Duration, error) {
+	now := time.Now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	bucket, ok := l.buckets[key]
+	if !ok {
+		bucket = memoryBucket{tokens: float64(burst)}
+	} else {
+		bucket.tokens = math.Min(float64(burst), bucket.tokens+now.Sub(bucket.updatedAt).Seconds()*rate)
+	}
+	bucket.updatedAt = now
+
+	allowed := bucket.tokens >= 1
+	if allowed {
+		bucket.tokens--
+	}
+	l.buckets[key] = bucket
+	if allowed {
+		return true, 0, nil
+	}
+	return false, waitFor(bucket.tokens, rate), nil
+}
+
+func (l *MemoryLimiter) Increment(ctx context.Context, key string, ttl time.Duration) (int64, error) {
+	now := time.Now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	counter, ok := l.counters[key]
+	if !ok || now.After(counter.expiresAt) {
+		counter = memoryCounter{expiresAt: now.Add(ttl)}
+		l.evict(now)
+	}
+	counter.value++
+	l.counters[key] = counter
+	return counter.value, nil
+}
+
+func (l *MemoryLimiter) Count(ctx context.Context, key string) (int64, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	counter, ok := l.counters[key]
+	if !ok || time.Now().After(counter.expiresAt) {
+		return 0, nil
+	}
+	return counter.value, nil
+}
+
+// evict drops the expired counters, it is called with the limiter locked when a counter is created
+func (l *MemoryLimiter) evict(now time.Time) {
+	for key, counter := range l.counters {
+		if now.After(counter.expiresAt) {
+			delete(l.counters, key)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryLimiter_Take(t *testing.T) {
+	t.Parallel()
+
+	t.Run("happy case: burst then refill", func(tt *testing.T) {
+		limiter := NewMemoryLimiter()
+		for i := 0; i < 3; i++ {
+			allowed, _, err := limiter.Take(context.Background(), "key", 10, 3)
+			assert.NoError(tt, err)
+			assert.True(tt, allowed)
+		}
+
+		allowed, retryAfter, err := limiter.Take(context.Background(), "key", 10, 3)
+		assert.NoError(tt, err)
+		assert.False(tt, allowed)
+		assert.InDelta(tt, 100*time.Millisecond, retryAfter, float64(10*time.Millisecond))
+
+		// buckets are per key
+		allowed, _, err = limiter.Take(context.Background(), "other", 10, 3)
+		assert.NoError(tt, err)
+		assert.True(tt, allowed)
+
+		time.Sleep(retryAfter)
+		allowed, _, err = limiter.Take(context.Background(), "key", 10, 3)
+		assert.NoError(tt, err)
+		assert.True(tt, allowed)
+	})
+}
+
+func TestMemoryLimiter_Increment(t *testing.T) {
+	t.Parallel()
+
+	t.Run("happy case: counters expire", func(tt *testing.T) {
+		limiter := NewMemoryLimiter()
+		for i := int64(1); i <= 3; i++ {
+			value, err := limiter.Increment(context.Background(), "key", 50*time.Millisecond)
+			assert.NoError(tt, err)
+			assert.Equal(tt, i, value)
+		}
+		count, err := limiter.Count(context.Background(), "key")
+		assert.NoError(tt, err)
+		assert.Equal(tt, int64(3), count)
+
+		time.Sleep(60 * time.Millisecond)
+		count, err = limiter.Count(context.Background(), "key")
+		assert.NoError(tt, err)
+		assert.Zero(tt, count)
+		value, err := limiter.Increment(context.Background(), "key", time.Minute)
+		assert.NoError(tt, err)
+		assert.Equal(tt, int64(1), value)
+	})
+}
@@ -0,0 +1,25 @@
+package ratelimit
+
+import (
+	"context"
+	"time"
+)
+
+// Limiter keeps the token buckets and counters of rate limited clients.
+type Limiter interface {
+	// Take takes a token from the bucket of key, refilled with rate tokens per second up to burst tokens.
+	// When the bucket is empty it returns false and how long until a token is available.
+	Take(ctx context.Context, key string, rate float64, burst int) (bool, time.Duration, error)
+	// Increment adds one to the counter of key and returns its new value, the counter expires ttl after it is created.
+	Increment(ctx context.Context, key string, ttl time.Duration) (int64, error)
+	// Count returns the counter of key, 0 when it does not exist.
+	Count(ctx context.Context, key string) (int64, error)
+}
+
+// waitFor returns how long until the bucket has a token
+func waitFor(tokens, rate float64) time.Duration {
+	if rate <= 0 {
+		return 0
+	}
+	return time.Duration((1 - tokens) / rate * float64(time.Second))
+}
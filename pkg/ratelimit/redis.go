@@ -0,0 +1,83 @@
+package ratelimit
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisLimiter shares buckets and counters between every instance connected to the same Redis.
+type RedisLimiter struct {
+	Client *redis.Client
+}
+
+// takeScript refills and takes from the bucket atomically, it returns 1 or 0 and the tokens left
+var takeScript = redis.NewScript(`
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+local bucket = redis.call("HMGET", KEYS[1], "tokens", "updatedAt")
+local tokens = tonumber(bucket[1])
+if tokens == nil then
+  tokens = burst
+else
+  tokens = math.min(burst, tokens + (now - tonumber(bucket[2])) / 1000 * rate)
+end
+local allowed = 0
+if tokens >= 1 then
+  tokens = tokens - 1
+  allowed = 1
+end
+redis.call("HSET", KEYS[1], "tokens", tostring(tokens), "updatedAt", now)
+local ttl = 1000
+if rate > 0 then
+  ttl = math.ceil(burst / rate * 1000) + 1000
+end
+redis.call("PEXPIRE", KEYS[1], ttl)
+return {allowed, tostring(tokens)}
+`)
+
+// incrementScript sets the expiry of a counter when it is created
+var incrementScript = redis.NewScript(`
+local value = redis.call("INCR", KEYS[1])
+if value == 1 then
+  redis.call("PEXPIRE", KEYS[1], ARGV[1])
+end
+return value
+`)
+
+func (l *RedisLimiter) Take(ctx context.Context, key string, rate float64, burst int) (bool, time.Duration, error) {
+	result, err := takeScript.Run(ctx, l.Client, []string{key}, rate, burst, time.Now().UnixMilli()).Slice()
+	if err != nil {
+		return false, 0, errors.Wrap(err, "RedisLimiter.Take")
+	}
+	if len(result) != 2 {
+		return false, 0, errors.Errorf("RedisLimiter.Take: unexpected result %v", result)
+	}
+	allowed, _ := result[0].(int64)
+	if allowed == 1 {
+		return true, 0, nil
+	}
+	value, _ := result[1].(string)
+	tokens, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return false, 0, errors.Wrap(err, "RedisLimiter.Take")
+	}
+	return false, waitFor(tokens, rate), nil
+}
+
+func (l *RedisLimiter) Increment(ctx context.Context, key string, ttl time.Duration) (int64, error) {
+	value, err := incrementScript.Run(ctx, l.Client, []string{key}, ttl.Milliseconds()).Int64()
+	return value, errors.Wrap(err, "RedisLimiter.Increment")
+}
+
+func (l *RedisLimiter) Count(ctx context.Context, key string) (int64, error) {
+	value, err := l.Client.Get(ctx, key).Int64()
+	if err == redis.Nil {
+		return 0, nil
+	}
+	return value, errors.Wrap(err, "RedisLimiter.Count")
+}
@@ -0,0 +1,193 @@
+package search
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+const (
+	scoreExact  = 1.0
+	scorePrefix = 0.8
+	scoreFuzzy  = 0.6
+	// bonus for a document containing the whole query as typed
+	scorePhrase = 0.5
+)
+
+type Document struct {
+	ID   string
+	Text string
+}
+
+type Result struct {
+	ID    string
+	Score float64
+	// length of the matched text, shorter texts rank first on equal score
+	length int
+}
+
+// Index is an in-memory full text index with prefix and typo tolerant matching,
+// it is immutable and safe for concurrent use.
+type Index struct {
+	documents []indexedDocument
+	// postings maps each token to the documents containing it
+	postings map[string][]int
+}
+
+type indexedDocument struct {
+	id         string
+	normalized string
+}
+
+func NewIndex(documents []Document) *Index {
+	index := &Index{
+		documents: make([]indexedDocument, 0, len(documents)),
+		postings:  make(map[string][]int),
+	}
+
+	for i, doc := range documents {
+		tokens := tokenize(doc.Text)
+		index.documents = append(index.documents, indexedDocument{
+			id:         doc.ID,
+			normalized: strings.Join(tokens, " "),
+		})
+
+		seen := make(map[string]struct{}, len(tokens))
+		for _, token := range tokens {
+			if _, ok := seen[token]; ok {
+				continue
+			}
+			seen[token] = struct{}{}
+			index.postings[token] = append(index.postings[token], i)
+		}
+	}
+
+	return index
+}
+
+// Search ranks documents matching query, best first. Every query token adds the score of its best
+// matching token in the document: exact, prefix or within a few typos.
+func (index *Index) Search(query string, limit int) []Result {
+	queryTokens := tokenize(query)
+	if len(queryTokens) == 0 {
+		return nil
+	}
+
+	scores := make(map[int]float64)
+	for _, queryToken := range queryTokens {
+		best := make(map[int]float64)
+		for token, docs := range index.postings {
+			score := matchToken(queryToken, token)
+			if score == 0 {
+				continue
+			}
+			for _, doc := range docs {
+				if score > best[doc] {
+					best[doc] = score
+				}
+			}
+		}
+		for doc, score := range best {
+			scores[doc] += score
+		}
+	}
+
+	phrase := strings.Join(queryTokens, " ")
+	results := make([]Result, 0, len(scores))
+	for doc, score := range scores {
+		score /= float64(len(queryTokens))
+		if strings.Contains(index.documents[doc].normalized, phrase) {
+			score += scorePhrase
+		}
+		results = append(results, Result{
+			ID:     index.documents[doc].id,
+			Score:  score,
+			length: len(index.documents[doc].normalized),
+		})
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		if results[i].length != results[j].length {
+			return results[i].length < results[j].length
+		}
+		return results[i].ID < results[j].ID
+	})
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	return results
+}
+
+func matchToken(queryToken, token string) float64 {
+	switch {
+	case queryToken == token:
+		return scoreExact
+	case strings.HasPrefix(token, queryToken):
+		return scorePrefix
+	}
+
+	maxEdits := allowedEdits(queryToken)
+	if maxEdits == 0 {
+		return 0
+	}
+	// compare with the token prefix too, so a typo in a partially typed word still matches
+	candidate := token
+	if len(candidate) > len(queryToken)+maxEdits {
+		candidate = candidate[:len(queryToken)]
+	}
+	distance := editDistance(queryToken, candidate)
+	if distance > maxEdits {
+		return 0
+	}
+	return scoreFuzzy * (1 - float64(distance)/float64(len(queryToken)+1))
+}
+
+// allowedEdits returns how many typos are tolerated for a query token
+func allowedEdits(token string) int {
+	switch n := len([]rune(token)); {
+	case n < 4:
+		return 0
+	case n < 8:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// editDistance is the Damerau-Levenshtein (optimal string alignment) distance,
+// a swap of two adjacent letters counts as one typo.
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	rows := make([][]int, len(ra)+1)
+	for i := range rows {
+		rows[i] = make([]int, len(rb)+1)
+		rows[i][0] = i
+	}
+	for j := range rows[0] {
+		rows[0][j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			rows[i][j] = min(rows[i-1][j]+1, rows[i][j-1]+1, rows[i-1][j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				rows[i][j] = min(rows[i][j], rows[i-2][j-2]+1)
+			}
+		}
+	}
+	return rows[len(ra)][len(rb)]
+}
+
+// tokenize lowercases text and splits it on anything that is not a letter or a digit
+func tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
@@ -0,0 +1,53 @@
+package search
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func mockIndex() *Index {
+	return NewIndex([]Document{
+		{ID: "378237", Text: "Canteen 2"},
+		{ID: "378233", Text: "Hall 1 (Blk 18)"},
+		{ID: "378207", Text: "ADM, Hall 8"},
+		{ID: "383011", Text: "University Health Services(SSC bus stop)"},
+		{ID: "44480", Text: "Campus Rider Green"},
+	})
+}
+
+func TestIndex_Search(t *testing.T) {
+	t.Parallel()
+
+	t.Run("happy case: exact match is case insensitive", func(tt *testing.T) {
+		resp := mockIndex().Search("CANTEEN", 10)
+		assert.Len(tt, resp, 1)
+		assert.Equal(tt, "378237", resp[0].ID)
+	})
+
+	t.Run("happy case: every token counts", func(tt *testing.T) {
+		resp := mockIndex().Search("hall 8", 10)
+		assert.Len(tt, resp, 2)
+		assert.Equal(tt, "378207", resp[0].ID)
+		assert.Equal(tt, "378233", resp[1].ID)
+	})
+
+	t.Run("happy case: prefix match", func(tt *testing.T) {
+		resp := mockIndex().Search("univ", 10)
+		assert.Len(tt, resp, 1)
+		assert.Equal(tt, "383011", resp[0].ID)
+	})
+
+	t.Run("happy case: typo tolerant", func(tt *testing.T) {
+		for _, query := range []string{"cantene", "caanteen", "cnteen", "grene"} {
+			resp := mockIndex().Search(query, 10)
+			assert.NotEmpty(tt, resp, query)
+		}
+		assert.Equal(tt, "378237", mockIndex().Search("cantene", 10)[0].ID)
+	})
+
+	t.Run("no match", func(tt *testing.T) {
+		assert.Empty(tt, mockIndex().Search("library", 10))
+		assert.Empty(tt, mockIndex().Search("  ", 10))
+	})
+}
@@ -0,0 +1,59 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestSetup(t *testing.T) {
+	t.Parallel()
+
+	t.Run("happy case: exporters", func(tt *testing.T) {
+		for _, exporter := range []string{ExporterNone, ExporterStdout, ExporterOTLP} {
+			shutdown, err := Setup(context.Background(), Options{Exporter: exporter, ServiceName: "bus-timing", SampleRatio: 1})
+			assert.NoError(tt, err)
+			assert.NoError(tt, shutdown(context.Background()))
+		}
+	})
+
+	t.Run("happy case: stdout exporter writes to its output", func(tt *testing.T) {
+		buf := &bytes.Buffer{}
+		shutdown, err := Setup(context.Background(), Options{Exporter: ExporterStdout, Output: buf, SampleRatio: 1})
+		assert.NoError(tt, err)
+		_, span := Start(context.Background(), "UWaveClient.busLines")
+		End(span, nil)
+		assert.NoError(tt, shutdown(context.Background()))
+		assert.Contains(tt, buf.String(), "UWaveClient.busLines")
+	})
+
+	t.Run("bad case: unknown exporter", func(tt *testing.T) {
+		_, err := Setup(context.Background(), Options{Exporter: "jaeger"})
+		assert.Error(tt, err)
+	})
+}
+
+func TestEnd(t *testing.T) {
+	t.Parallel()
+
+	recorder := tracetest.NewSpanRecorder()
+	tracer := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer("test")
+
+	_, span := tracer.Start(context.Background(), "ok")
+	End(span, nil)
+	_, span = tracer.Start(context.Background(), "failed")
+	End(span, errors.New("uwave is unavailable"))
+
+	spans := recorder.Ended()
+	assert.Len(t, spans, 2)
+	assert.Equal(t, codes.Unset, spans[0].Status().Code)
+	assert.Equal(t, codes.Error, spans[1].Status().Code)
+	assert.Equal(t, "uwave is unavailable", spans[1].Status().Description)
+	assert.Len(t, spans[1].Events(), 1)
+}
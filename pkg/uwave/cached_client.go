@@ -0,0 +1,126 @@
+package uwave
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"bus-timing/pkg/cache"
+	"bus-timing/pkg/logging"
+	"bus-timing/pkg/metrics"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// CachedClient keeps the bus line catalogue and the latest bus positions of each line in a cache,
+// so instances sharing the cache only call uWave once per TTL.
+// Concurrent misses of a key in an instance share a single call.
+type CachedClient struct {
+	Client interface {
+		GetBusLines(ctx context.Context) (GetBusLineResponse, error)
+		GetRunningBusByBusLineID(ctx context.Context, busLineID string) (GetRunningBusResponse, error)
+	}
+	Cache interface {
+		Get(ctx context.Context, key string) ([]byte, error)
+		Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	}
+	BusLine     CacheOption
+	BusPosition CacheOption
+	Logger      *slog.Logger
+
+	group singleflight.Group
+}
+
+type CacheOption struct {
+	KeyPrefix string
+	TTL       time.Duration
+}
+
+func (c *CachedClient) GetBusLines(ctx context.Context) (GetBusLineResponse, error) {
+	key := c.BusLine.KeyPrefix
+	resp := GetBusLineResponse{}
+	if c.getCache(ctx, "bus_line", key, &resp) {
+		return resp, nil
+	}
+
+	val, err := c.fetch(ctx, key, c.BusLine.TTL, func(ctx context.Context) (interface{}, error) {
+		return c.Client.GetBusLines(ctx)
+	})
+	if err != nil {
+		return GetBusLineResponse{}, err
+	}
+	return val.(GetBusLineResponse), nil
+}
+
+func (c *CachedClient) GetRunningBusByBusLineID(ctx context.Context, busLineID string) (GetRunningBusResponse, error) {
+	key := fmt.Sprintf("%s:%s", c.BusPosition.KeyPrefix, busLineID)
+	resp := GetRunningBusResponse{}
+	if c.getCache(ctx, "bus_position", key, &resp) {
+		return resp, nil
+	}
+
+	val, err := c.fetch(ctx, key, c.BusPosition.TTL, func(ctx context.Context) (interface{}, error) {
+		return c.Client.GetRunningBusByBusLineID(ctx, busLineID)
+	})
+	if err != nil {
+		return GetRunningBusResponse{}, err
+	}
+	return val.(GetRunningBusResponse), nil
+}
+
+// fetch calls upstream once for the concurrent misses of key and caches a successful response.
+// The call does not stop when the caller that started it goes away, the others may still wait for it.
+func (c *CachedClient) fetch(ctx context.Context, key string, ttl time.Duration, call func(ctx context.Context) (interface{}, error)) (interface{}, error) {
+	val, err, _ := c.group.Do(key, func() (interface{}, error) {
+		ctx := context.WithoutCancel(ctx)
+		val, err := call(ctx)
+		if err != nil {
+			return nil, err
+		}
+		c.setCache(ctx, key, val, ttl)
+		return val, nil
+	})
+	return val, err
+}
+
+// getCache reports whether key was found, cache failures are treated as a miss.
+// Hits and misses are counted under the name of the cache.
+func (c *CachedClient) getCache(ctx context.Context, name, key string, dest interface{}) bool {
+	hit := c.lookup(ctx, key, dest)
+	result := "miss"
+	if hit {
+		result = "hit"
+	}
+	metrics.CacheRequests.WithLabelValues(name, result).Inc()
+	return hit
+}
+
+func (c *CachedClient) lookup(ctx context.Context, key string, dest interface{}) bool {
+	data, err := c.Cache.Get(ctx, key)
+	if err != nil {
+		if err != cache.ErrCacheMiss {
+			logging.OrDefault(c.Logger).WarnContext(ctx, "CachedClient: get", "key", key, "error", err)
+		}
+		return false
+	}
+
+	if err := json.Unmarshal(data, dest); err != nil {
+		logging.OrDefault(c.Logger).WarnContext(ctx, "CachedClient: decode", "key", key, "error", err)
+		return false
+	}
+	return true
+}
+
+func (c *CachedClient) setCache(ctx context.Context, key string, value interface{}, ttl time.Duration) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		logging.OrDefault(c.Logger).ErrorContext(ctx, "CachedClient: encode", "key", key, "error", err)
+		return
+	}
+
+	if err := c.Cache.Set(ctx, key, data, ttl); err != nil {
+		logging.OrDefault(c.Logger).WarnContext(ctx, "CachedClient: set", "key", key, "error", err)
+	}
+}
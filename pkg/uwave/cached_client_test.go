@@ -0,0 +1,115 @@
+package uwave
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"bus-timing/pkg/cache"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type mockClient struct {
+	busLineCalls     int
+	busPositionCalls int
+	err              error
+}
+
+func (m *mockClient) GetBusLines(ctx context.Context) (GetBusLineResponse, error) {
+	m.busLineCalls++
+	return GetBusLineResponse{Payload: []BusLinePayload{{ID: "44480"}}}, m.err
+}
+
+func (m *mockClient) GetRunningBusByBusLineID(ctx context.Context, busLineID string) (GetRunningBusResponse, error) {
+	m.busPositionCalls++
+	return GetRunningBusResponse{Payload: []RunningBusPayload{{VehiclePlate: "PD1064Z"}}}, m.err
+}
+
+func TestCachedClient(t *testing.T) {
+	t.Parallel()
+
+	t.Run("happy case: second call is served from cache", func(tt *testing.T) {
+		upstream := &mockClient{}
+		client := CachedClient{
+			Client:      upstream,
+			Cache:       cache.NewMemoryCache(),
+			BusLine:     CacheOption{KeyPrefix: "bus-lines", TTL: time.Minute},
+			BusPosition: CacheOption{KeyPrefix: "bus-positions", TTL: time.Minute},
+		}
+
+		for i := 0; i < 2; i++ {
+			busLines, err := client.GetBusLines(context.Background())
+			assert.NoError(tt, err)
+			assert.Equal(tt, "44480", busLines.Payload[0].ID)
+
+			runningBuses, err := client.GetRunningBusByBusLineID(context.Background(), "44480")
+			assert.NoError(tt, err)
+			assert.Equal(tt, "PD1064Z", runningBuses.Payload[0].VehiclePlate)
+		}
+		assert.Equal(tt, 1, upstream.busLineCalls)
+		assert.Equal(tt, 1, upstream.busPositionCalls)
+
+		_, err := client.GetRunningBusByBusLineID(context.Background(), "44481")
+		assert.NoError(tt, err)
+		assert.Equal(tt, 2, upstream.busPositionCalls)
+	})
+
+	t.Run("bad case: upstream failure is not cached", func(tt *testing.T) {
+		upstream := &mockClient{err: http.ErrServerClosed}
+		client := CachedClient{
+			Client:  upstream,
+			Cache:   cache.NewMemoryCache(),
+			BusLine: CacheOption{KeyPrefix: "bus-lines", TTL: time.Minute},
+		}
+
+		_, err := client.GetBusLines(context.Background())
+		assert.Equal(tt, http.ErrServerClosed, err)
+		_, err = client.GetBusLines(context.Background())
+		assert.Equal(tt, http.ErrServerClosed, err)
+		assert.Equal(tt, 2, upstream.busLineCalls)
+	})
+}
+
+// blockingClient answers the running buses once release is closed
+type blockingClient struct {
+	mockClient
+	calls   atomic.Int32
+	release chan struct{}
+}
+
+func (m *blockingClient) GetRunningBusByBusLineID(ctx context.Context, busLineID string) (GetRunningBusResponse, error) {
+	m.calls.Add(1)
+	<-m.release
+	return GetRunningBusResponse{Payload: []RunningBusPayload{{VehiclePlate: "PD1064Z"}}}, nil
+}
+
+func TestCachedClient_concurrentMisses(t *testing.T) {
+	t.Parallel()
+
+	upstream := &blockingClient{release: make(chan struct{})}
+	client := &CachedClient{
+		Client:      upstream,
+		Cache:       cache.NewMemoryCache(),
+		BusPosition: CacheOption{KeyPrefix: "bus-positions", TTL: time.Minute},
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			runningBuses, err := client.GetRunningBusByBusLineID(context.Background(), "44480")
+			assert.NoError(t, err)
+			assert.Equal(t, "PD1064Z", runningBuses.Payload[0].VehiclePlate)
+		}()
+	}
+	assert.Eventually(t, func() bool { return upstream.calls.Load() == 1 }, time.Second, time.Millisecond)
+	time.Sleep(10 * time.Millisecond)
+	close(upstream.release)
+	wg.Wait()
+	assert.Equal(t, int32(1), upstream.calls.Load())
+}
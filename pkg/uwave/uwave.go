@@ -5,13 +5,33 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
+	"time"
+
+	"bus-timing/pkg/apperror"
+	"bus-timing/pkg/logging"
+	"bus-timing/pkg/metrics"
+	"bus-timing/pkg/tracing"
 
 	"github.com/pkg/errors"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+const (
+	// upstream labels the metrics of the calls to uWave
+	upstream = "uwave"
+	// requestTimeout bounds a call to uWave, including reading the body, so a stalled upstream cannot hold its caller
+	requestTimeout = 10 * time.Second
 )
 
+// httpClient traces the calls and propagates the trace context to uWave
+var httpClient = &http.Client{Timeout: requestTimeout, Transport: otelhttp.NewTransport(http.DefaultTransport)}
+
 type UWaveClient struct {
 	Endpoint string
+	Logger   *slog.Logger
 }
 
 type GetBusLineRequest struct {
@@ -44,6 +64,8 @@ type GetRunningBusRequest struct {
 type GetRunningBusResponse struct {
 	Payload []RunningBusPayload `json:"payload"`
 	Status  int                 `json:"status"`
+	// FetchedAt is when the positions were read from uWave, it is kept by the cache
+	FetchedAt time.Time `json:"fetchedAt"`
 }
 
 type RunningBusPayload struct {
@@ -62,20 +84,14 @@ type RunningBusPort struct {
 
 func (u *UWaveClient) GetBusLines(ctx context.Context) (GetBusLineResponse, error) {
 	requestURL := fmt.Sprintf("%s/busLines", u.Endpoint)
-	res, err := http.Get(requestURL)
+	resBody, err := u.get(ctx, "busLines", requestURL)
 	if err != nil {
 		return GetBusLineResponse{}, errors.Wrap(err, "UWaveClient.GetBusLines")
 	}
-	defer res.Body.Close()
-
-	resBody, err := io.ReadAll(res.Body)
-	if err != nil {
-		fmt.Printf("client: could not read response body: %s\n", err)
-	}
 
 	resp := GetBusLineResponse{}
 	if err := json.Unmarshal(resBody, &resp); err != nil {
-		return GetBusLineResponse{}, errors.Wrap(err, "UWaveClient.GetBusLines")
+		return GetBusLineResponse{}, errors.Wrap(apperror.Upstream(err, "invalid response from uwave"), "UWaveClient.GetBusLines")
 	}
 
 	// plan, _ := os.ReadFile("./test_data/bus_line.json")
@@ -90,22 +106,16 @@ func (u *UWaveClient) GetBusLines(ctx context.Context) (GetBusLineResponse, erro
 
 func (u *UWaveClient) GetRunningBusByBusLineID(ctx context.Context, busLineID string) (GetRunningBusResponse, error) {
 	requestURL := fmt.Sprintf("%s/busPositions/%s", u.Endpoint, busLineID)
-	res, err := http.Get(requestURL)
+	resBody, err := u.get(ctx, "busPositions", requestURL)
 	if err != nil {
 		return GetRunningBusResponse{}, errors.Wrap(err, "UWaveClient.GetRunningBusByBusLineID")
 	}
-	defer res.Body.Close()
-
-	resBody, err := io.ReadAll(res.Body)
-	if err != nil {
-		fmt.Printf("client: could not read response body: %s\n", err)
-		return GetRunningBusResponse{}, errors.Wrap(err, "UWaveClient.GetRunningBusByBusLineID")
-	}
 
 	resp := GetRunningBusResponse{}
 	if err := json.Unmarshal(resBody, &resp); err != nil {
-		return GetRunningBusResponse{}, errors.Wrap(err, "UWaveClient.GetRunningBusByBusLineID")
+		return GetRunningBusResponse{}, errors.Wrap(apperror.Upstream(err, "invalid response from uwave"), "UWaveClient.GetRunningBusByBusLineID")
 	}
+	resp.FetchedAt = time.Now()
 
 	// plan, _ := os.ReadFile(fmt.Sprintf("./test_data/bus_line_position_%s.json", busLineID))
 	// resp := GetRunningBusResponse{}
@@ -115,3 +125,40 @@ func (u *UWaveClient) GetRunningBusByBusLineID(ctx context.Context, busLineID st
 	// }
 	return resp, nil
 }
+
+// get reads the body of a successful response, failures are reported as the uwave API being unavailable.
+// The latency and failures are observed under the endpoint name, and the call is traced in a span of its own.
+func (u *UWaveClient) get(ctx context.Context, endpoint, requestURL string) (body []byte, err error) {
+	ctx, span := tracing.Start(ctx, "UWaveClient."+endpoint, attribute.String("uwave.endpoint", endpoint))
+	defer metrics.ObserveDuration(metrics.UpstreamRequestDuration.WithLabelValues(upstream, endpoint), time.Now())
+	defer func() {
+		if err != nil {
+			metrics.UpstreamErrors.WithLabelValues(upstream, endpoint, string(apperror.KindOf(err))).Inc()
+		}
+		tracing.End(span, err)
+	}()
+	logger := logging.OrDefault(u.Logger).With("endpoint", endpoint, "url", requestURL)
+	start := time.Now()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, requestURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	res, err := httpClient.Do(req)
+	if err != nil {
+		logger.WarnContext(ctx, "UWaveClient: request failed", "error", err)
+		return nil, apperror.Upstream(err, "uwave is unavailable")
+	}
+	defer res.Body.Close()
+
+	logger.DebugContext(ctx, "UWaveClient: response", "status", res.StatusCode, "latency", time.Since(start))
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		logger.WarnContext(ctx, "UWaveClient: unexpected status", "status", res.StatusCode)
+		return nil, apperror.Upstream(fmt.Errorf("unexpected status: %d", res.StatusCode), "uwave is unavailable")
+	}
+	resBody, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, apperror.Upstream(err, "uwave is unavailable")
+	}
+	return resBody, nil
+}
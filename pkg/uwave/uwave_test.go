@@ -0,0 +1,94 @@
+package uwave
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"bus-timing/pkg/apperror"
+	"bus-timing/pkg/tracing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestUWaveClient_GetBusLines(t *testing.T) {
+	t.Parallel()
+
+	t.Run("happy case", func(tt *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(tt, "/busLines", r.URL.Path)
+			w.Write([]byte(`{"payload":[{"id":"44480"}]}`))
+		}))
+		defer server.Close()
+
+		resp, err := (&UWaveClient{Endpoint: server.URL}).GetBusLines(context.Background())
+		assert.NoError(tt, err)
+		assert.Equal(tt, "44480", resp.Payload[0].ID)
+	})
+
+	t.Run("happy case: trace context is propagated", func(tt *testing.T) {
+		_, err := tracing.Setup(context.Background(), tracing.Options{})
+		assert.NoError(tt, err)
+		traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+		spanID, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+		ctx := trace.ContextWithRemoteSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+			TraceID:    traceID,
+			SpanID:     spanID,
+			TraceFlags: trace.FlagsSampled,
+		}))
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Contains(tt, r.Header.Get("traceparent"), traceID.String())
+			w.Write([]byte(`{"payload":[]}`))
+		}))
+		defer server.Close()
+
+		_, err = (&UWaveClient{Endpoint: server.URL}).GetBusLines(ctx)
+		assert.NoError(tt, err)
+	})
+
+	t.Run("bad case: upstream error status", func(tt *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer server.Close()
+
+		_, err := (&UWaveClient{Endpoint: server.URL}).GetBusLines(context.Background())
+		assert.Error(tt, err)
+		assert.Equal(tt, apperror.KindUpstreamUnavailable, apperror.KindOf(err))
+		assert.Equal(tt, "uwave is unavailable", apperror.MessageOf(err))
+	})
+}
+
+func TestUWaveClient_GetRunningBusByBusLineID(t *testing.T) {
+	t.Parallel()
+
+	t.Run("happy case: running buses are stamped with their fetch time", func(tt *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(tt, "/busPositions/44480", r.URL.Path)
+			w.Write([]byte(`{"payload":[{"vehiclePlate":"PD1064Z"}]}`))
+		}))
+		defer server.Close()
+
+		resp, err := (&UWaveClient{Endpoint: server.URL}).GetRunningBusByBusLineID(context.Background(), "44480")
+		assert.NoError(tt, err)
+		assert.Equal(tt, "PD1064Z", resp.Payload[0].VehiclePlate)
+		assert.WithinDuration(tt, time.Now(), resp.FetchedAt, time.Second)
+	})
+
+	t.Run("bad case: deadline exceeded", func(tt *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			<-r.Context().Done()
+		}))
+		defer server.Close()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 0)
+		defer cancel()
+		_, err := (&UWaveClient{Endpoint: server.URL}).GetRunningBusByBusLineID(ctx, "44480")
+		assert.Error(tt, err)
+		assert.Equal(tt, apperror.KindTimeout, apperror.KindOf(err))
+	})
+}
@@ -0,0 +1,123 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"syscall"
+	"time"
+
+	"bus-timing/pkg/apperror"
+)
+
+// nonPublicNetworks are special-purpose ranges the net.IP methods do not tell apart
+var nonPublicNetworks = []*net.IPNet{
+	mustParseCIDR("0.0.0.0/8"),       // this network
+	mustParseCIDR("100.64.0.0/10"),   // carrier-grade NAT
+	mustParseCIDR("192.0.0.0/24"),    // IETF protocol assignments
+	mustParseCIDR("198.18.0.0/15"),   // benchmarking
+	mustParseCIDR("240.0.0.0/4"),     // reserved
+	mustParseCIDR("64:ff9b::/96"),    // NAT64, it can reach private IPv4 addresses
+	mustParseCIDR("64:ff9b:1::/48"),  // local-use NAT64
+	mustParseCIDR("2001:db8::/32"),   // documentation
+	mustParseCIDR("2002::/16"),       // 6to4, it embeds IPv4 addresses
+	mustParseCIDR("fec0::/10"),       // deprecated site-local
+	mustParseCIDR("100::/64"),        // discard-only
+	mustParseCIDR("2001::/32"),       // Teredo, it embeds IPv4 addresses
+	mustParseCIDR("::ffff:0:0:0/96"), // IPv4-translated
+}
+
+func mustParseCIDR(cidr string) *net.IPNet {
+	_, network, err := net.ParseCIDR(cidr)
+	if err != nil {
+		panic(err)
+	}
+	return network
+}
+
+// IsPublicIP tells whether ip is a public unicast address, webhooks are not sent to loopback,
+// private, link-local or other special-purpose addresses.
+func IsPublicIP(ip net.IP) bool {
+	if ip == nil || ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() || ip.IsMulticast() {
+		return false
+	}
+	if ip4 := ip.To4(); ip4 != nil {
+		ip = ip4
+	}
+	for _, network := range nonPublicNetworks {
+		if network.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// Resolver looks up the addresses of a host, net.DefaultResolver is one.
+type Resolver interface {
+	LookupIPAddr(ctx context.Context, host string) ([]net.IPAddr, error)
+}
+
+// CheckURL tells why webhooks cannot be sent to rawURL: it must be http or https,
+// and its host must only resolve to public addresses.
+func CheckURL(ctx context.Context, resolver Resolver, rawURL string) error {
+	host, err := hostOf(rawURL)
+	if err != nil {
+		return err
+	}
+	if ip := net.ParseIP(host); ip != nil {
+		if !IsPublicIP(ip) {
+			return apperror.InvalidInput("invalid callback URL: %s is not a public address", host)
+		}
+		return nil
+	}
+	addrs, err := resolver.LookupIPAddr(ctx, host)
+	if err != nil || len(addrs) == 0 {
+		return apperror.InvalidInput("invalid callback URL: %s does not resolve", host)
+	}
+	for _, addr := range addrs {
+		if !IsPublicIP(addr.IP) {
+			return apperror.InvalidInput("invalid callback URL: %s resolves to %s, not a public address", host, addr.IP)
+		}
+	}
+	return nil
+}
+
+// hostOf returns the host of an http or https URL
+func hostOf(rawURL string) (string, error) {
+	callbackURL, err := url.Parse(rawURL)
+	if err != nil || (callbackURL.Scheme != "http" && callbackURL.Scheme != "https") || callbackURL.Hostname() == "" {
+		return "", apperror.InvalidInput("invalid callback URL: %s", rawURL)
+	}
+	return callbackURL.Hostname(), nil
+}
+
+// NewClient returns a client that only connects to public addresses. The check is made on the
+// address being dialed, so redirects and hosts resolving to another address since CheckURL are refused too.
+func NewClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: 5 * time.Second,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if !IsPublicIP(net.ParseIP(host)) {
+				return fmt.Errorf("webhook: %s is not a public address", host)
+			}
+			return nil
+		},
+	}
+	return &http.Client{
+		Timeout: timeout,
+		// no proxy, the address dialed must be the one of the receiver
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: 5 * time.Second,
+			MaxIdleConns:        10,
+			IdleConnTimeout:     90 * time.Second,
+		},
+	}
+}
@@ -0,0 +1,117 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"bus-timing/pkg/apperror"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type staticResolver map[string][]string
+
+func (r staticResolver) LookupIPAddr(ctx context.Context, host string) ([]net.IPAddr, error) {
+	ips, ok := r[host]
+	if !ok {
+		return nil, errors.New("no such host")
+	}
+	addrs := make([]net.IPAddr, 0, len(ips))
+	for _, ip := range ips {
+		addrs = append(addrs, net.IPAddr{IP: net.ParseIP(ip)})
+	}
+	return addrs, nil
+}
+
+func TestIsPublicIP(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		ip     string
+		public bool
+	}{
+		{"93.184.216.34", true},
+		{"2606:2800:220:1:248:1893:25c8:1946", true},
+		{"127.0.0.1", false},
+		{"10.1.2.3", false},
+		{"172.16.0.1", false},
+		{"192.168.1.1", false},
+		{"169.254.169.254", false},
+		{"100.64.0.1", false},
+		{"0.0.0.0", false},
+		{"::1", false},
+		{"fd00::1", false},
+		{"fe80::1", false},
+		{"::ffff:127.0.0.1", false},
+		{"64:ff9b::a9fe:a9fe", false},
+	}
+	for _, test := range tests {
+		assert.Equal(t, test.public, IsPublicIP(net.ParseIP(test.ip)), test.ip)
+	}
+}
+
+func TestCheckURL(t *testing.T) {
+	t.Parallel()
+
+	resolver := staticResolver{
+		"example.com":        {"93.184.216.34"},
+		"internal.example":   {"10.0.0.5"},
+		"rebinding.example":  {"93.184.216.34", "127.0.0.1"},
+		"metadata.example":   {"169.254.169.254"},
+		"dual-stack.example": {"93.184.216.34", "2606:2800:220:1:248:1893:25c8:1946"},
+	}
+
+	t.Run("happy case", func(tt *testing.T) {
+		for _, rawURL := range []string{"https://example.com/hook", "http://dual-stack.example:8080/hook", "https://93.184.216.34/hook"} {
+			assert.NoError(tt, CheckURL(context.Background(), resolver, rawURL), rawURL)
+		}
+	})
+
+	t.Run("bad case: private, loopback and link-local receivers", func(tt *testing.T) {
+		for _, rawURL := range []string{
+			"http://127.0.0.1:8080/hook",
+			"http://[::1]/hook",
+			"http://169.254.169.254/latest/meta-data",
+			"https://internal.example/hook",
+			"https://rebinding.example/hook",
+			"https://metadata.example/hook",
+		} {
+			err := CheckURL(context.Background(), resolver, rawURL)
+			assert.Error(tt, err, rawURL)
+			assert.Equal(tt, apperror.KindInvalidInput, apperror.KindOf(err), rawURL)
+		}
+	})
+
+	t.Run("bad case: invalid URLs", func(tt *testing.T) {
+		for _, rawURL := range []string{"ftp://example.com/hook", "https:///hook", "https://unknown.example/hook"} {
+			assert.Error(tt, CheckURL(context.Background(), resolver, rawURL), rawURL)
+		}
+	})
+}
+
+func TestNewClient(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	t.Run("bad case: private addresses are refused at dial time", func(tt *testing.T) {
+		_, err := NewClient(time.Second).Get(server.URL)
+		assert.ErrorContains(tt, err, "not a public address")
+
+		sender := Sender{Secret: "secret", MaxAttempts: 1}
+		assert.Error(tt, sender.Send(context.Background(), server.URL, map[string]string{}))
+		assert.Error(tt, sender.CheckURL(context.Background(), server.URL))
+	})
+
+	t.Run("happy case: private networks allowed for local receivers", func(tt *testing.T) {
+		sender := Sender{Secret: "secret", MaxAttempts: 1, AllowPrivateNetworks: true}
+		assert.NoError(tt, sender.CheckURL(context.Background(), server.URL))
+		assert.NoError(tt, sender.Send(context.Background(), server.URL, map[string]string{}))
+	})
+}
@@ -0,0 +1,135 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	SignatureHeader = "X-Signature"
+	TimestampHeader = "X-Timestamp"
+
+	defaultMaxAttempts = 3
+	defaultBackoff     = time.Second
+	defaultTimeout     = 10 * time.Second
+)
+
+// Sender posts JSON payloads signed with HMAC-SHA256, retrying with an exponential backoff
+// when the receiver cannot be reached or answers 429 or 5xx.
+type Sender struct {
+	Secret string
+	// Client sends the webhooks, one of NewClient when nil
+	Client      *http.Client
+	MaxAttempts int
+	// Backoff is the wait before the second attempt, doubled before every next one
+	Backoff time.Duration
+	// Resolver resolves the hosts of CheckURL, net.DefaultResolver when nil
+	Resolver Resolver
+	// AllowPrivateNetworks lets webhooks reach loopback and private addresses, for local receivers only
+	AllowPrivateNetworks bool
+}
+
+// Sign returns the signature of a body sent at timestamp (unix seconds), receivers verify it
+// by computing hex(HMAC-SHA256(secret, timestamp + "." + body)).
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// CheckURL tells why webhooks cannot be sent to url, see CheckURL.
+func (sender *Sender) CheckURL(ctx context.Context, url string) error {
+	if sender.AllowPrivateNetworks {
+		_, err := hostOf(url)
+		return err
+	}
+	var resolver Resolver = net.DefaultResolver
+	if sender.Resolver != nil {
+		resolver = sender.Resolver
+	}
+	return CheckURL(ctx, resolver, url)
+}
+
+func (sender *Sender) Send(ctx context.Context, url string, payload any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return errors.Wrap(err, "Sender.Send")
+	}
+
+	maxAttempts := sender.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = defaultMaxAttempts
+	}
+	backoff := sender.Backoff
+	if backoff <= 0 {
+		backoff = defaultBackoff
+	}
+
+	for attempt := 1; ; attempt++ {
+		retry, err := sender.post(ctx, url, body)
+		if err == nil {
+			return nil
+		}
+		if !retry || attempt >= maxAttempts {
+			return errors.Wrapf(err, "Sender.Send: attempt %d", attempt)
+		}
+
+		select {
+		case <-ctx.Done():
+			return errors.Wrap(ctx.Err(), "Sender.Send")
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// post sends one attempt, it reports whether a failure is worth retrying.
+func (sender *Sender) post(ctx context.Context, url string, body []byte) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(TimestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(SignatureHeader, Sign(sender.Secret, timestamp, body))
+
+	client := sender.Client
+	switch {
+	case client != nil:
+	case sender.AllowPrivateNetworks:
+		client = &http.Client{Timeout: defaultTimeout}
+	default:
+		client = NewClient(defaultTimeout)
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return ctx.Err() == nil, err
+	}
+	defer res.Body.Close()
+	// drain the body so the connection is reused
+	_, _ = io.Copy(io.Discard, io.LimitReader(res.Body, 1<<16))
+
+	switch {
+	case res.StatusCode >= 200 && res.StatusCode < 300:
+		return false, nil
+	case res.StatusCode == http.StatusTooManyRequests || res.StatusCode >= 500:
+		return true, fmt.Errorf("webhook responded %d", res.StatusCode)
+	default:
+		return false, fmt.Errorf("webhook responded %d", res.StatusCode)
+	}
+}
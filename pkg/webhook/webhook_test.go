@@ -0,0 +1,68 @@
+package webhook
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSender_Send(t *testing.T) {
+	t.Parallel()
+
+	t.Run("happy case: signed and retried after a server error", func(tt *testing.T) {
+		var calls int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			timestamp, _ := strconv.ParseInt(r.Header.Get(TimestampHeader), 10, 64)
+			assert.Equal(tt, Sign("secret", timestamp, body), r.Header.Get(SignatureHeader))
+			assert.JSONEq(tt, `{"busLineID":"44480"}`, string(body))
+
+			if atomic.AddInt32(&calls, 1) == 1 {
+				w.WriteHeader(http.StatusBadGateway)
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		defer server.Close()
+
+		sender := Sender{Secret: "secret", AllowPrivateNetworks: true, Backoff: time.Millisecond}
+		err := sender.Send(context.Background(), server.URL, map[string]string{"busLineID": "44480"})
+		assert.NoError(tt, err)
+		assert.Equal(tt, int32(2), atomic.LoadInt32(&calls))
+	})
+
+	t.Run("bad case: client errors are not retried", func(tt *testing.T) {
+		var calls int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&calls, 1)
+			w.WriteHeader(http.StatusBadRequest)
+		}))
+		defer server.Close()
+
+		sender := Sender{Secret: "secret", AllowPrivateNetworks: true, Backoff: time.Millisecond}
+		err := sender.Send(context.Background(), server.URL, map[string]string{})
+		assert.Error(tt, err)
+		assert.Equal(tt, int32(1), atomic.LoadInt32(&calls))
+	})
+
+	t.Run("bad case: gives up after max attempts", func(tt *testing.T) {
+		var calls int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&calls, 1)
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer server.Close()
+
+		sender := Sender{Secret: "secret", AllowPrivateNetworks: true, MaxAttempts: 3, Backoff: time.Millisecond}
+		err := sender.Send(context.Background(), server.URL, map[string]string{})
+		assert.Error(tt, err)
+		assert.Equal(tt, int32(3), atomic.LoadInt32(&calls))
+	})
+}